@@ -0,0 +1,341 @@
+package gtmlp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CachedResponse is an HTTP response stored in a ResponseCache
+type CachedResponse struct {
+	URL        string      // Request URL
+	StatusCode int         // HTTP status code
+	Header     http.Header // Response headers
+	Body       []byte      // Response body
+	StoredAt   time.Time   // When the response was stored or last revalidated
+}
+
+// ResponseCache stores HTTP responses between fetches.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the cached response for key, if any
+	Get(key string) (*CachedResponse, bool)
+	// Set stores a response under key
+	Set(key string, resp *CachedResponse) error
+	// Delete removes the response stored under key
+	Delete(key string) error
+}
+
+// FileCache is a ResponseCache that stores one JSON file per response under a directory
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache creates a file cache rooted at dir, creating the directory if needed
+func NewFileCache(dir string) (*FileCache, error) {
+	if dir == "" {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "cache directory cannot be empty",
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("failed to create cache directory: %s", dir),
+			Cause:   err,
+		}
+	}
+
+	return &FileCache{dir: dir}, nil
+}
+
+// path returns the file for key, sharded by the first two hex characters
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name+".json")
+}
+
+// Get returns the cached response for key, if any
+func (c *FileCache) Get(key string) (*CachedResponse, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			getLogger().Warn("cache read failed",
+				"path", path,
+				"error", err.Error())
+		}
+		return nil, false
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		getLogger().Warn("cache entry corrupt",
+			"path", path,
+			"error", err.Error())
+		return nil, false
+	}
+
+	return &resp, true
+}
+
+// Set stores a response under key, writing through a temporary file so readers never see partial entries
+func (c *FileCache) Set(key string, resp *CachedResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes the response stored under key
+func (c *FileCache) Delete(key string) error {
+	err := os.Remove(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// cacheKey identifies a GET request by its normalized URL, user agent and the headers
+// that change the response. Header values are hashed so credentials never appear in keys.
+func cacheKey(url string, config *Config) string {
+	var b strings.Builder
+	b.WriteString("GET ")
+	b.WriteString(normalizeURL(url))
+	if config.UserAgent != "" {
+		b.WriteString("\nUser-Agent: ")
+		b.WriteString(config.UserAgent)
+	}
+
+	names := make([]string, 0, len(config.Headers))
+	for name := range config.Headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(": ")
+		sum := sha256.Sum256([]byte(headerValue(config.Headers, name)))
+		b.WriteString(hex.EncodeToString(sum[:]))
+	}
+
+	return b.String()
+}
+
+// headerValue looks up a header in a map with arbitrary key casing
+func headerValue(headers map[string]string, canonical string) string {
+	for name, value := range headers {
+		if http.CanonicalHeaderKey(name) == canonical {
+			return value
+		}
+	}
+	return ""
+}
+
+// isCacheable reports whether a request may be served from or stored in the cache
+func isCacheable(pageReq *pageRequest, config *Config) bool {
+	return config.Cache != nil && (pageReq.Method == "" || pageReq.Method == http.MethodGet)
+}
+
+// isFresh reports whether a cached response is younger than ttl
+func (r *CachedResponse) isFresh(ttl time.Duration) bool {
+	return ttl > 0 && time.Since(r.StoredAt) < ttl
+}
+
+// hasValidators reports whether the cached response can be revalidated with a conditional request
+func (r *CachedResponse) hasValidators() bool {
+	return r.Header.Get("ETag") != "" || r.Header.Get("Last-Modified") != ""
+}
+
+// addConditionalHeaders adds If-None-Match and If-Modified-Since from the cached validators
+func (r *CachedResponse) addConditionalHeaders(req *http.Request) {
+	if etag := r.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := r.Header.Get("Last-Modified"); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// httpResponse converts the cached response into an *http.Response to req with a fresh body reader
+func (r *CachedResponse) httpResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// cacheTransport serves a fresh cached response, or revalidates a stale one and
+// turns a 304 Not Modified into the cached response
+type cacheTransport struct {
+	key    string
+	fresh  *CachedResponse
+	stale  *CachedResponse
+	served bool // The last response came from the cache
+	config *Config
+	base   http.RoundTripper
+}
+
+// RoundTrip serves the request from the cache or the base transport
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.served = false
+	if t.fresh != nil {
+		t.served = true
+		return t.fresh.httpResponse(req), nil
+	}
+
+	if t.stale != nil {
+		req = req.Clone(req.Context())
+		t.stale.addConditionalHeaders(req)
+	}
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.stale != nil && resp.StatusCode == http.StatusNotModified {
+		t.served = true
+		return revalidate(t.key, t.stale, resp, t.config), nil
+	}
+	return resp, nil
+}
+
+// lookupCache returns a fresh cached response to serve directly, or a stale one to revalidate
+func lookupCache(key, url string, config *Config) (fresh, stale *CachedResponse) {
+	entry, ok := config.Cache.Get(key)
+	if !ok {
+		getLogger().Info("cache miss",
+			"url", url)
+		return nil, nil
+	}
+
+	age := time.Since(entry.StoredAt)
+	if entry.isFresh(config.CacheTTL) {
+		getLogger().Info("cache hit",
+			"url", url,
+			"age_ms", age.Milliseconds())
+		return entry, nil
+	}
+
+	if !entry.hasValidators() {
+		getLogger().Info("cache miss",
+			"url", url,
+			"reason", "expired")
+		return nil, nil
+	}
+
+	getLogger().Debug("cache stale, revalidating",
+		"url", url,
+		"age_ms", age.Milliseconds())
+	return nil, entry
+}
+
+// revalidate refreshes a cached response after a 304 Not Modified and returns it as a response
+func revalidate(key string, cached *CachedResponse, notModified *http.Response, config *Config) *http.Response {
+	notModified.Body.Close()
+
+	// A 304 may carry updated validators and caching headers
+	for name, values := range notModified.Header {
+		cached.Header[name] = values
+	}
+	cached.StoredAt = time.Now()
+
+	if err := config.Cache.Set(key, cached); err != nil {
+		getLogger().Warn("cache write failed",
+			"url", cached.URL,
+			"error", err.Error())
+	}
+
+	getLogger().Info("cache revalidated",
+		"url", cached.URL)
+
+	return cached.httpResponse(notModified.Request)
+}
+
+// storeResponse buffers a successful response body into the cache and returns a response
+// that can still be read by the caller
+func storeResponse(key, url string, resp *http.Response, config *Config) (*http.Response, error) {
+	if strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeNetwork,
+			Message: "failed to read response body",
+			URL:     url,
+			Cause:   err,
+		}
+	}
+
+	entry := &CachedResponse{
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		StoredAt:   time.Now(),
+	}
+	if err := config.Cache.Set(key, entry); err != nil {
+		getLogger().Warn("cache write failed",
+			"url", url,
+			"error", err.Error())
+	} else {
+		getLogger().Debug("cache stored",
+			"url", url,
+			"size_bytes", len(body))
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,233 @@
+package gtmlp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFileCache_RoundTrip tests storing, loading and deleting entries
+func TestFileCache_RoundTrip(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache failed: %v", err)
+	}
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Expected miss for unknown key")
+	}
+
+	entry := &CachedResponse{
+		URL:        "https://example.com/a",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": {`"v1"`}},
+		Body:       []byte("<html></html>"),
+		StoredAt:   time.Now(),
+	}
+	if err := cache.Set("k", entry); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	got, ok := cache.Get("k")
+	if !ok {
+		t.Fatal("Expected hit after Set")
+	}
+	if string(got.Body) != "<html></html>" || got.Header.Get("ETag") != `"v1"` {
+		t.Errorf("Unexpected cached entry: %+v", got)
+	}
+
+	if err := cache.Delete("k"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := cache.Get("k"); ok {
+		t.Error("Expected miss after Delete")
+	}
+	if err := cache.Delete("k"); err != nil {
+		t.Errorf("Expected deleting a missing key to succeed, got %v", err)
+	}
+}
+
+// TestNewFileCache_EmptyDir tests that an empty directory is rejected
+func TestNewFileCache_EmptyDir(t *testing.T) {
+	if _, err := NewFileCache(""); err == nil {
+		t.Error("Expected error for empty cache directory")
+	}
+}
+
+// TestCacheKey tests that keys normalize URLs and include request headers
+func TestCacheKey(t *testing.T) {
+	config := &Config{}
+	if cacheKey("https://example.com/a/?b=2&a=1#top", config) != cacheKey("https://example.com/a?a=1&b=2", config) {
+		t.Error("Expected equivalent URLs to share a cache key")
+	}
+
+	withHeader := &Config{Headers: map[string]string{"accept-language": "de"}}
+	if cacheKey("https://example.com/a", config) == cacheKey("https://example.com/a", withHeader) {
+		t.Error("Expected request headers to change the cache key")
+	}
+
+	withAuth := &Config{Headers: map[string]string{"Authorization": "Bearer secret-token"}}
+	if strings.Contains(cacheKey("https://example.com/a", withAuth), "secret-token") {
+		t.Error("Expected header values to be hashed in the cache key")
+	}
+
+	withAgent := &Config{UserAgent: "mobile-bot"}
+	if cacheKey("https://example.com/a", config) == cacheKey("https://example.com/a", withAgent) {
+		t.Error("Expected the user agent to change the cache key")
+	}
+}
+
+const testHTMLCached = `<html><body><div class="item"><h2>Cached</h2></div></body></html>`
+
+// TestScrapeURL_CacheHit tests that fresh entries are served without a request
+func TestScrapeURL_CacheHit(t *testing.T) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(testHTMLCached))
+	}))
+	defer server.Close()
+
+	cache, _ := NewFileCache(t.TempDir())
+	config := &Config{
+		Container:       "//div[@class='item']",
+		Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Cache:           cache,
+		CacheTTL:        time.Hour,
+	}
+
+	for i := 0; i < 3; i++ {
+		items, err := ScrapeURLUntyped(context.Background(), server.URL, config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(items) != 1 || items[0]["name"] != "Cached" {
+			t.Fatalf("Unexpected items: %v", items)
+		}
+	}
+
+	if full, notModified := atomic.LoadInt32(&full), atomic.LoadInt32(&notModified); full != 1 || notModified != 0 {
+		t.Errorf("Expected 1 full request and no revalidation, got %d full and %d conditional", full, notModified)
+	}
+}
+
+// TestScrapeURL_CacheRecorded tests that cache hits and revalidated responses reach the session and WARC archive
+func TestScrapeURL_CacheRecorded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(testHTMLCached))
+	}))
+	defer server.Close()
+
+	for _, ttl := range []time.Duration{time.Hour, 0} {
+		cache, _ := NewFileCache(t.TempDir())
+		sessionPath := filepath.Join(t.TempDir(), "session.har")
+		var archive bytes.Buffer
+		config := &Config{
+			Container:       "//div[@class='item']",
+			Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+			Timeout:         5 * time.Second,
+			AllowPrivateIPs: true, // Allow localhost for testing
+			Cache:           cache,
+			CacheTTL:        ttl,
+			Session:         RecordSession(sessionPath),
+			WARC:            NewWARCWriter(&archive, false),
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, err := ScrapeURLUntyped(context.Background(), server.URL, config); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if err := config.Session.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		if config.Session.Len() != 2 {
+			t.Errorf("TTL %v: expected 2 recorded exchanges, got %d", ttl, config.Session.Len())
+		}
+
+		archived, err := ScrapeWARC[map[string]any](context.Background(), bytes.NewReader(archive.Bytes()), config)
+		if err != nil {
+			t.Fatalf("ScrapeWARC failed: %v", err)
+		}
+		if archived.TotalPages != 2 || archived.TotalItems != 2 {
+			t.Errorf("TTL %v: expected 2 archived pages with items, got %d pages and %d items", ttl, archived.TotalPages, archived.TotalItems)
+		}
+
+		// Replaying without the cache gets full responses, not 304s
+		replay, err := ReplaySession(sessionPath)
+		if err != nil {
+			t.Fatalf("ReplaySession failed: %v", err)
+		}
+		replayConfig := *config
+		replayConfig.Cache = nil
+		replayConfig.WARC = nil
+		replayConfig.Session = replay
+		for i := 0; i < 2; i++ {
+			items, err := ScrapeURLUntyped(context.Background(), server.URL, &replayConfig)
+			if err != nil {
+				t.Fatalf("TTL %v: replay failed: %v", ttl, err)
+			}
+			if len(items) != 1 || items[0]["name"] != "Cached" {
+				t.Errorf("TTL %v: unexpected replayed items: %v", ttl, items)
+			}
+		}
+	}
+}
+
+// TestScrapeURL_CacheRevalidation tests that stale entries are revalidated with If-None-Match
+func TestScrapeURL_CacheRevalidation(t *testing.T) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(testHTMLCached))
+	}))
+	defer server.Close()
+
+	cache, _ := NewFileCache(t.TempDir())
+	config := &Config{
+		Container:       "//div[@class='item']",
+		Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true,  // Allow localhost for testing
+		Cache:           cache, // CacheTTL 0 revalidates every time
+	}
+
+	for i := 0; i < 3; i++ {
+		items, err := ScrapeURLUntyped(context.Background(), server.URL, config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(items) != 1 || items[0]["name"] != "Cached" {
+			t.Fatalf("Unexpected items: %v", items)
+		}
+	}
+
+	if full, notModified := atomic.LoadInt32(&full), atomic.LoadInt32(&notModified); full != 1 || notModified != 2 {
+		t.Errorf("Expected 1 full request and 2 revalidations, got %d full and %d conditional", full, notModified)
+	}
+}
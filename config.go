@@ -1,14 +1,15 @@
 package gtmlp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/antchfx/xpath"
 	"gopkg.in/yaml.v3"
 )
 
@@ -126,6 +127,35 @@ func applyEnvVars(cfg *Config, mapping *EnvMapping) {
 
 // Validate validates the config
 func (c *Config) Validate() error {
+	if err := c.validateExtraction(); err != nil {
+		return err
+	}
+
+	if c.Timeout <= 0 {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "timeout must be positive",
+		}
+	}
+
+	// Validate pagination config if present
+	if c.Pagination != nil {
+		if err := validatePaginationConfig(c.Pagination); err != nil {
+			return err
+		}
+		if types, ok := parserPaginationTypes[c.Parser]; ok && !slices.Contains(types, c.Pagination.Type) {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("%s pagination is not supported with the %s parser (use '%s')", c.Pagination.Type, c.Parser, strings.Join(types, "' or '")),
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateExtraction validates the container and field selectors
+func (c *Config) validateExtraction() error {
 	if c.Container == "" {
 		return &ScrapeError{
 			Type:    ErrTypeConfig,
@@ -133,8 +163,20 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if err := validateParser(c.Parser); err != nil {
+		return err
+	}
+	syntax := c.selectorSyntax()
+
+	// Validate embedded script definitions
+	for name, script := range c.Scripts {
+		if err := validateScriptConfig(name, script); err != nil {
+			return err
+		}
+	}
+
 	// Validate container XPath syntax
-	if _, err := xpath.Compile(c.Container); err != nil {
+	if err := c.validateContainerSelector(c.Container, syntax); err != nil {
 		return &ScrapeError{
 			Type:    ErrTypeXPath,
 			Message: "invalid container xpath syntax",
@@ -144,8 +186,37 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate altContainer XPath syntax
+	fieldSyntax := syntax
+	htmlContainers := syntax == syntaxHTML
+	switch {
+	case htmlContainers && isScriptSelector(c.Container):
+		fieldSyntax = syntaxJSONItem
+	case isTableSelector(c.Container):
+		if !htmlContainers {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("table: containers are not supported with the %s parser", c.Parser),
+				XPath:   c.Container,
+			}
+		}
+		fieldSyntax = syntaxTableRow
+	}
 	for i, altXPath := range c.AltContainer {
-		if _, err := xpath.Compile(altXPath); err != nil {
+		if htmlContainers && isScriptSelector(altXPath) != isScriptSelector(c.Container) {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("altContainer[%d] must be a script: selector if and only if container is", i),
+				XPath:   altXPath,
+			}
+		}
+		if htmlContainers && isTableSelector(altXPath) != isTableSelector(c.Container) {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("altContainer[%d] must be a table: selector if and only if container is", i),
+				XPath:   altXPath,
+			}
+		}
+		if err := c.validateContainerSelector(altXPath, syntax); err != nil {
 			return &ScrapeError{
 				Type:    ErrTypeXPath,
 				Message: fmt.Sprintf("invalid altContainer[%d] xpath syntax", i),
@@ -164,60 +235,332 @@ func (c *Config) Validate() error {
 
 	// Validate field XPath syntax
 	for fieldName, fieldConfig := range c.Fields {
-		if fieldConfig.XPath == "" {
+		if err := c.validateField("field", fieldName, fieldConfig, fieldSyntax); err != nil {
+			return err
+		}
+	}
+
+	// Validate document fields (extracted once per page, so they cannot follow links)
+	for fieldName, fieldConfig := range c.DocumentFields {
+		if err := c.validateField("document field", fieldName, fieldConfig, syntax); err != nil {
+			return err
+		}
+		if fieldConfig.Follow != nil {
 			return &ScrapeError{
 				Type:    ErrTypeConfig,
-				Message: fmt.Sprintf("field '%s' xpath is required", fieldName),
+				Message: fmt.Sprintf("document field '%s' cannot use follow", fieldName),
 			}
 		}
+		if _, clash := c.Fields[fieldName]; clash && c.CopyDocumentFields {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("document field '%s' is also an item field", fieldName),
+			}
+		}
+	}
+
+	// Validate dedup key fields
+	if c.Dedup != nil {
+		columns := ConfigColumns(c)
+		for _, key := range c.Dedup.KeyFields {
+			if !slices.Contains(columns, key) {
+				return &ScrapeError{
+					Type:    ErrTypeConfig,
+					Message: fmt.Sprintf("dedup key field '%s' is not a config field", key),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateField checks the selectors and follow config of one field. kind names the field in errors.
+func (c *Config) validateField(kind, fieldName string, fieldConfig FieldConfig, syntax selectorSyntax) error {
+	if fieldConfig.XPath == "" {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("%s '%s' xpath is required", kind, fieldName),
+		}
+	}
+
+	// Nested fields select elements rather than values
+	validateSelector := c.validateFieldSelector
+	if isNestedField(fieldConfig) {
+		validateSelector = c.validateNestedSelector
+	}
+
+	// Validate primary XPath
+	if err := validateSelector(fieldConfig.XPath, syntax); err != nil {
+		return &ScrapeError{
+			Type:    ErrTypeXPath,
+			Message: fmt.Sprintf("invalid xpath for %s '%s'", kind, fieldName),
+			XPath:   fieldConfig.XPath,
+			Cause:   err,
+		}
+	}
 
-		// Validate primary XPath
-		if _, err := xpath.Compile(fieldConfig.XPath); err != nil {
+	// Validate altXpath entries
+	for i, altXPath := range fieldConfig.AltXPath {
+		if err := validateSelector(altXPath, syntax); err != nil {
 			return &ScrapeError{
 				Type:    ErrTypeXPath,
-				Message: fmt.Sprintf("invalid xpath for field '%s'", fieldName),
-				XPath:   fieldConfig.XPath,
+				Message: fmt.Sprintf("invalid altXpath[%d] for %s '%s'", i, kind, fieldName),
+				XPath:   altXPath,
 				Cause:   err,
 			}
 		}
+	}
 
-		// Validate altXpath entries
-		for i, altXPath := range fieldConfig.AltXPath {
-			if _, err := xpath.Compile(altXPath); err != nil {
+	if err := validateOutput(fieldConfig.Output); err != nil {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("invalid output for %s '%s'", kind, fieldName),
+			Cause:   err,
+		}
+	}
+	if fieldConfig.Output != "" && fieldConfig.Output != OutputText {
+		for _, selector := range append([]string{fieldConfig.XPath}, fieldConfig.AltXPath...) {
+			if !supportsOutput(selector, syntax) {
 				return &ScrapeError{
-					Type:    ErrTypeXPath,
-					Message: fmt.Sprintf("invalid altXpath[%d] for field '%s'", i, fieldName),
-					XPath:   altXPath,
-					Cause:   err,
+					Type:    ErrTypeConfig,
+					Message: fmt.Sprintf("output '%s' for %s '%s' needs XPath, css: or column: selectors", fieldConfig.Output, kind, fieldName),
+					XPath:   selector,
 				}
 			}
 		}
 	}
 
-	if c.Timeout <= 0 {
+	if err := c.validateNestedField(kind, fieldName, fieldConfig, syntax); err != nil {
+		return err
+	}
+
+	if err := validateFieldType(fieldConfig); err != nil {
 		return &ScrapeError{
 			Type:    ErrTypeConfig,
-			Message: "timeout must be positive",
+			Message: fmt.Sprintf("invalid type for %s '%s'", kind, fieldName),
+			Cause:   err,
+		}
+	}
+	if fieldConfig.Default != nil {
+		if _, err := coerceValue(context.Background(), fieldConfig, fieldConfig.Default); err != nil {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("invalid default for %s '%s'", kind, fieldName),
+				Cause:   err,
+			}
 		}
 	}
 
-	// Validate pagination config if present
-	if c.Pagination != nil {
-		if err := validatePaginationConfig(c.Pagination); err != nil {
-			return err
+	// Validate detail page config
+	if fieldConfig.Follow != nil {
+		if err := validateFollowConfig(fieldConfig.Follow); err != nil {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("invalid follow config for %s '%s'", kind, fieldName),
+				Cause:   err,
+			}
 		}
 	}
 
 	return nil
 }
 
+// selectorSyntax is the selector language a config position accepts
+type selectorSyntax int
+
+const (
+	syntaxHTML     selectorSyntax = iota // XPath, css:, script: and structured data selectors
+	syntaxJSON                           // JSON paths into a JSON document
+	syntaxJSONItem                       // JSON paths into script: container elements, or script: selectors
+	syntaxXML                            // XPath and css: selectors with Config.Namespaces prefixes
+	syntaxTableRow                       // HTML syntax relative to a table row, or column: selectors
+	syntaxAuto                           // Parser chosen per response: JSON paths start with "$", anything else is XPath or css:
+)
+
+// selectorSyntax returns the selector syntax of the config's parser
+func (c *Config) selectorSyntax() selectorSyntax {
+	switch c.Parser {
+	case ParserJSON:
+		return syntaxJSON
+	case ParserXML:
+		return syntaxXML
+	case ParserAuto:
+		return syntaxAuto
+	}
+	return syntaxHTML
+}
+
+// supportsOutput reports whether a field selector honors FieldConfig.Output.
+// JSON paths, script: and structured data selectors return their values unchanged.
+func supportsOutput(selector string, syntax selectorSyntax) bool {
+	switch {
+	case syntax == syntaxJSON, syntax == syntaxJSONItem:
+		return false
+	case syntax == syntaxAuto && strings.HasPrefix(selector, "$"):
+		return false
+	}
+	return !isScriptSelector(selector) && !isStructuredSelector(selector)
+}
+
+// isHTMLOnlySelector reports whether a selector only runs on HTML pages
+func isHTMLOnlySelector(selector string) bool {
+	return isScriptSelector(selector) || isStructuredSelector(selector) ||
+		isTableSelector(selector) || isColumnSelector(selector)
+}
+
+// autoSelectorError rejects selectors that extract nothing once the auto parser picks JSON or XML
+func autoSelectorError(selector string) error {
+	return fmt.Errorf("selector %q requires the html parser, not auto", selector)
+}
+
+// validateContainerSelector checks a container selector: XPath, css:, script:, table: or a JSON path
+func (c *Config) validateContainerSelector(selector string, syntax selectorSyntax) error {
+	switch syntax {
+	case syntaxJSON:
+		_, err := splitJSONPath(selector)
+		return err
+	case syntaxXML:
+		_, err := compileXMLSelector(selector, c.Namespaces)
+		return err
+	case syntaxAuto:
+		if strings.HasPrefix(selector, "$") {
+			return c.validateContainerSelector(selector, syntaxJSON)
+		}
+		if isHTMLOnlySelector(selector) {
+			return autoSelectorError(selector)
+		}
+		return c.validateContainerSelector(selector, syntaxHTML)
+	}
+
+	if isScriptSelector(selector) {
+		return validateScriptSelector(selector, c.Scripts)
+	}
+	_, err := compileSelector(strings.TrimPrefix(selector, tablePrefix))
+	return err
+}
+
+// validateFieldSelector checks a field selector against syntax
+func (c *Config) validateFieldSelector(selector string, syntax selectorSyntax) error {
+	switch syntax {
+	case syntaxJSON:
+		_, err := splitJSONPath(selector)
+		return err
+	case syntaxJSONItem:
+		if isScriptSelector(selector) {
+			return validateScriptSelector(selector, c.Scripts)
+		}
+		_, err := splitJSONPath(selector)
+		return err
+	case syntaxXML:
+		_, err := compileXMLSelector(selector, c.Namespaces)
+		return err
+	case syntaxTableRow:
+		if isColumnSelector(selector) {
+			return validateColumnSelector(selector)
+		}
+		return c.validateFieldSelector(selector, syntaxHTML)
+	case syntaxAuto:
+		if strings.HasPrefix(selector, "$") {
+			return c.validateFieldSelector(selector, syntaxJSON)
+		}
+		if isHTMLOnlySelector(selector) {
+			return autoSelectorError(selector)
+		}
+		return c.validateFieldSelector(selector, syntaxHTML)
+	}
+
+	if isScriptSelector(selector) {
+		return validateScriptSelector(selector, c.Scripts)
+	}
+	if isStructuredSelector(selector) {
+		_, err := parseStructuredSelector(selector)
+		return err
+	}
+	if isColumnSelector(selector) {
+		return fmt.Errorf("column selector %q requires a table: container", selector)
+	}
+	_, err := compileSelector(selector)
+	return err
+}
+
+// validateFollowConfig validates a detail page config.
+// Container is optional and HTTP settings are inherited from the parent config.
+func validateFollowConfig(detail *Config) error {
+	if detail.Pagination != nil {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "pagination is not supported for detail pages",
+		}
+	}
+	if detail.Parser == ParserJSON || detail.Parser == ParserXML || detail.Parser == ParserAuto {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("%s parser is not supported for detail pages", detail.Parser),
+		}
+	}
+
+	return followConfig(detail).validateExtraction()
+}
+
 // validatePaginationConfig validates pagination configuration
 func validatePaginationConfig(p *PaginationConfig) error {
 	// Validate type
-	if p.Type != "next-link" && p.Type != "numbered" {
+	switch p.Type {
+	case "next-link", "numbered", "link-header", "json", "form":
+	default:
 		return &ScrapeError{
 			Type:    ErrTypeConfig,
-			Message: fmt.Sprintf("invalid pagination type: %s (must be 'next-link' or 'numbered')", p.Type),
+			Message: fmt.Sprintf("invalid pagination type: %s (must be 'next-link', 'numbered', 'link-header', 'json' or 'form')", p.Type),
+		}
+	}
+
+	// Validate form selectors
+	if p.Type == "form" {
+		if p.FormSelector == "" {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: "formSelector is required for form pagination",
+			}
+		}
+
+		if _, err := compileSelector(p.FormSelector); err != nil {
+			return &ScrapeError{
+				Type:    ErrTypeXPath,
+				Message: "invalid formSelector xpath syntax",
+				XPath:   p.FormSelector,
+				Cause:   err,
+			}
+		}
+
+		// nextSelector is optional for form pagination
+		if p.NextSelector != "" {
+			if _, err := compileSelector(p.NextSelector); err != nil {
+				return &ScrapeError{
+					Type:    ErrTypeXPath,
+					Message: "invalid nextSelector xpath syntax",
+					XPath:   p.NextSelector,
+					Cause:   err,
+				}
+			}
+		}
+	}
+
+	// Validate json path
+	if p.Type == "json" {
+		if p.JSONPath == "" {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: "jsonPath is required for json pagination",
+			}
+		}
+
+		if _, err := splitJSONPath(p.JSONPath); err != nil {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: "invalid jsonPath syntax",
+				Cause:   err,
+			}
 		}
 	}
 
@@ -231,7 +574,7 @@ func validatePaginationConfig(p *PaginationConfig) error {
 		}
 
 		// Validate nextSelector XPath syntax
-		if _, err := xpath.Compile(p.NextSelector); err != nil {
+		if _, err := compileSelector(p.NextSelector); err != nil {
 			return &ScrapeError{
 				Type:    ErrTypeXPath,
 				Message: "invalid nextSelector xpath syntax",
@@ -242,7 +585,7 @@ func validatePaginationConfig(p *PaginationConfig) error {
 
 		// Validate altSelectors
 		for i, altSelector := range p.AltSelectors {
-			if _, err := xpath.Compile(altSelector); err != nil {
+			if _, err := compileSelector(altSelector); err != nil {
 				return &ScrapeError{
 					Type:    ErrTypeXPath,
 					Message: fmt.Sprintf("invalid altSelectors[%d] xpath syntax", i),
@@ -255,7 +598,9 @@ func validatePaginationConfig(p *PaginationConfig) error {
 
 	// Validate numbered selectors
 	if p.Type == "numbered" {
-		if p.PageSelector == "" {
+		// pageSelector may be omitted when the page range is inferred from an explicit pattern
+		inferredRange := p.LastPageSelector != "" && p.PageURLPattern != ""
+		if p.PageSelector == "" && !inferredRange {
 			return &ScrapeError{
 				Type:    ErrTypeConfig,
 				Message: "pageSelector is required for numbered pagination",
@@ -263,16 +608,45 @@ func validatePaginationConfig(p *PaginationConfig) error {
 		}
 
 		// Validate pageSelector XPath syntax
-		if _, err := xpath.Compile(p.PageSelector); err != nil {
+		if p.PageSelector != "" {
+			if _, err := compileSelector(p.PageSelector); err != nil {
+				return &ScrapeError{
+					Type:    ErrTypeXPath,
+					Message: "invalid pageSelector xpath syntax",
+					XPath:   p.PageSelector,
+					Cause:   err,
+				}
+			}
+		}
+
+		// Validate lastPageSelector XPath syntax
+		if p.LastPageSelector != "" {
+			if _, err := compileSelector(p.LastPageSelector); err != nil {
+				return &ScrapeError{
+					Type:    ErrTypeXPath,
+					Message: "invalid lastPageSelector xpath syntax",
+					XPath:   p.LastPageSelector,
+					Cause:   err,
+				}
+			}
+		}
+
+		if p.PageURLPattern != "" && !strings.Contains(p.PageURLPattern, pagePlaceholder) {
 			return &ScrapeError{
-				Type:    ErrTypeXPath,
-				Message: "invalid pageSelector xpath syntax",
-				XPath:   p.PageSelector,
-				Cause:   err,
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("pageUrlPattern must contain %s", pagePlaceholder),
 			}
 		}
 	}
 
+	// Validate error policy
+	if p.OnError != "" && p.OnError != OnErrorAbort && p.OnError != OnErrorSkip {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("invalid pagination onError: %s (must be 'abort' or 'skip')", p.OnError),
+		}
+	}
+
 	// Validate limits
 	if p.MaxPages < 0 {
 		return &ScrapeError{
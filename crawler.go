@@ -0,0 +1,518 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+)
+
+// Default crawl configuration
+const (
+	DefaultCrawlMaxDepth = 3
+	DefaultCrawlMaxPages = 1000
+	DefaultCrawlWorkers  = 4
+)
+
+// CrawlSeedsOnly is a MaxDepth that fetches the seeds without following links.
+// A MaxDepth of 0 means the default depth.
+const CrawlSeedsOnly = -1
+
+// CrawlConfig defines a multi-level crawl: seed URLs, page types with their
+// link-extraction rules and extraction configs, and scope limits
+type CrawlConfig struct {
+	Seeds     []string             // Start URLs
+	SeedType  string               // Page type of the seed URLs
+	PageTypes map[string]*PageType // Page type name → PageType
+
+	// Scope
+	MaxDepth   int      // Maximum link depth from the seeds (default: 3, CrawlSeedsOnly for none)
+	MaxPages   int      // Maximum pages to fetch (default: 1000)
+	SameDomain bool     // Only follow links on the seed hosts
+	Allow      []string // URL regexes; if set, links must match at least one
+	Deny       []string // URL regexes; matching links are skipped
+
+	Workers int     // Concurrent page fetches (default: 4)
+	Fetch   *Config // HTTP and security settings for all fetches (default: 30s timeout)
+}
+
+// PageType describes one kind of page in a crawl
+type PageType struct {
+	Links  []LinkRule // Rules for discovering linked pages
+	Config *Config    // Optional extraction config; items are emitted for each container
+}
+
+// LinkRule extracts links to pages of a given type
+type LinkRule struct {
+	XPath    string   // XPath selecting link URLs, e.g. //a[@class='category']/@href
+	Pipes    []string // URL transformation pipes
+	PageType string   // Page type of the linked pages
+}
+
+// CrawlItem is an item extracted during a crawl
+type CrawlItem struct {
+	PageType  string         // Page type the item was extracted from
+	SourceURL string         // URL of the page the item was extracted from
+	Depth     int            // Link depth of the source page
+	Data      map[string]any // Extracted fields
+	Document  map[string]any // Document field values of the source page (see Config.DocumentFields)
+}
+
+// CrawlError records a page that failed during a crawl
+type CrawlError struct {
+	URL      string
+	PageType string
+	Depth    int
+	Cause    error
+}
+
+func (e *CrawlError) Error() string {
+	return fmt.Sprintf("crawl failed at %s page (%s): %v", e.PageType, e.URL, e.Cause)
+}
+
+func (e *CrawlError) Unwrap() error {
+	return e.Cause
+}
+
+// CrawlResult contains crawl output and statistics
+type CrawlResult struct {
+	Items  []CrawlItem   // Collected items (empty when using CrawlFunc)
+	Pages  int           // Pages fetched successfully
+	Errors []*CrawlError // Pages that failed
+}
+
+// crawlTask is a frontier entry
+type crawlTask struct {
+	url      string
+	pageType string
+	depth    int
+}
+
+// crawlOutcome is the result of processing one crawl task
+type crawlOutcome struct {
+	task     crawlTask
+	items    []map[string]any
+	document map[string]any
+	links    []crawlTask
+	err      error
+}
+
+// Crawl runs a crawl and returns all extracted items
+func Crawl(ctx context.Context, config *CrawlConfig) (*CrawlResult, error) {
+	var items []CrawlItem
+	result, err := CrawlFunc(ctx, config, func(item CrawlItem) error {
+		items = append(items, item)
+		return nil
+	})
+	if result != nil {
+		result.Items = items
+	}
+	return result, err
+}
+
+// CrawlFunc runs a crawl and calls fn for each extracted item as pages complete.
+// fn is never called concurrently. Failed pages are recorded in the result and
+// the crawl continues; an error returned by fn stops the crawl.
+func CrawlFunc(ctx context.Context, config *CrawlConfig, fn func(CrawlItem) error) (*CrawlResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	scope, err := newCrawlScope(config)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDepth := config.MaxDepth
+	switch maxDepth {
+	case 0:
+		maxDepth = DefaultCrawlMaxDepth
+	case CrawlSeedsOnly:
+		maxDepth = 0
+	}
+	maxPages := config.MaxPages
+	if maxPages == 0 {
+		maxPages = DefaultCrawlMaxPages
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = DefaultCrawlWorkers
+	}
+	fetchConfig := config.Fetch
+	if fetchConfig == nil {
+		fetchConfig = &Config{Timeout: 30 * time.Second, UserAgent: "GTMLP/2.0"}
+	}
+
+	getLogger().Info("crawl starting",
+		"seeds", len(config.Seeds),
+		"max_depth", maxDepth,
+		"max_pages", maxPages,
+		"workers", workers)
+
+	// Deduplicating frontier
+	seen := make(map[string]bool)
+	var queue []crawlTask
+	for _, seed := range config.Seeds {
+		key := canonicalURL(seed)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		queue = append(queue, crawlTask{url: seed, pageType: config.SeedType})
+	}
+
+	// Worker pool
+	tasks := make(chan crawlTask)
+	outcomes := make(chan crawlOutcome)
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range tasks {
+				outcome := processCrawlTask(workerCtx, task, config, fetchConfig)
+				select {
+				case outcomes <- outcome:
+				case <-workerCtx.Done():
+					return
+				}
+			}
+		}()
+	}
+	defer close(tasks)
+
+	result := &CrawlResult{}
+	dispatched, inFlight := 0, 0
+	startTime := time.Now()
+
+	for inFlight > 0 || (len(queue) > 0 && dispatched < maxPages) {
+		var send chan crawlTask
+		var next crawlTask
+		if len(queue) > 0 && dispatched < maxPages {
+			send = tasks
+			next = queue[0]
+		}
+
+		select {
+		case send <- next:
+			queue = queue[1:]
+			dispatched++
+			inFlight++
+
+		case outcome := <-outcomes:
+			inFlight--
+			task := outcome.task
+
+			if outcome.err != nil {
+				getLogger().Warn("crawl page failed",
+					"url", task.url,
+					"page_type", task.pageType,
+					"depth", task.depth,
+					"error", outcome.err.Error())
+				result.Errors = append(result.Errors, &CrawlError{
+					URL:      task.url,
+					PageType: task.pageType,
+					Depth:    task.depth,
+					Cause:    outcome.err,
+				})
+				continue
+			}
+
+			result.Pages++
+			getLogger().Info("crawl page scraped",
+				"url", task.url,
+				"page_type", task.pageType,
+				"depth", task.depth,
+				"items", len(outcome.items),
+				"links", len(outcome.links))
+
+			for _, data := range outcome.items {
+				item := CrawlItem{
+					PageType:  task.pageType,
+					SourceURL: task.url,
+					Depth:     task.depth,
+					Data:      data,
+					Document:  outcome.document,
+				}
+				if err := fn(item); err != nil {
+					return result, err
+				}
+			}
+
+			// Enqueue new in-scope links
+			for _, link := range outcome.links {
+				if link.depth > maxDepth {
+					continue
+				}
+				key := canonicalURL(link.url)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if !scope.allows(link.url) {
+					getLogger().Debug("crawl link out of scope",
+						"url", link.url)
+					continue
+				}
+				queue = append(queue, link)
+			}
+
+		case <-ctx.Done():
+			getLogger().Warn("crawl cancelled",
+				"pages", result.Pages,
+				"queued", len(queue))
+			return result, ctx.Err()
+		}
+	}
+
+	if len(queue) > 0 {
+		getLogger().Warn("crawl max pages reached",
+			"max_pages", maxPages,
+			"queued", len(queue))
+	}
+
+	getLogger().Info("crawl complete",
+		"pages", result.Pages,
+		"errors", len(result.Errors),
+		"duration", time.Since(startTime).String())
+
+	return result, nil
+}
+
+// processCrawlTask fetches a page, extracts its items and discovers linked pages
+func processCrawlTask(ctx context.Context, task crawlTask, config *CrawlConfig, fetchConfig *Config) crawlOutcome {
+	outcome := crawlOutcome{task: task}
+	pageType := config.PageTypes[task.pageType]
+
+	_, doc, err := fetchDocumentRequest(newGetRequest(task.url).withContext(ctx), fetchConfig)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+
+	pageCtx := withFetchConfig(WithURL(ctx, task.url), fetchConfig)
+
+	if pageType.Config != nil {
+		outcome.items, outcome.document, err = extractPage(pageCtx, doc, followConfig(pageType.Config))
+		if err != nil {
+			outcome.err = err
+			return outcome
+		}
+	}
+
+	for _, rule := range pageType.Links {
+		expr, err := compileSelector(rule.XPath)
+		if err != nil {
+			outcome.err = &ScrapeError{
+				Type:    ErrTypeXPath,
+				Message: "invalid link rule xpath",
+				XPath:   rule.XPath,
+				Cause:   err,
+			}
+			return outcome
+		}
+
+		nodeIterator := expr.Evaluate(htmlquery.CreateXPathNavigator(doc)).(*xpath.NodeIterator)
+		for nodeIterator.MoveNext() {
+			rawURL := strings.TrimSpace(nodeIterator.Current().Value())
+			if rawURL == "" {
+				continue
+			}
+
+			processedURL, err := applyPipesToURL(pageCtx, rawURL, rule.Pipes)
+			if err != nil || processedURL == "" {
+				continue
+			}
+
+			absoluteURL, err := resolveURL(task.url, processedURL)
+			if err != nil {
+				continue
+			}
+
+			outcome.links = append(outcome.links, crawlTask{
+				url:      absoluteURL,
+				pageType: rule.PageType,
+				depth:    task.depth + 1,
+			})
+		}
+	}
+
+	return outcome
+}
+
+// crawlScope decides which discovered URLs may be crawled
+type crawlScope struct {
+	hosts map[string]bool
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// newCrawlScope compiles the scope rules of a crawl config
+func newCrawlScope(config *CrawlConfig) (*crawlScope, error) {
+	scope := &crawlScope{}
+
+	if config.SameDomain {
+		scope.hosts = make(map[string]bool)
+		for _, seed := range config.Seeds {
+			if u, err := url.Parse(seed); err == nil {
+				scope.hosts[strings.ToLower(u.Hostname())] = true
+			}
+		}
+	}
+
+	compile := func(patterns []string, name string) ([]*regexp.Regexp, error) {
+		var compiled []*regexp.Regexp
+		for i, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, &ScrapeError{
+					Type:    ErrTypeConfig,
+					Message: fmt.Sprintf("invalid %s[%d] pattern", name, i),
+					Cause:   err,
+				}
+			}
+			compiled = append(compiled, re)
+		}
+		return compiled, nil
+	}
+
+	var err error
+	if scope.allow, err = compile(config.Allow, "allow"); err != nil {
+		return nil, err
+	}
+	if scope.deny, err = compile(config.Deny, "deny"); err != nil {
+		return nil, err
+	}
+
+	return scope, nil
+}
+
+// allows reports whether rawURL is within the crawl scope
+func (s *crawlScope) allows(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	if s.hosts != nil && !s.hosts[strings.ToLower(u.Hostname())] {
+		return false
+	}
+
+	for _, re := range s.deny {
+		if re.MatchString(rawURL) {
+			return false
+		}
+	}
+
+	if len(s.allow) == 0 {
+		return true
+	}
+	for _, re := range s.allow {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalURL extends normalizeURL for the crawl frontier:
+// scheme and host are lowercased and default ports dropped
+func canonicalURL(rawURL string) string {
+	normalized := normalizeURL(rawURL)
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return normalized
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u.String()
+}
+
+// Validate validates the crawl config
+func (c *CrawlConfig) Validate() error {
+	if len(c.Seeds) == 0 {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "at least one seed URL is required",
+		}
+	}
+
+	if _, ok := c.PageTypes[c.SeedType]; !ok {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("seed page type '%s' is not defined", c.SeedType),
+		}
+	}
+
+	if c.MaxDepth < CrawlSeedsOnly {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "maxDepth must be non-negative or CrawlSeedsOnly",
+		}
+	}
+	if c.MaxPages < 0 {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "maxPages must be non-negative",
+		}
+	}
+
+	for name, pageType := range c.PageTypes {
+		if pageType == nil {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("page type '%s' is nil", name),
+			}
+		}
+
+		for i, rule := range pageType.Links {
+			if _, err := compileSelector(rule.XPath); err != nil {
+				return &ScrapeError{
+					Type:    ErrTypeXPath,
+					Message: fmt.Sprintf("invalid links[%d] xpath for page type '%s'", i, name),
+					XPath:   rule.XPath,
+					Cause:   err,
+				}
+			}
+
+			if _, ok := c.PageTypes[rule.PageType]; !ok {
+				return &ScrapeError{
+					Type:    ErrTypeConfig,
+					Message: fmt.Sprintf("links[%d] of page type '%s' target undefined page type '%s'", i, name, rule.PageType),
+				}
+			}
+		}
+
+		if pageType.Config != nil {
+			if err := followConfig(pageType.Config).validateExtraction(); err != nil {
+				return &ScrapeError{
+					Type:    ErrTypeConfig,
+					Message: fmt.Sprintf("invalid config for page type '%s'", name),
+					Cause:   err,
+				}
+			}
+		}
+	}
+
+	if c.Fetch != nil && c.Fetch.Timeout <= 0 {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "fetch timeout must be positive",
+		}
+	}
+
+	return nil
+}
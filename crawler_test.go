@@ -0,0 +1,342 @@
+package gtmlp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Test site for crawling: home → categories → listings
+var testCrawlPages = map[string]string{
+	"/": `<html><body>
+		<a class="cat" href="/cat/shoes">Shoes</a>
+		<a class="cat" href="/cat/hats">Hats</a>
+		<a class="cat" href="https://external.example.com/cat/x">External</a>
+		<a class="cat" href="/cat/private">Private</a>
+	</body></html>`,
+	"/cat/shoes": `<html><body><h1>Shoes</h1>
+		<a class="sub" href="/list/sneakers">Sneakers</a>
+		<a class="sub" href="/list/boots#top">Boots</a>
+	</body></html>`,
+	"/cat/hats": `<html><body><h1>Hats</h1>
+		<a class="sub" href="/list/caps">Caps</a>
+		<a class="sub" href="/list/boots">Boots again</a>
+	</body></html>`,
+	"/list/sneakers": `<html><body>
+		<div class="product"><h2>Runner</h2></div>
+		<div class="product"><h2>Court</h2></div>
+	</body></html>`,
+	"/list/boots": `<html><body><div class="product"><h2>Hiker</h2></div></body></html>`,
+	"/list/caps":  `<html><body><div class="product"><h2>Snapback</h2></div></body></html>`,
+}
+
+// TestCrawl_MultiLevel tests crawling categories, subcategories and listings
+func TestCrawl_MultiLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := testCrawlPages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	config := &CrawlConfig{
+		Seeds:    []string{server.URL + "/"},
+		SeedType: "home",
+		PageTypes: map[string]*PageType{
+			"home": {
+				Links: []LinkRule{{XPath: `//a[@class="cat"]/@href`, PageType: "category"}},
+			},
+			"category": {
+				Links: []LinkRule{{XPath: `//a[@class="sub"]/@href`, PageType: "listing"}},
+				Config: &Config{
+					Fields:         map[string]FieldConfig{"title": {XPath: `//h1/text()`}},
+					DocumentFields: map[string]FieldConfig{"first": {XPath: `//a[@class="sub"][1]/text()`}},
+				},
+			},
+			"listing": {
+				Config: &Config{
+					Container: `//div[@class="product"]`,
+					Fields:    map[string]FieldConfig{"name": {XPath: `.//h2/text()`}},
+				},
+			},
+		},
+		SameDomain: true,
+		Deny:       []string{`/private`},
+		Workers:    3,
+		Fetch: &Config{
+			Timeout:         30 * time.Second,
+			AllowPrivateIPs: true, // Allow localhost for testing
+		},
+	}
+
+	result, err := Crawl(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+
+	// home + 2 categories + 3 listings (boots deduplicated)
+	if result.Pages != 6 {
+		t.Errorf("Expected 6 pages, got %d", result.Pages)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", result.Errors)
+	}
+
+	var products, categories []string
+	for _, item := range result.Items {
+		switch item.PageType {
+		case "listing":
+			products = append(products, item.Data["name"].(string))
+			if item.Depth != 2 {
+				t.Errorf("Expected listing depth 2, got %d", item.Depth)
+			}
+		case "category":
+			categories = append(categories, item.Data["title"].(string))
+			if first := item.Document["first"]; first != "Sneakers" && first != "Caps" {
+				t.Errorf("Expected document fields on category items, got %v", item.Document)
+			}
+		}
+		if item.SourceURL == "" {
+			t.Error("Expected source URL on item")
+		}
+	}
+	sort.Strings(products)
+	sort.Strings(categories)
+
+	if len(products) != 4 || products[0] != "Court" || products[3] != "Snapback" {
+		t.Errorf("Unexpected products: %v", products)
+	}
+	if len(categories) != 2 || categories[0] != "Hats" {
+		t.Errorf("Unexpected categories: %v", categories)
+	}
+}
+
+// TestCrawl_MaxDepth tests that links beyond the max depth are not followed
+func TestCrawl_MaxDepth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := testCrawlPages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	config := &CrawlConfig{
+		Seeds:    []string{server.URL + "/"},
+		SeedType: "home",
+		PageTypes: map[string]*PageType{
+			"home": {
+				Links: []LinkRule{{XPath: `//a[@class="cat"]/@href`, PageType: "category"}},
+			},
+			"category": {
+				Links: []LinkRule{{XPath: `//a[@class="sub"]/@href`, PageType: "listing"}},
+				Config: &Config{
+					Fields: map[string]FieldConfig{"title": {XPath: `//h1/text()`}},
+				},
+			},
+			"listing": {
+				Config: &Config{
+					Container: `//div[@class="product"]`,
+					Fields:    map[string]FieldConfig{"name": {XPath: `.//h2/text()`}},
+				},
+			},
+		},
+		SameDomain: true,
+		Deny:       []string{`/private`},
+		MaxDepth:   1,
+		Workers:    3,
+		Fetch: &Config{
+			Timeout:         30 * time.Second,
+			AllowPrivateIPs: true, // Allow localhost for testing
+		},
+	}
+
+	result, err := Crawl(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+
+	if result.Pages != 3 {
+		t.Errorf("Expected home and 2 categories, got %d pages", result.Pages)
+	}
+	for _, item := range result.Items {
+		if item.PageType == "listing" {
+			t.Errorf("Unexpected listing item at depth %d", item.Depth)
+		}
+	}
+
+	// CrawlSeedsOnly fetches the seeds without following links
+	config.MaxDepth = CrawlSeedsOnly
+	result, err = Crawl(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	if result.Pages != 1 {
+		t.Errorf("Expected only the seed, got %d pages", result.Pages)
+	}
+}
+
+// TestCrawlFunc_StopOnHandlerError tests that a handler error stops the crawl
+func TestCrawlFunc_StopOnHandlerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := testCrawlPages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	config := &CrawlConfig{
+		Seeds:    []string{server.URL + "/"},
+		SeedType: "home",
+		PageTypes: map[string]*PageType{
+			"home": {
+				Links: []LinkRule{{XPath: `//a[@class="cat"]/@href`, PageType: "category"}},
+			},
+			"category": {
+				Config: &Config{
+					Fields: map[string]FieldConfig{"title": {XPath: `//h1/text()`}},
+				},
+			},
+		},
+		SameDomain: true,
+		Fetch: &Config{
+			Timeout:         30 * time.Second,
+			AllowPrivateIPs: true, // Allow localhost for testing
+		},
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	_, err := CrawlFunc(context.Background(), config, func(item CrawlItem) error {
+		calls++
+		return errStop
+	})
+
+	if !errors.Is(err, errStop) {
+		t.Errorf("Expected handler error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 handler call, got %d", calls)
+	}
+}
+
+// TestCrawl_RecordsFailedPages tests that failed pages are recorded and the crawl continues
+func TestCrawl_RecordsFailedPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := testCrawlPages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	config := &CrawlConfig{
+		Seeds:    []string{server.URL + "/"},
+		SeedType: "home",
+		PageTypes: map[string]*PageType{
+			"home": {
+				Links: []LinkRule{{XPath: `//a[@class="cat"]/@href`, PageType: "category"}},
+			},
+			"category": {
+				Config: &Config{
+					Fields: map[string]FieldConfig{"title": {XPath: `//h1/text()`}},
+				},
+			},
+		},
+		SameDomain: true,
+		Fetch: &Config{
+			Timeout:         30 * time.Second,
+			AllowPrivateIPs: true, // Allow localhost for testing
+		},
+	}
+
+	result, err := Crawl(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].PageType != "category" {
+		t.Fatalf("Expected /cat/private to fail, got %v", result.Errors)
+	}
+	if !Is(result.Errors[0], ErrTypeNetwork) {
+		t.Errorf("Expected network error, got %v", result.Errors[0].Cause)
+	}
+}
+
+// TestCanonicalURL tests frontier URL normalization
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"HTTPS://Example.COM:443/a/?b=2&a=1#x", "https://example.com/a?a=1&b=2"},
+		{"http://example.com:80", "http://example.com/"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+	}
+
+	for _, tt := range tests {
+		if result := canonicalURL(tt.input); result != tt.expected {
+			t.Errorf("canonicalURL(%s) = %s, expected %s", tt.input, result, tt.expected)
+		}
+	}
+}
+
+// TestCrawlConfig_Validate tests crawl config validation
+func TestCrawlConfig_Validate(t *testing.T) {
+	config := &CrawlConfig{
+		Seeds:    []string{"https://example.com/"},
+		SeedType: "home",
+		PageTypes: map[string]*PageType{
+			"home": {
+				Links: []LinkRule{{XPath: `//a[@class="cat"]/@href`, PageType: "category"}},
+			},
+			"category": {
+				Config: &Config{
+					Fields: map[string]FieldConfig{"title": {XPath: `//h1/text()`}},
+				},
+			},
+		},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+
+	config.PageTypes["home"].Links = append(config.PageTypes["home"].Links, LinkRule{XPath: "//a/@href", PageType: "missing"})
+	if err := config.Validate(); !Is(err, ErrTypeConfig) {
+		t.Errorf("Expected config error for undefined page type, got %v", err)
+	}
+
+	config.PageTypes["home"].Links = config.PageTypes["home"].Links[:1]
+	config.MaxDepth = CrawlSeedsOnly
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected CrawlSeedsOnly to be valid, got %v", err)
+	}
+	config.MaxDepth = -2
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for negative maxDepth, got nil")
+	}
+
+	config.MaxDepth = 0
+	config.SeedType = "unknown"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for undefined seed type, got nil")
+	}
+}
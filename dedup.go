@@ -0,0 +1,313 @@
+package gtmlp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultIDField is the item field that receives the stable item ID
+const DefaultIDField = "_id"
+
+// SeenStore remembers item IDs so duplicates can be dropped.
+// IDs are checked during extraction and only added once their page's items
+// have been returned or written, so a failed page is scraped again next run.
+// Implementations must be safe for concurrent use.
+type SeenStore interface {
+	// Has reports whether id has been added
+	Has(id string) (bool, error)
+	// Add records ids as seen
+	Add(ids ...string) error
+}
+
+// MemorySeenStore is an in-memory SeenStore
+type MemorySeenStore struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+// NewMemorySeenStore creates an empty in-memory store
+func NewMemorySeenStore() *MemorySeenStore {
+	return &MemorySeenStore{ids: make(map[string]struct{})}
+}
+
+// Has reports whether id has been added
+func (s *MemorySeenStore) Has(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.ids[id]
+	return ok, nil
+}
+
+// Add records ids as seen
+func (s *MemorySeenStore) Add(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.ids[id] = struct{}{}
+	}
+	return nil
+}
+
+// FileSeenStore is a SeenStore backed by an append-only file of IDs, one per line,
+// so duplicates are detected across runs
+type FileSeenStore struct {
+	mem  *MemorySeenStore
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFileSeenStore loads the IDs recorded in path and appends new ones to it
+func OpenFileSeenStore(path string) (*FileSeenStore, error) {
+	mem := NewMemorySeenStore()
+
+	existing, err := os.Open(path)
+	switch {
+	case err == nil:
+		scanner := bufio.NewScanner(existing)
+		for scanner.Scan() {
+			if id := strings.TrimSpace(scanner.Text()); id != "" {
+				mem.ids[id] = struct{}{}
+			}
+		}
+		existing.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, seenStoreError(path, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, seenStoreError(path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, seenStoreError(path, err)
+	}
+
+	getLogger().Debug("seen store opened",
+		"path", path,
+		"ids", len(mem.ids))
+
+	return &FileSeenStore{mem: mem, file: file}, nil
+}
+
+func seenStoreError(path string, err error) error {
+	return &ScrapeError{
+		Type:    ErrTypeConfig,
+		Message: fmt.Sprintf("failed to open seen store: %s", path),
+		Cause:   err,
+	}
+}
+
+// Has reports whether id has been added in this or an earlier run
+func (s *FileSeenStore) Has(id string) (bool, error) {
+	return s.mem.Has(id)
+}
+
+// Add records ids as seen and appends the new ones to the file
+func (s *FileSeenStore) Add(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lines strings.Builder
+	for _, id := range ids {
+		if seen, _ := s.mem.Has(id); !seen {
+			lines.WriteString(id + "\n")
+		}
+	}
+	if lines.Len() == 0 {
+		return nil
+	}
+	if _, err := s.file.WriteString(lines.String()); err != nil {
+		return err
+	}
+	return s.mem.Add(ids...)
+}
+
+// Close closes the underlying file
+func (s *FileSeenStore) Close() error {
+	return s.file.Close()
+}
+
+// itemID computes a stable ID from the key fields, or from every field if none are set
+func itemID(item map[string]any, dedup *DedupConfig) string {
+	keys := dedup.KeyFields
+	if len(keys) == 0 {
+		for name := range item {
+			if name != dedup.idField() {
+				keys = append(keys, name)
+			}
+		}
+		sort.Strings(keys)
+	}
+
+	hash := sha256.New()
+	for _, name := range keys {
+		value, _ := json.Marshal(item[name])
+		fmt.Fprintf(hash, "%s=%s\n", name, value)
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// idField returns the field that receives the item ID
+func (d *DedupConfig) idField() string {
+	if d.IDField != "" {
+		return d.IDField
+	}
+	return DefaultIDField
+}
+
+// dedupState shares a seen store and duplicate count across the pages of one scrape
+type dedupState struct {
+	store      SeenStore
+	mu         sync.Mutex
+	pending    map[string]struct{} // IDs kept on pages whose items are not delivered yet
+	duplicates atomic.Int64
+}
+
+// withDedup attaches a per-scrape dedup state to ctx when config enables dedup.
+// An outer scrape's state is reused, so nested scrapes share one set of IDs.
+func withDedup(ctx context.Context, config *Config) (context.Context, *dedupState) {
+	if config.Dedup == nil {
+		return ctx, nil
+	}
+	if state, ok := ctx.Value(contextKey("dedup")).(*dedupState); ok {
+		return ctx, state
+	}
+	state := &dedupState{store: config.Dedup.Store, pending: make(map[string]struct{})}
+	if state.store == nil {
+		state.store = NewMemorySeenStore()
+	}
+	return context.WithValue(ctx, contextKey("dedup"), state), state
+}
+
+// count returns the number of duplicates dropped (nil-safe)
+func (s *dedupState) count() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.duplicates.Load())
+}
+
+// claim reports whether id is new, reserving it until its page is committed or released
+func (s *dedupState) claim(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pending[id]; ok {
+		return false, nil
+	}
+	seen, err := s.store.Has(id)
+	if err != nil || seen {
+		return false, err
+	}
+	s.pending[id] = struct{}{}
+	return true, nil
+}
+
+// dedupPage holds the IDs one page kept until its items are delivered
+type dedupPage struct {
+	state *dedupState
+	ids   []string
+}
+
+// withDedupPage attaches a page to ctx that collects the IDs kept by dedupItems.
+// Returns a nil page when dedup is off or an outer caller already owns the page.
+func withDedupPage(ctx context.Context, config *Config) (context.Context, *dedupPage) {
+	if config.Dedup == nil {
+		return ctx, nil
+	}
+	if _, ok := ctx.Value(contextKey("dedupPage")).(*dedupPage); ok {
+		return ctx, nil
+	}
+	ctx, state := withDedup(ctx, config)
+	page := &dedupPage{state: state}
+	return context.WithValue(ctx, contextKey("dedupPage"), page), page
+}
+
+// commit adds the page's IDs to the store once its items are delivered (nil-safe)
+func (p *dedupPage) commit() error {
+	if p == nil || len(p.ids) == 0 {
+		return nil
+	}
+	err := p.state.store.Add(p.ids...)
+	p.release()
+	if err != nil {
+		return seenStoreFailed(err)
+	}
+	return nil
+}
+
+// release drops the page's reserved IDs without adding them to the store (nil-safe).
+// It does nothing after commit.
+func (p *dedupPage) release() {
+	if p == nil {
+		return
+	}
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	for _, id := range p.ids {
+		delete(p.state.pending, id)
+	}
+	p.ids = nil
+}
+
+func seenStoreFailed(err error) error {
+	return &ScrapeError{
+		Type:    ErrTypeValidation,
+		Message: "seen store failed",
+		Cause:   err,
+	}
+}
+
+// dedupItems stamps each item with its ID and drops items whose ID was already seen.
+// Kept IDs are added to the store when the page attached to ctx is committed,
+// or right away if there is none.
+func dedupItems(ctx context.Context, items []map[string]any, config *Config) ([]map[string]any, error) {
+	page, owned := ctx.Value(contextKey("dedupPage")).(*dedupPage)
+	if !owned {
+		// Items are returned as extracted: commit them immediately
+		_, page = withDedupPage(ctx, config)
+		defer page.release()
+	}
+	state := page.state
+
+	kept := items[:0]
+	for _, item := range items {
+		id := itemID(item, config.Dedup)
+		item[config.Dedup.idField()] = id
+
+		isNew, err := state.claim(id)
+		if err != nil {
+			return nil, seenStoreFailed(err)
+		}
+		if !isNew {
+			state.duplicates.Add(1)
+			continue
+		}
+		page.ids = append(page.ids, id)
+		kept = append(kept, item)
+	}
+
+	if dropped := len(items) - len(kept); dropped > 0 {
+		getLogger().Debug("duplicate items dropped",
+			"dropped", dropped,
+			"kept", len(kept))
+	}
+
+	if !owned {
+		if err := page.commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	return kept, nil
+}
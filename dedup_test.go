@@ -0,0 +1,221 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type dedupTestProduct struct {
+	ID    string `json:"_id"`
+	SKU   string `json:"sku"`
+	Name  string `json:"name"`
+	Price string `json:"price"`
+}
+
+// TestItemID tests that IDs depend only on the key fields
+func TestItemID(t *testing.T) {
+	byKey := &DedupConfig{KeyFields: []string{"sku"}}
+	a := itemID(map[string]any{"sku": "A1", "price": "1"}, byKey)
+	b := itemID(map[string]any{"sku": "A1", "price": "2"}, byKey)
+	if a != b {
+		t.Error("Expected items with the same key to share an ID")
+	}
+	if a == itemID(map[string]any{"sku": "B2", "price": "1"}, byKey) {
+		t.Error("Expected different keys to give different IDs")
+	}
+	if len(a) != 32 {
+		t.Errorf("Expected 32 hex character ID, got %q", a)
+	}
+
+	allFields := &DedupConfig{}
+	c := itemID(map[string]any{"sku": "A1", "price": "1"}, allFields)
+	d := itemID(map[string]any{"price": "1", "sku": "A1", "_id": "stale"}, allFields)
+	if c != d {
+		t.Error("Expected all-field IDs to ignore map order and the ID field")
+	}
+	if c == itemID(map[string]any{"sku": "A1", "price": "2"}, allFields) {
+		t.Error("Expected all-field IDs to change with any field")
+	}
+}
+
+// Test listing pages for dedup: B2 shifts onto page 2 while paginating
+const testHTMLDedupPage1 = `<html><body>
+  <div class="p"><span class="sku">A1</span><h2>Alpha</h2></div>
+  <div class="p"><span class="sku">B2</span><h2>Beta</h2></div>
+  <a class="next" href="?page=2">Next</a>
+</body></html>`
+
+const testHTMLDedupPage2 = `<html><body>
+  <div class="p"><span class="sku">B2</span><h2>Beta</h2></div>
+  <div class="p"><span class="sku">C3</span><h2>Gamma</h2></div>
+</body></html>`
+
+// TestScrapeURLWithPages_Dedup tests dedup across pages, ID stamping and statistics
+func TestScrapeURLWithPages_Dedup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, testHTMLDedupPage2)
+			return
+		}
+		fmt.Fprint(w, testHTMLDedupPage1)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: "//div[@class='p']",
+		Fields: map[string]FieldConfig{
+			"sku":  {XPath: ".//span[@class='sku']/text()"},
+			"name": {XPath: ".//h2/text()"},
+		},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: "//a[@class='next']/@href",
+		},
+		Dedup: &DedupConfig{KeyFields: []string{"sku"}},
+	}
+	results, err := ScrapeURLWithPages[dedupTestProduct](context.Background(), server.URL, config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+
+	items := results.Items()
+	if len(items) != 3 || results.TotalItems != 3 {
+		t.Fatalf("Expected 3 unique items, got %d (%+v)", len(items), items)
+	}
+	if results.Duplicates != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", results.Duplicates)
+	}
+	for _, item := range items {
+		if item.ID != itemID(map[string]any{"sku": item.SKU}, config.Dedup) {
+			t.Errorf("Expected stable ID for %s, got %q", item.SKU, item.ID)
+		}
+	}
+}
+
+// TestScrapeURL_DedupAcrossRuns tests that a file seen-store drops items seen in earlier runs
+func TestScrapeURL_DedupAcrossRuns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, testHTMLDedupPage2)
+			return
+		}
+		fmt.Fprint(w, testHTMLDedupPage1)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "seen.txt")
+	config := &Config{
+		Container: "//div[@class='p']",
+		Fields: map[string]FieldConfig{
+			"sku":  {XPath: ".//span[@class='sku']/text()"},
+			"name": {XPath: ".//h2/text()"},
+		},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: "//a[@class='next']/@href",
+		},
+	}
+
+	run := func() int {
+		store, err := OpenFileSeenStore(path)
+		if err != nil {
+			t.Fatalf("OpenFileSeenStore failed: %v", err)
+		}
+		defer store.Close()
+
+		config.Dedup = &DedupConfig{KeyFields: []string{"sku"}, Store: store}
+		items, err := ScrapeURLUntyped(context.Background(), server.URL, config)
+		if err != nil {
+			t.Fatalf("ScrapeURLUntyped failed: %v", err)
+		}
+		return len(items)
+	}
+
+	if n := run(); n != 3 {
+		t.Errorf("Expected 3 new items on first run, got %d", n)
+	}
+	if n := run(); n != 0 {
+		t.Errorf("Expected no new items on second run, got %d", n)
+	}
+}
+
+// failingSink rejects every write
+type failingSink struct{}
+
+func (failingSink) Write(item any) error { return fmt.Errorf("disk full") }
+func (failingSink) Close() error         { return nil }
+
+// TestScrapeURL_DedupFailedPage tests that items of a page that fails after
+// extraction are not marked as seen, so the next run still returns them
+func TestScrapeURL_DedupFailedPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, testHTMLDedupPage2)
+			return
+		}
+		fmt.Fprint(w, testHTMLDedupPage1)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "seen.txt")
+	config := &Config{
+		Container: "//div[@class='p']",
+		Fields: map[string]FieldConfig{
+			"sku":  {XPath: ".//span[@class='sku']/text()"},
+			"name": {XPath: ".//h2/text()"},
+		},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: "//a[@class='next']/@href",
+		},
+	}
+
+	openStore := func() *FileSeenStore {
+		store, err := OpenFileSeenStore(path)
+		if err != nil {
+			t.Fatalf("OpenFileSeenStore failed: %v", err)
+		}
+		config.Dedup = &DedupConfig{KeyFields: []string{"sku"}, Store: store}
+		return store
+	}
+
+	store := openStore()
+	if _, err := ScrapeURLToSink[dedupTestProduct](context.Background(), server.URL, config, failingSink{}); err == nil {
+		t.Fatal("Expected sink error")
+	}
+	store.Close()
+
+	store = openStore()
+	defer store.Close()
+	items, err := ScrapeURLUntyped(context.Background(), server.URL, config)
+	if err != nil {
+		t.Fatalf("ScrapeURLUntyped failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("Expected 3 items after the failed run, got %d", len(items))
+	}
+}
+
+// TestConfigValidate_DedupKeyFields tests that unknown key fields are rejected
+func TestConfigValidate_DedupKeyFields(t *testing.T) {
+	config := &Config{
+		Container: "//div[@class='p']",
+		Fields: map[string]FieldConfig{
+			"sku":  {XPath: ".//span[@class='sku']/text()"},
+			"name": {XPath: ".//h2/text()"},
+		},
+		Dedup: &DedupConfig{KeyFields: []string{"missing"}},
+	}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for unknown dedup key field")
+	}
+}
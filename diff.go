@@ -0,0 +1,292 @@
+package gtmlp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffOptions configures how two result sets are compared
+type DiffOptions struct {
+	KeyFields      []string           // Fields that identify an item (default: "_id", see Config.Dedup)
+	IgnoreFields   []string           // Fields never reported as modified
+	Tolerance      float64            // Numeric differences up to this are ignored; if zero, values are compared as text
+	FieldTolerance map[string]float64 // Per-field numeric tolerance, overriding Tolerance (fields listed here are always compared numerically)
+}
+
+// FieldChange is one modified field
+type FieldChange struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// ItemChange is an item present in both result sets with modified fields
+type ItemChange struct {
+	Key     string
+	Old     map[string]any
+	New     map[string]any
+	Changes []FieldChange // Sorted by field name
+}
+
+// Diff is the difference between a previous and a current result set
+type Diff struct {
+	Added     []map[string]any // Items only in the current set, in current order
+	Removed   []map[string]any // Items only in the previous set, in previous order
+	Modified  []ItemChange     // Items whose fields changed, in current order
+	Unchanged int              // Items present in both without changes
+}
+
+// HasChanges reports whether anything was added, removed or modified
+func (d *Diff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Modified) > 0
+}
+
+// DiffItems compares current items against previous ones by key. Items may be
+// structs or maps; nested values are compared field by field using dotted names.
+func DiffItems[T, U any](previous []T, current []U, options *DiffOptions) (*Diff, error) {
+	if options == nil {
+		options = &DiffOptions{}
+	}
+	keyFields := options.KeyFields
+	if len(keyFields) == 0 {
+		keyFields = []string{DefaultIDField}
+	}
+
+	prevRecords, prevKeys, err := diffRecords(previous, keyFields)
+	if err != nil {
+		return nil, err
+	}
+	currRecords, currKeys, err := diffRecords(current, keyFields)
+	if err != nil {
+		return nil, err
+	}
+
+	prevByKey := make(map[string]map[string]any, len(prevRecords))
+	for i, record := range prevRecords {
+		prevByKey[prevKeys[i]] = record
+	}
+
+	ignored := make(map[string]bool, len(options.IgnoreFields))
+	for _, field := range options.IgnoreFields {
+		ignored[field] = true
+	}
+
+	diff := &Diff{}
+	matched := make(map[string]bool, len(currRecords))
+	for i, record := range currRecords {
+		key := currKeys[i]
+		old, ok := prevByKey[key]
+		if !ok {
+			diff.Added = append(diff.Added, record)
+			continue
+		}
+		matched[key] = true
+
+		changes := diffFields(old, record, ignored, options)
+		if len(changes) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Modified = append(diff.Modified, ItemChange{Key: key, Old: old, New: record, Changes: changes})
+	}
+
+	for i, record := range prevRecords {
+		if !matched[prevKeys[i]] {
+			diff.Removed = append(diff.Removed, record)
+		}
+	}
+
+	getLogger().Info("diff complete",
+		"added", len(diff.Added),
+		"removed", len(diff.Removed),
+		"modified", len(diff.Modified),
+		"unchanged", diff.Unchanged)
+
+	return diff, nil
+}
+
+// diffRecords converts items into flattened records and computes their keys
+func diffRecords[T any](items []T, keyFields []string) ([]map[string]any, []string, error) {
+	records := make([]map[string]any, len(items))
+	keys := make([]string, len(items))
+	for i, item := range items {
+		record, err := sinkRecord(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		records[i] = flattenRecord(record)
+
+		parts := make([]string, len(keyFields))
+		for j, field := range keyFields {
+			value, ok := records[i][field]
+			if !ok {
+				return nil, nil, &ScrapeError{
+					Type:    ErrTypeValidation,
+					Message: fmt.Sprintf("diff key field '%s' missing from item %d", field, i),
+				}
+			}
+			parts[j] = formatCell(value)
+		}
+		keys[i] = strings.Join(parts, "\x1f")
+	}
+	return records, keys, nil
+}
+
+// diffFields returns the changed fields between two flattened records
+func diffFields(old, current map[string]any, ignored map[string]bool, options *DiffOptions) []FieldChange {
+	names := make(map[string]bool, len(current))
+	for name := range old {
+		names[name] = true
+	}
+	for name := range current {
+		names[name] = true
+	}
+
+	var changes []FieldChange
+	for name := range names {
+		if ignored[name] {
+			continue
+		}
+		tolerance, numeric := options.Tolerance, options.Tolerance > 0
+		if t, ok := options.FieldTolerance[name]; ok {
+			tolerance, numeric = t, true
+		}
+		if !valuesEqual(old[name], current[name], tolerance, numeric) {
+			changes = append(changes, FieldChange{Field: name, Old: old[name], New: current[name]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// valuesEqual compares two values by their text. Fields with a tolerance are compared
+// numerically within it when both are numbers or numeric strings, so "0123" and "123"
+// differ unless a tolerance is configured.
+func valuesEqual(a, b any, tolerance float64, numeric bool) bool {
+	if formatCell(a) == formatCell(b) {
+		return true
+	}
+	if !numeric {
+		return false
+	}
+	if x, ok := numericValue(a); ok {
+		if y, ok := numericValue(b); ok {
+			return math.Abs(x-y) <= tolerance
+		}
+	}
+	return false
+}
+
+// numericValue converts numbers and numeric strings to float64
+func numericValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// ReadSnapshot reads items written by JSONLSink (one object per line) or a JSON array
+func ReadSnapshot(r io.Reader) ([]map[string]any, error) {
+	br := bufio.NewReader(r)
+	decoder := json.NewDecoder(br)
+	decoder.UseNumber()
+
+	// A JSON array holds the whole snapshot
+	if first, err := peekNonSpace(br); err == nil && first == '[' {
+		var items []map[string]any
+		if err := decoder.Decode(&items); err != nil {
+			return nil, snapshotError("failed to read snapshot", err)
+		}
+		return items, nil
+	}
+
+	var items []map[string]any
+	for {
+		var item map[string]any
+		err := decoder.Decode(&item)
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, snapshotError("failed to read snapshot", err)
+		}
+		items = append(items, item)
+	}
+}
+
+// LoadSnapshot reads a snapshot file (see ReadSnapshot). A missing file is an empty snapshot.
+func LoadSnapshot(path string) ([]map[string]any, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, snapshotError("failed to read snapshot", err)
+	}
+	defer f.Close()
+
+	return ReadSnapshot(f)
+}
+
+// SaveSnapshot writes items to path as JSON Lines, replacing the previous snapshot
+func SaveSnapshot[T any](path string, items []T) error {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+	if err := WriteAll(sink, items); err != nil {
+		return err
+	}
+	if err := sink.Close(); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return snapshotError("failed to write snapshot", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return snapshotError("failed to write snapshot", err)
+	}
+	return nil
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming it
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for i := 1; ; i++ {
+		peeked, err := br.Peek(i)
+		if err != nil {
+			return 0, err
+		}
+		if c := peeked[i-1]; c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c, nil
+		}
+	}
+}
+
+func snapshotError(message string, err error) error {
+	return &ScrapeError{
+		Type:    ErrTypeParsing,
+		Message: message,
+		Cause:   err,
+	}
+}
@@ -0,0 +1,148 @@
+package gtmlp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type diffTestProduct struct {
+	SKU   string  `json:"sku"`
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+	Seen  string  `json:"seen"`
+}
+
+// TestDiffItems tests added, removed, modified and unchanged detection
+func TestDiffItems(t *testing.T) {
+	previous := []diffTestProduct{
+		{SKU: "A", Name: "Alpha", Price: 10.00, Seen: "mon"},
+		{SKU: "B", Name: "Beta", Price: 20.00, Seen: "mon"},
+		{SKU: "C", Name: "Gamma", Price: 30.00, Seen: "mon"},
+	}
+	current := []diffTestProduct{
+		{SKU: "A", Name: "Alpha", Price: 10.004, Seen: "tue"}, // within tolerance, ignored field
+		{SKU: "B", Name: "Beta v2", Price: 18.50, Seen: "tue"},
+		{SKU: "D", Name: "Delta", Price: 40.00, Seen: "tue"},
+	}
+
+	diff, err := DiffItems(previous, current, &DiffOptions{
+		KeyFields:    []string{"sku"},
+		IgnoreFields: []string{"seen"},
+		Tolerance:    0.01,
+	})
+	if err != nil {
+		t.Fatalf("DiffItems failed: %v", err)
+	}
+
+	if !diff.HasChanges() {
+		t.Error("Expected changes")
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Expected 1 unchanged item, got %d", diff.Unchanged)
+	}
+	if len(diff.Added) != 1 || diff.Added[0]["sku"] != "D" {
+		t.Errorf("Expected D added, got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0]["sku"] != "C" {
+		t.Errorf("Expected C removed, got %v", diff.Removed)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0].Key != "B" {
+		t.Fatalf("Expected B modified, got %v", diff.Modified)
+	}
+
+	var fields []string
+	for _, change := range diff.Modified[0].Changes {
+		fields = append(fields, change.Field)
+	}
+	if !reflect.DeepEqual(fields, []string{"name", "price"}) {
+		t.Errorf("Expected name and price changes, got %v", fields)
+	}
+	if change := diff.Modified[0].Changes[0]; change.Old != "Beta" || change.New != "Beta v2" {
+		t.Errorf("Unexpected name change: %+v", change)
+	}
+}
+
+// TestDiffItems_FieldTolerance tests per-field tolerance and numeric strings
+func TestDiffItems_FieldTolerance(t *testing.T) {
+	previous := []map[string]any{{"_id": "x", "price": "19.99", "rating": 4.5}}
+	current := []map[string]any{{"_id": "x", "price": "20.49", "rating": 4.6}}
+
+	diff, err := DiffItems(previous, current, &DiffOptions{
+		FieldTolerance: map[string]float64{"price": 1},
+	})
+	if err != nil {
+		t.Fatalf("DiffItems failed: %v", err)
+	}
+	if len(diff.Modified) != 1 || len(diff.Modified[0].Changes) != 1 || diff.Modified[0].Changes[0].Field != "rating" {
+		t.Errorf("Expected only rating to change, got %+v", diff.Modified)
+	}
+}
+
+// TestDiffItems_ExactText tests that values without a tolerance are compared as text
+func TestDiffItems_ExactText(t *testing.T) {
+	previous := []map[string]any{{"_id": "x", "code": "0123", "ref": "9007199254740993", "count": json.Number("3")}}
+	current := []map[string]any{{"_id": "x", "code": "123", "ref": "9007199254740992", "count": 3}}
+
+	diff, err := DiffItems(previous, current, nil)
+	if err != nil {
+		t.Fatalf("DiffItems failed: %v", err)
+	}
+	var fields []string
+	if len(diff.Modified) == 1 {
+		for _, change := range diff.Modified[0].Changes {
+			fields = append(fields, change.Field)
+		}
+	}
+	if strings.Join(fields, ",") != "code,ref" {
+		t.Errorf("Expected code and ref to change, got %v", fields)
+	}
+}
+
+// TestDiffItems_MissingKey tests that items without the key field are rejected
+func TestDiffItems_MissingKey(t *testing.T) {
+	_, err := DiffItems([]map[string]any{{"name": "a"}}, []map[string]any{}, nil)
+	if err == nil {
+		t.Error("Expected error for items without the default _id key")
+	}
+}
+
+// TestSnapshot_RoundTrip tests saving and loading snapshots
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.jsonl")
+
+	missing, err := LoadSnapshot(path)
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("Expected empty snapshot for missing file, got %v, %v", missing, err)
+	}
+
+	items := []diffTestProduct{{SKU: "A", Price: 1.5}, {SKU: "B", Price: 2}}
+	if err := SaveSnapshot(path, items); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	diff, err := DiffItems(loaded, items, &DiffOptions{KeyFields: []string{"sku"}})
+	if err != nil {
+		t.Fatalf("DiffItems failed: %v", err)
+	}
+	if diff.HasChanges() || diff.Unchanged != 2 {
+		t.Errorf("Expected round-tripped snapshot to be unchanged, got %+v", diff)
+	}
+}
+
+// TestReadSnapshot_JSONArray tests reading a JSON array snapshot
+func TestReadSnapshot_JSONArray(t *testing.T) {
+	items, err := ReadSnapshot(strings.NewReader("  \n[{\"sku\":\"A\"},{\"sku\":\"B\"}]"))
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if len(items) != 2 || items[1]["sku"] != "B" {
+		t.Errorf("Unexpected items: %v", items)
+	}
+}
@@ -15,6 +15,8 @@ const (
 	ErrTypeConfig     ErrorType = "config"
 	ErrTypeValidation ErrorType = "validation"
 	ErrTypePipe       ErrorType = "pipe"
+	ErrTypeRobots     ErrorType = "robots"
+	ErrTypeFieldType  ErrorType = "type"
 )
 
 // ScrapeError is a typed error with context
@@ -64,3 +66,18 @@ func (e *PipeError) Error() string {
 func (e *PipeError) Unwrap() error {
 	return e.Cause
 }
+
+// TypeError represents a field value that could not be converted to the field's type
+type TypeError struct {
+	Type  string
+	Input string
+	Cause error
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("cannot convert %q to %s: %v", e.Input, e.Type, e.Cause)
+}
+
+func (e *TypeError) Unwrap() error {
+	return e.Cause
+}
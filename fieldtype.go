@@ -0,0 +1,372 @@
+package gtmlp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field types (see FieldConfig.Type)
+const (
+	TypeString   = "string"   // Text
+	TypeInt      = "int"      // Whole number (int)
+	TypeFloat    = "float"    // Floating point number (float64)
+	TypeBool     = "bool"     // true/false, yes/no, y/n, on/off, 1/0
+	TypeTime     = "time"     // Date and time (time.Time), parsed with FieldConfig.Layout or common formats
+	TypeDuration = "duration" // Duration (time.Duration): "1h30m", "01:30:00", "PT1H30M", "1 hour 30 minutes" or seconds
+	TypeURL      = "url"      // URL, resolved against the page URL
+	TypeDecimal  = "decimal"  // Exact decimal number (json.Number)
+)
+
+// fieldTypes lists the valid FieldConfig.Type values
+var fieldTypes = []string{TypeString, TypeInt, TypeFloat, TypeBool, TypeTime, TypeDuration, TypeURL, TypeDecimal}
+
+// timeLayouts are tried in order for time fields without a Layout
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"2006/01/02",
+}
+
+var (
+	// numberToken matches a number with optional digit group separators.
+	// A sign only counts at the start or after whitespace or a currency symbol,
+	// so the hyphen in "SKU-123" is not a minus.
+	numberToken = regexp.MustCompile(`(?:(?:^|[\s\p{Sc}])([-+\x{2212}]))?(\d+(?:[.,'\x{00a0}\x{202f} ]\d+)*)`)
+	// jsonNumber matches a JSON number literal
+	jsonNumber = regexp.MustCompile(`^-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][-+]?\d+)?$`)
+	// clockDuration matches "1:02:03" and "02:03"
+	clockDuration = regexp.MustCompile(`^(\d+):([0-5]?\d)(?::([0-5]?\d(?:\.\d+)?))?$`)
+	// isoDuration matches ISO 8601 durations without years and months, e.g. "PT1H30M" or "P1DT2H"
+	isoDuration = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)W)?(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+	// wordDuration matches one "<number> <unit>" part of "1 hour 30 minutes"
+	wordDuration = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(weeks?|w|days?|d|hours?|hrs?|hr|h|minutes?|mins?|m|seconds?|secs?|s)\b`)
+)
+
+// durationUnits maps the first letter of a word duration unit to its length
+var durationUnits = map[byte]time.Duration{
+	'w': 7 * 24 * time.Hour,
+	'd': 24 * time.Hour,
+	'h': time.Hour,
+	'm': time.Minute,
+	's': time.Second,
+}
+
+// validateFieldType checks a field's Type and Layout
+func validateFieldType(fieldConfig FieldConfig) error {
+	if fieldConfig.Type != "" && !isFieldType(fieldConfig.Type) {
+		return fmt.Errorf("unknown type %q (must be %s)", fieldConfig.Type, strings.Join(fieldTypes, ", "))
+	}
+	if fieldConfig.Layout != "" && fieldConfig.Type != TypeTime {
+		return fmt.Errorf("layout requires type %q", TypeTime)
+	}
+	return nil
+}
+
+// isFieldType reports whether typ is a known field type
+func isFieldType(typ string) bool {
+	for _, known := range fieldTypes {
+		if typ == known {
+			return true
+		}
+	}
+	return false
+}
+
+// coerceValue converts a field value to the field's Type. Untyped fields are returned unchanged.
+func coerceValue(ctx context.Context, fieldConfig FieldConfig, value any) (any, error) {
+	if fieldConfig.Type == "" {
+		return value, nil
+	}
+
+	// Values already of the target type, e.g. from a parsetime pipe or a typed default
+	switch v := value.(type) {
+	case time.Time:
+		if fieldConfig.Type == TypeTime {
+			return v, nil
+		}
+	case time.Duration:
+		if fieldConfig.Type == TypeDuration {
+			return v, nil
+		}
+	case bool:
+		if fieldConfig.Type == TypeBool {
+			return v, nil
+		}
+	case float64:
+		// Avoid exponent notation for large numbers from pipes or JSON defaults
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	input := strings.TrimSpace(fmt.Sprint(value))
+	var result any
+	var err error
+	switch fieldConfig.Type {
+	case TypeString:
+		return input, nil
+	case TypeInt:
+		result, err = parseIntValue(input)
+	case TypeFloat:
+		result, err = parseFloatValue(input)
+	case TypeDecimal:
+		var number string
+		number, err = parseNumber(input)
+		result = json.Number(number)
+	case TypeBool:
+		result, err = parseBoolValue(input)
+	case TypeTime:
+		result, err = parseTimeValue(input, fieldConfig.Layout)
+	case TypeDuration:
+		result, err = parseDurationValue(input)
+	case TypeURL:
+		result, err = parseURLValue(ctx, input)
+	default:
+		err = fmt.Errorf("unknown type %q", fieldConfig.Type)
+	}
+	if err != nil {
+		return nil, &TypeError{Type: fieldConfig.Type, Input: input, Cause: err}
+	}
+	return result, nil
+}
+
+// parseNumber extracts the first number in s as a JSON number literal. Currency
+// symbols, units and digit group separators are ignored. A single "," followed
+// by other than three digits, or the last of mixed "." and "," separators, is
+// the decimal separator: "1,299.00", "1.299,00", "1 299" and "17,50" all parse.
+func parseNumber(s string) (string, error) {
+	if jsonNumber.MatchString(s) {
+		return s, nil
+	}
+
+	m := numberToken.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("no number found")
+	}
+
+	negative := m[1] == "-" || m[1] == "−"
+	token := m[2]
+
+	// Split into digit groups and the separators between them
+	var groups []string
+	var separators []rune
+	start := 0
+	for i, r := range token {
+		if r < '0' || r > '9' {
+			groups = append(groups, token[start:i])
+			separators = append(separators, r)
+			start = i + len(string(r))
+		}
+	}
+	groups = append(groups, token[start:])
+
+	// Find the decimal separator, if any
+	fraction := ""
+	if n := len(separators); n > 0 {
+		last := separators[n-1]
+		mixed := false
+		repeated := false
+		for _, sep := range separators[:n-1] {
+			if sep == last {
+				repeated = true
+			} else {
+				mixed = true
+			}
+		}
+		isDecimal := false
+		switch {
+		case last != '.' && last != ',':
+		case repeated:
+		case mixed:
+			isDecimal = true
+		case last == '.':
+			isDecimal = true
+		default:
+			// A lone comma is a thousands separator only before exactly three digits
+			isDecimal = len(groups[n]) != 3
+		}
+		if isDecimal {
+			fraction = groups[n]
+			groups = groups[:n]
+		}
+	}
+
+	// Group separators must be followed by three digits
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", fmt.Errorf("invalid digit grouping in %q", token)
+		}
+	}
+
+	integer := strings.TrimLeft(strings.Join(groups, ""), "0")
+	if integer == "" {
+		integer = "0"
+	}
+	number := integer
+	if fraction != "" {
+		number += "." + fraction
+	}
+	if negative {
+		number = "-" + number
+	}
+	return number, nil
+}
+
+// parseIntValue parses a whole number; a fractional part other than zeros is an error
+func parseIntValue(s string) (int, error) {
+	number, err := parseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	if strings.ContainsAny(number, "eE") {
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, err
+		}
+		if f != math.Trunc(f) || f > math.MaxInt64 || f < math.MinInt64 {
+			return 0, fmt.Errorf("%s is not a whole number in range", number)
+		}
+		return int(f), nil
+	}
+
+	integer, fraction, _ := strings.Cut(number, ".")
+	if strings.Trim(fraction, "0") != "" {
+		return 0, fmt.Errorf("%s has a fractional part", number)
+	}
+	n, err := strconv.Atoi(integer)
+	if err != nil {
+		return 0, fmt.Errorf("%s is out of range", integer)
+	}
+	return n, nil
+}
+
+// parseFloatValue parses a number as float64
+func parseFloatValue(s string) (float64, error) {
+	number, err := parseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(number, 64)
+}
+
+// parseBoolValue parses common boolean words
+func parseBoolValue(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "yes", "y", "on", "1":
+		return true, nil
+	case "false", "no", "n", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("not a boolean")
+}
+
+// parseTimeValue parses a time with layout, or else with common layouts and Unix
+// timestamps. Times without a zone are UTC.
+func parseTimeValue(s, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, s)
+	}
+
+	for _, candidate := range timeLayouts {
+		if t, err := time.Parse(candidate, s); err == nil {
+			return t, nil
+		}
+	}
+
+	// Unix timestamps in seconds or milliseconds
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && len(s) >= 9 {
+		if len(s) <= 11 {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return time.UnixMilli(n).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time format")
+}
+
+// parseDurationValue parses Go, clock, ISO 8601 and worded durations, or a number of seconds
+func parseDurationValue(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	if m := clockDuration.FindStringSubmatch(s); m != nil {
+		parts := []string{m[1], m[2], m[3]}
+		if m[3] == "" {
+			// "mm:ss"
+			parts = []string{"0", m[1], m[2]}
+		}
+		return sumDuration(parts, []time.Duration{time.Hour, time.Minute, time.Second}), nil
+	}
+
+	if m := isoDuration.FindStringSubmatch(strings.ToUpper(s)); m != nil && s != "P" && !strings.HasSuffix(strings.ToUpper(s), "T") {
+		return sumDuration(m[1:], []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}), nil
+	}
+
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(seconds, 0) && !math.IsNaN(seconds) {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	// "1 hour 30 minutes", "1h 30m", "2 hrs, 5 mins"
+	if matches := wordDuration.FindAllStringSubmatch(s, -1); matches != nil {
+		rest := strings.ToLower(wordDuration.ReplaceAllString(s, ""))
+		rest = strings.NewReplacer(",", "", "and", "", " ", "").Replace(rest)
+		if rest == "" {
+			var d time.Duration
+			for _, m := range matches {
+				n, _ := strconv.ParseFloat(m[1], 64)
+				d += time.Duration(n * float64(durationUnits[strings.ToLower(m[2])[0]]))
+			}
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unrecognized duration format")
+}
+
+// sumDuration adds up numeric parts multiplied by their units; empty parts are skipped
+func sumDuration(parts []string, units []time.Duration) time.Duration {
+	var d time.Duration
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		n, _ := strconv.ParseFloat(part, 64)
+		d += time.Duration(n * float64(units[i]))
+	}
+	return d
+}
+
+// parseURLValue parses a URL and resolves it against the page URL from ctx, if known
+func parseURLValue(ctx context.Context, s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if baseURL, _ := ctx.Value(contextKey("baseURL")).(string); baseURL != "" {
+		if base, err := url.Parse(baseURL); err == nil {
+			u = base.ResolveReference(u)
+		}
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,216 @@
+package gtmlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseNumber tests number extraction with currencies and digit grouping
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"42", "42"},
+		{"-3.5", "-3.5"},
+		{"$1,299.00", "1299.00"},
+		{"€1.299,00", "1299.00"},
+		{"1 299 kr", "1299"},
+		{"17,50 €", "17.50"},
+		{"1,299", "1299"},
+		{"Price: 0.99 USD", "0.99"},
+		{"007", "7"},
+		{"1'000'000", "1000000"},
+		{"1e3", "1e3"},
+		{"SKU-123", "123"},
+		{"Pages 10-20", "10"},
+		{"Change: -4.5%", "-4.5"},
+		{"$-12.00", "-12.00"},
+		{"−7 °C", "-7"},
+	}
+	for _, tt := range tests {
+		got, err := parseNumber(tt.input)
+		if err != nil || got != tt.expected {
+			t.Errorf("parseNumber(%q) = %q, %v; expected %q", tt.input, got, err, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "free", "1,29,9"} {
+		if got, err := parseNumber(input); err == nil {
+			t.Errorf("parseNumber(%q) = %q; expected error", input, got)
+		}
+	}
+}
+
+// TestCoerceValue tests conversion to each field type
+func TestCoerceValue(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey("baseURL"), "https://example.com/shop/")
+	tests := []struct {
+		typ      string
+		layout   string
+		input    any
+		expected any
+	}{
+		{TypeString, "", 5, "5"},
+		{TypeString, "", "  Alpha \n", "Alpha"},
+		{TypeInt, "", "1,234 items", 1234},
+		{TypeInt, "", "12.00", 12},
+		{TypeInt, "", 7, 7},
+		{TypeFloat, "", "$9.99", 9.99},
+		{TypeDecimal, "", "€ 1.000,10", json.Number("1000.10")},
+		{TypeBool, "", "Yes", true},
+		{TypeBool, "", "off", false},
+		{TypeTime, "", "2024-03-01T10:00:00Z", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{TypeTime, "", "Mar 1, 2024", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{TypeTime, "", "1709287200", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{TypeTime, "02/01/2006", "01/03/2024", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{TypeDuration, "", "1h30m", 90 * time.Minute},
+		{TypeDuration, "", "1:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{TypeDuration, "", "4:05", 4*time.Minute + 5*time.Second},
+		{TypeDuration, "", "PT1H30M", 90 * time.Minute},
+		{TypeDuration, "", "P1DT2H", 26 * time.Hour},
+		{TypeDuration, "", "1 hour 30 minutes", 90 * time.Minute},
+		{TypeDuration, "", "2 hrs, 5 mins", 2*time.Hour + 5*time.Minute},
+		{TypeDuration, "", "90", 90 * time.Second},
+		{TypeURL, "", "../item?id=1", "https://example.com/item?id=1"},
+	}
+	for _, tt := range tests {
+		got, err := coerceValue(ctx, FieldConfig{Type: tt.typ, Layout: tt.layout}, tt.input)
+		if err != nil {
+			t.Errorf("%s %q: unexpected error %v", tt.typ, tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s %q: expected %v (%T), got %v (%T)", tt.typ, tt.input, tt.expected, tt.expected, got, got)
+		}
+	}
+}
+
+// TestCoerceValue_Errors tests that invalid values report the type and input
+func TestCoerceValue_Errors(t *testing.T) {
+	tests := []struct {
+		typ   string
+		input string
+	}{
+		{TypeInt, "12.5"},
+		{TypeInt, "n/a"},
+		{TypeFloat, "free"},
+		{TypeBool, "maybe"},
+		{TypeTime, "someday"},
+		{TypeDuration, "a while"},
+		{TypeURL, "http://[::1"},
+	}
+	for _, tt := range tests {
+		_, err := coerceValue(context.Background(), FieldConfig{Type: tt.typ}, tt.input)
+		var typeErr *TypeError
+		if !errors.As(err, &typeErr) {
+			t.Errorf("%s %q: expected TypeError, got %v", tt.typ, tt.input, err)
+			continue
+		}
+		if typeErr.Type != tt.typ || typeErr.Input != tt.input {
+			t.Errorf("Unexpected TypeError fields: %+v", typeErr)
+		}
+	}
+}
+
+// TestScrape_FieldTypes tests typed fields, defaults and type errors during scraping
+func TestScrape_FieldTypes(t *testing.T) {
+	html := `<html><body>
+<div class="item"><h2>Widget</h2><span class="price">$1,299.50</span><span class="qty">3 left</span>
+  <span class="stock"> Yes </span><time>2024-03-01</time><span class="len">1:30:00</span></div>
+<div class="item"><h2>Gadget</h2><span class="price">$5</span></div>
+</body></html>`
+
+	config := &Config{
+		Container: "//div[@class='item']",
+		Fields: map[string]FieldConfig{
+			"name":    {XPath: ".//h2"},
+			"price":   {XPath: ".//span[@class='price']", Type: TypeDecimal},
+			"qty":     {XPath: ".//span[@class='qty']", Type: TypeInt, Default: 0},
+			"stock":   {XPath: ".//span[@class='stock']", Pipes: []string{"trim"}, Type: TypeBool, Default: "no"},
+			"date":    {XPath: ".//time", Type: TypeTime},
+			"length":  {XPath: ".//span[@class='len']", Type: TypeDuration},
+			"comment": {XPath: ".//p", Default: "none"},
+		},
+		Timeout: 30 * time.Second,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	results, err := ScrapeUntyped(context.Background(), html, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	first, second := results[0], results[1]
+	if first["price"] != json.Number("1299.50") || first["qty"] != 3 || first["stock"] != true ||
+		first["date"] != time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC) || first["length"] != 90*time.Minute {
+		t.Errorf("Unexpected typed values: %v", first)
+	}
+	// Missing values use the default, or nil for typed fields without one
+	if second["qty"] != 0 || second["stock"] != false || second["date"] != nil || second["comment"] != "none" {
+		t.Errorf("Unexpected defaults: %v", second)
+	}
+
+	type product struct {
+		Price  float64       `json:"price"`
+		Date   time.Time     `json:"date"`
+		Length time.Duration `json:"length"`
+	}
+	typed, err := Scrape[product](context.Background(), html, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if typed[0].Price != 1299.5 || typed[0].Date.Day() != 1 || typed[0].Length != 90*time.Minute || !typed[1].Date.IsZero() {
+		t.Errorf("Unexpected struct results: %+v", typed)
+	}
+
+	config.Fields["name"] = FieldConfig{XPath: ".//h2", Type: TypeInt}
+	_, err = ScrapeUntyped(context.Background(), html, config)
+	var typeErr *TypeError
+	if !Is(err, ErrTypeFieldType) || !errors.As(err, &typeErr) || typeErr.Input != "Widget" {
+		t.Errorf("Expected type error for \"Widget\", got %v", err)
+	}
+}
+
+// TestValidate_FieldTypes tests validation of types, layouts and defaults
+func TestValidate_FieldTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  FieldConfig
+		errMsg string
+	}{
+		{"valid", FieldConfig{XPath: ".//p", Type: TypeTime, Layout: "2006-01-02", Default: "2000-01-01"}, ""},
+		{"untyped default", FieldConfig{XPath: ".//p", Default: "n/a"}, ""},
+		{"unknown type", FieldConfig{XPath: ".//p", Type: "integer"}, "invalid type for field 'value'"},
+		{"layout without time", FieldConfig{XPath: ".//p", Type: TypeInt, Layout: "2006"}, "invalid type for field 'value'"},
+		{"bad default", FieldConfig{XPath: ".//p", Type: TypeInt, Default: "none"}, "invalid default for field 'value'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Container: "//div",
+				Fields:    map[string]FieldConfig{"value": tt.field},
+				Timeout:   30 * time.Second,
+			}
+			err := config.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
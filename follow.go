@@ -0,0 +1,193 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DefaultFollowConcurrency is the default number of concurrent detail page fetches
+const DefaultFollowConcurrency = 4
+
+// followTarget identifies a follow field value on an extracted item
+type followTarget struct {
+	item  int
+	field string
+}
+
+// followResult holds the detail fields scraped from one detail URL
+type followResult struct {
+	fields map[string]any
+	err    error
+}
+
+// withFetchConfig stores the config whose HTTP and security settings are used for follow-up fetches
+func withFetchConfig(ctx context.Context, config *Config) context.Context {
+	return context.WithValue(ctx, contextKey("fetchConfig"), config)
+}
+
+// isFetchedPage reports whether ctx belongs to a page fetched over HTTP
+func isFetchedPage(ctx context.Context) bool {
+	_, ok := ctx.Value(contextKey("fetchConfig")).(*Config)
+	return ok
+}
+
+// fetchConfigFromContext returns the config for follow-up fetches, falling back to config
+func fetchConfigFromContext(ctx context.Context, config *Config) *Config {
+	if fetchConfig, ok := ctx.Value(contextKey("fetchConfig")).(*Config); ok && fetchConfig != nil {
+		return fetchConfig
+	}
+	return config
+}
+
+// hasFollowFields reports whether any field follows a detail page
+func hasFollowFields(config *Config) bool {
+	for _, fieldConfig := range config.Fields {
+		if fieldConfig.Follow != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// followDetails fetches the detail page linked from each item's follow fields
+// and merges the detail fields into the item. Identical URLs are fetched once.
+// A failed detail page fails the listing page, so pagination's OnError applies.
+func followDetails(ctx context.Context, items []map[string]any, config *Config) error {
+	fetchConfig := fetchConfigFromContext(ctx, config)
+	ctx = withFetchConfig(ctx, fetchConfig)
+
+	baseURL, _ := ctx.Value(contextKey("baseURL")).(string)
+
+	// Group targets by resolved detail URL
+	targets := make(map[string][]followTarget)
+	detailConfigs := make(map[string]*Config)
+	var urls []string
+	for i, item := range items {
+		for fieldName, fieldConfig := range config.Fields {
+			if fieldConfig.Follow == nil {
+				continue
+			}
+
+			rawURL := fmt.Sprintf("%v", item[fieldName])
+			if isEmpty(item[fieldName]) {
+				continue
+			}
+
+			detailURL := rawURL
+			if baseURL != "" {
+				resolved, err := resolveURL(baseURL, rawURL)
+				if err != nil {
+					return &ScrapeError{
+						Type:    ErrTypeParsing,
+						Message: fmt.Sprintf("invalid follow URL for field '%s'", fieldName),
+						URL:     rawURL,
+						Cause:   err,
+					}
+				}
+				detailURL = resolved
+			}
+
+			key := fieldName + " " + normalizeURL(detailURL)
+			if _, seen := targets[key]; !seen {
+				urls = append(urls, key)
+				detailConfigs[key] = followConfig(fieldConfig.Follow)
+			}
+			targets[key] = append(targets[key], followTarget{item: i, field: fieldName})
+			item[fieldName] = detailURL
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil
+	}
+
+	concurrency := fetchConfig.FollowConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultFollowConcurrency
+	}
+
+	getLogger().Info("following detail pages",
+		"urls", len(urls),
+		"items", len(items),
+		"concurrency", concurrency)
+
+	results := make(map[string]followResult, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, key := range urls {
+		first := targets[key][0]
+		detailURL := fmt.Sprintf("%v", items[first.item][first.field])
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func(key, detailURL string, detailConfig *Config) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fields, err := scrapeDetail(ctx, detailURL, detailConfig, fetchConfig)
+
+			mu.Lock()
+			results[key] = followResult{fields: fields, err: err}
+			mu.Unlock()
+		}(key, detailURL, detailConfigs[key])
+	}
+
+	wg.Wait()
+
+	// Merge in original order so errors are reported deterministically
+	for _, key := range urls {
+		result := results[key]
+		if result.err != nil {
+			return result.err
+		}
+		for _, target := range targets[key] {
+			for name, value := range result.fields {
+				items[target.item][name] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// scrapeDetail fetches a detail page with the fetch config's HTTP and security
+// settings and extracts the detail config's fields from its first container
+func scrapeDetail(ctx context.Context, detailURL string, detailConfig, fetchConfig *Config) (map[string]any, error) {
+	_, doc, err := fetchDocumentRequest(newGetRequest(detailURL).withContext(ctx), fetchConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _, err := extractPage(WithURL(ctx, detailURL), doc, detailConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		getLogger().Warn("detail page container not found",
+			"url", detailURL,
+			"container", detailConfig.Container)
+		return nil, nil
+	}
+
+	return items[0], nil
+}
+
+// followConfig returns the detail config with defaults applied:
+// an empty container selects the whole document
+func followConfig(detail *Config) *Config {
+	if detail.Container != "" {
+		return detail
+	}
+	withRoot := *detail
+	withRoot.Container = "/"
+	return &withRoot
+}
@@ -0,0 +1,212 @@
+package gtmlp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testHTMLListing = `<html><body>
+  <div class="product"><h2>Product 1</h2><a href="/detail/1">View</a></div>
+  <div class="product"><h2>Product 2</h2><a href="/detail/2">View</a></div>
+  <div class="product"><h2>Product 1 again</h2><a href="/detail/1#reviews">View</a></div>
+</body></html>`
+
+const testHTMLDetail1 = `<html><body>
+  <div class="detail"><p class="desc">First description</p><span class="sku">SKU-1</span></div>
+</body></html>`
+
+const testHTMLDetail2 = `<html><body>
+  <div class="detail"><p class="desc">Second description</p><span class="sku">SKU-2</span></div>
+</body></html>`
+
+// TestScrapeURL_FollowDetails tests merging detail page fields into listing items
+func TestScrapeURL_FollowDetails(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/products":
+			w.Write([]byte(testHTMLListing))
+		case "/detail/1":
+			w.Write([]byte(testHTMLDetail1))
+		case "/detail/2":
+			w.Write([]byte(testHTMLDetail2))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+			"link": {
+				XPath: `.//a/@href`,
+				Follow: &Config{
+					Container: `//div[@class="detail"]`,
+					Fields: map[string]FieldConfig{
+						"description": {XPath: `.//p[@class="desc"]/text()`},
+						"sku":         {XPath: `.//span[@class="sku"]/text()`},
+					},
+				},
+			},
+		},
+		Timeout:           30 * time.Second,
+		FollowConcurrency: 2,
+		AllowPrivateIPs:   true, // Allow localhost for testing
+	}
+
+	type DetailedProduct struct {
+		Name        string `json:"name"`
+		Link        string `json:"link"`
+		Description string `json:"description"`
+		SKU         string `json:"sku"`
+	}
+
+	products, err := ScrapeURL[DetailedProduct](context.Background(), server.URL+"/products", config)
+	if err != nil {
+		t.Fatalf("ScrapeURL failed: %v", err)
+	}
+
+	if len(products) != 3 {
+		t.Fatalf("Expected 3 products, got %d", len(products))
+	}
+
+	expected := []DetailedProduct{
+		{Name: "Product 1", Link: server.URL + "/detail/1", Description: "First description", SKU: "SKU-1"},
+		{Name: "Product 2", Link: server.URL + "/detail/2", Description: "Second description", SKU: "SKU-2"},
+		{Name: "Product 1 again", Link: server.URL + "/detail/1#reviews", Description: "First description", SKU: "SKU-1"},
+	}
+	for i, want := range expected {
+		if products[i] != want {
+			t.Errorf("products[%d] = %+v, expected %+v", i, products[i], want)
+		}
+	}
+
+	if hits["/detail/1"] != 1 {
+		t.Errorf("Expected duplicate detail URL to be fetched once, got %d", hits["/detail/1"])
+	}
+}
+
+// TestScrapeURL_FollowDetailsSecurity tests that detail fetches use the parent security settings
+func TestScrapeURL_FollowDetailsSecurity(t *testing.T) {
+	var detailHits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/products" {
+			atomic.AddInt32(&detailHits, 1)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><div class="product"><a href="http://localhost:1/secret">x</a></div></body></html>`))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"link": {
+				XPath: `.//a/@href`,
+				Follow: &Config{
+					Fields: map[string]FieldConfig{
+						"title": {XPath: `//title/text()`},
+					},
+				},
+			},
+		},
+		Timeout: 30 * time.Second,
+		URLValidator: func(rawURL string) error {
+			if strings.HasSuffix(rawURL, "/secret") {
+				return errors.New("blocked for test")
+			}
+			return nil
+		},
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	_, err := ScrapeURLUntyped(context.Background(), server.URL+"/products", config)
+	if err == nil {
+		t.Fatal("Expected blocked detail URL to fail, got nil")
+	}
+	if !Is(err, ErrTypeNetwork) {
+		t.Errorf("Expected network error, got %v", err)
+	}
+}
+
+// TestScrape_FollowStaysOffline tests that scraping an HTML string never fetches detail pages
+func TestScrape_FollowStaysOffline(t *testing.T) {
+	var detailHits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&detailHits, 1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(testHTMLDetail1))
+	}))
+	defer server.Close()
+
+	html := `<html><body><div class="product"><a href="` + server.URL + `/detail/1">View</a></div></body></html>`
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"link": {
+				XPath: `.//a/@href`,
+				Follow: &Config{
+					Container: `//div[@class="detail"]`,
+					Fields: map[string]FieldConfig{
+						"description": {XPath: `.//p[@class="desc"]/text()`},
+					},
+				},
+			},
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	results, err := ScrapeUntyped(context.Background(), html, config)
+	if err != nil {
+		t.Fatalf("ScrapeUntyped failed: %v", err)
+	}
+	if len(results) != 1 || results[0]["link"] != server.URL+"/detail/1" {
+		t.Errorf("Expected the link to be kept, got %v", results)
+	}
+	if _, ok := results[0]["description"]; ok {
+		t.Errorf("Expected no detail fields, got %v", results[0])
+	}
+	if hits := atomic.LoadInt32(&detailHits); hits != 0 {
+		t.Errorf("Expected no detail fetches, got %d", hits)
+	}
+}
+
+// TestValidate_FollowConfig tests detail config validation
+func TestValidate_FollowConfig(t *testing.T) {
+	config := &Config{
+		Container: "//div",
+		Fields: map[string]FieldConfig{
+			"link": {
+				XPath:  ".//a/@href",
+				Follow: &Config{Fields: map[string]FieldConfig{"bad": {XPath: "//["}}},
+			},
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	if err := config.Validate(); !Is(err, ErrTypeConfig) {
+		t.Errorf("Expected config error for invalid follow xpath, got %v", err)
+	}
+
+	config.Fields["link"].Follow.Fields["bad"] = FieldConfig{XPath: "//h1/text()"}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected valid follow config without container, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ go 1.25.1
 
 require (
 	github.com/antchfx/htmlquery v1.3.5
+	github.com/antchfx/xmlquery v1.5.0
 	github.com/antchfx/xpath v1.3.5
 	golang.org/x/net v0.49.0
 	gopkg.in/yaml.v3 v3.0.1
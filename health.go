@@ -174,6 +174,8 @@ func fetchForHealth(url string, config *Config) (*http.Response, error) {
 			"proxy", config.Proxy)
 	}
 
+	client.Transport = wrapTransport(client.Transport, config)
+
 	// Build request
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -1,17 +1,68 @@
 package gtmlp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	neturl "net/url"
 	"strings"
 	"time"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
 )
 
+// pageRequest describes how to fetch a page: a plain GET by default,
+// or a form submission with an encoded body
+type pageRequest struct {
+	Method      string // HTTP method (default: GET)
+	URL         string
+	Body        string // Encoded request body
+	ContentType string // Content-Type of Body
+
+	ctx      context.Context // Cancels the request and its retries (default: none)
+	visitKey string          // Overrides key() for form submissions (default: derived)
+}
+
+// newGetRequest creates a plain GET page request
+func newGetRequest(url string) *pageRequest {
+	return &pageRequest{Method: http.MethodGet, URL: url}
+}
+
+// withContext returns a copy of the request that is cancelled with ctx
+func (r *pageRequest) withContext(ctx context.Context) *pageRequest {
+	req := *r
+	req.ctx = ctx
+	return &req
+}
+
+// key identifies the request for visited tracking
+func (r *pageRequest) key() string {
+	if r.visitKey != "" {
+		return r.visitKey
+	}
+	normalized := normalizeURL(r.URL)
+	if r.Method == "" || r.Method == http.MethodGet {
+		return normalized
+	}
+	return r.Method + " " + normalized + "\n" + r.Body
+}
+
 // fetch fetches a URL and returns the HTTP response
 func fetch(url string, config *Config) (*http.Response, error) {
+	return fetchRequest(newGetRequest(url), config)
+}
+
+// fetchRequest performs a page request and returns the HTTP response
+func fetchRequest(pageReq *pageRequest, config *Config) (*http.Response, error) {
 	startTime := time.Now()
+	url := pageReq.URL
+	method := pageReq.Method
+	if method == "" {
+		method = http.MethodGet
+	}
 
 	// Validate URL
 	if url == "" {
@@ -61,8 +112,26 @@ func fetch(url string, config *Config) (*http.Response, error) {
 		}
 	}
 
+	// robots.txt compliance
+	crawlDelay, err := checkRobots(parsedURL, config)
+	if err != nil {
+		return nil, err
+	}
+	rateLimit := config.RateLimit
+	if crawlDelay > rateLimit {
+		rateLimit = crawlDelay
+	}
+
+	// Response cache
+	var cached *cacheTransport
+	if isCacheable(pageReq, config) {
+		cached = &cacheTransport{key: cacheKey(url, config), config: config}
+		cached.fresh, cached.stale = lookupCache(cached.key, url, config)
+	}
+
 	getLogger().Debug("http request starting",
 		"url", url,
+		"method", method,
 		"timeout", config.Timeout,
 		"max_retries", config.MaxRetries)
 
@@ -87,6 +156,18 @@ func fetch(url string, config *Config) (*http.Response, error) {
 		}
 	}
 
+	// Cache hits are served beneath the session and WARC layers so they are recorded too
+	if cached != nil {
+		cached.base = client.Transport
+		client.Transport = cached
+	}
+	client.Transport = wrapTransport(client.Transport, config)
+
+	ctx := pageReq.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// Perform request with retry logic
 	var lastErr error
 	maxAttempts := config.MaxRetries + 1
@@ -95,11 +176,31 @@ func fetch(url string, config *Config) (*http.Response, error) {
 		// Add exponential backoff delay between retries
 		if attempt > 0 {
 			backoffDuration := time.Duration(1<<uint(attempt-1)) * time.Second
-			time.Sleep(backoffDuration)
+			select {
+			case <-time.After(backoffDuration):
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeNetwork,
+				Message: "HTTP request cancelled",
+				URL:     url,
+				Cause:   err,
+			}
+		}
+
+		// Space out requests to the same host (replayed and cached responses need no spacing)
+		if !config.Session.replaying() && (cached == nil || cached.fresh == nil) {
+			hostLimiter.wait(parsedURL.Host, rateLimit)
 		}
 
 		// Build request
-		req, err := http.NewRequest("GET", url, nil)
+		var body io.Reader
+		if pageReq.Body != "" {
+			body = strings.NewReader(pageReq.Body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
 		if err != nil {
 			lastErr = &ScrapeError{
 				Type:    ErrTypeNetwork,
@@ -119,6 +220,10 @@ func fetch(url string, config *Config) (*http.Response, error) {
 		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 
+		if pageReq.ContentType != "" {
+			req.Header.Set("Content-Type", pageReq.ContentType)
+		}
+
 		// Set custom headers
 		for key, value := range config.Headers {
 			req.Header.Set(key, value)
@@ -127,6 +232,11 @@ func fetch(url string, config *Config) (*http.Response, error) {
 		// Execute request
 		resp, err := client.Do(req)
 		if err != nil {
+			// A replay miss will not succeed on retry
+			var scrapeErr *ScrapeError
+			if config.Session.replaying() && errors.As(err, &scrapeErr) {
+				return nil, scrapeErr
+			}
 			getLogger().Warn("http request failed",
 				"url", url,
 				"attempt", attempt+1,
@@ -164,6 +274,9 @@ func fetch(url string, config *Config) (*http.Response, error) {
 			"status", resp.StatusCode,
 			"duration_ms", duration.Milliseconds(),
 			"attempt", attempt+1)
+		if cached != nil && !cached.served {
+			return storeResponse(cached.key, url, resp, config)
+		}
 		return resp, nil
 	}
 
@@ -175,14 +288,36 @@ func fetch(url string, config *Config) (*http.Response, error) {
 	return nil, lastErr
 }
 
-// fetchHTML fetches a URL and returns the HTML content as a string
-func fetchHTML(url string, config *Config) (string, error) {
-	getLogger().Debug("fetching html",
-		"url", url)
+// wrapTransport layers session record/replay and WARC archiving over the base transport
+func wrapTransport(base http.RoundTripper, config *Config) http.RoundTripper {
+	if config.Session != nil {
+		base = config.Session.transport(base)
+	}
+	if config.WARC != nil {
+		base = config.WARC.transport(base)
+	}
+	return base
+}
+
+// fetchedPage holds a fetched response body together with its metadata
+type fetchedPage struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// fetchPage fetches a URL and returns the response body and headers
+func fetchPage(url string, config *Config) (*fetchedPage, error) {
+	return fetchPageRequest(newGetRequest(url), config)
+}
 
-	resp, err := fetch(url, config)
+// fetchPageRequest performs a page request and returns the response body and headers
+func fetchPageRequest(pageReq *pageRequest, config *Config) (*fetchedPage, error) {
+	url := pageReq.URL
+	resp, err := fetchRequest(pageReq, config)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -192,7 +327,7 @@ func fetchHTML(url string, config *Config) (string, error) {
 		getLogger().Error("failed to read response body",
 			"url", url,
 			"error", err.Error())
-		return "", &ScrapeError{
+		return nil, &ScrapeError{
 			Type:    ErrTypeNetwork,
 			Message: "failed to read response body",
 			URL:     url,
@@ -200,12 +335,63 @@ func fetchHTML(url string, config *Config) (string, error) {
 		}
 	}
 
-	// Convert to string and trim whitespace
-	html := strings.TrimSpace(string(body))
+	return &fetchedPage{
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		// Convert to string and trim whitespace
+		Body: strings.TrimSpace(string(body)),
+	}, nil
+}
+
+// fetchHTML fetches a URL and returns the HTML content as a string
+func fetchHTML(url string, config *Config) (string, error) {
+	getLogger().Debug("fetching html",
+		"url", url)
+
+	page, err := fetchPage(url, config)
+	if err != nil {
+		return "", err
+	}
 
 	getLogger().Debug("html fetched successfully",
 		"url", url,
-		"size_bytes", len(html))
+		"size_bytes", len(page.Body))
+
+	return page.Body, nil
+}
+
+// fetchDocument fetches a URL and parses the response body as HTML
+func fetchDocument(url string, config *Config) (*fetchedPage, *html.Node, error) {
+	return fetchDocumentRequest(newGetRequest(url), config)
+}
+
+// fetchDocumentRequest performs a page request and parses the response body as HTML
+func fetchDocumentRequest(pageReq *pageRequest, config *Config) (*fetchedPage, *html.Node, error) {
+	page, err := fetchPageRequest(pageReq, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := parseDocument(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return page, doc, nil
+}
+
+// parseDocument parses a fetched page body as HTML
+func parseDocument(page *fetchedPage) (*html.Node, error) {
+	doc, err := htmlquery.Parse(strings.NewReader(page.Body))
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: "failed to parse HTML",
+			URL:     page.URL,
+			Cause:   err,
+		}
+	}
 
-	return html, nil
+	return doc, nil
 }
@@ -0,0 +1,160 @@
+package gtmlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseJSON decodes JSON data keeping numbers as json.Number so large
+// integers and cursors keep their exact textual form
+func parseJSON(data string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// lookupJSONPath resolves a dot-separated path with optional array indices
+// against decoded JSON, e.g. "meta.next", "$.links[0].href" or "items[2]".
+// Returns false if any segment is missing.
+func lookupJSONPath(data any, path string) (any, bool) {
+	segments, err := splitJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+
+	current := data
+	for _, segment := range segments {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			current = v[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// selectJSONPath resolves a path like lookupJSONPath, where a "*" or "[*]" segment
+// matches every element of an array or every value of an object (in key order).
+// Returns all matches in order.
+func selectJSONPath(data any, path string) []any {
+	segments, err := splitJSONPath(path)
+	if err != nil {
+		return nil
+	}
+
+	current := []any{data}
+	for _, segment := range segments {
+		var next []any
+		for _, value := range current {
+			if segment == "*" {
+				switch v := value.(type) {
+				case map[string]any:
+					for _, key := range slices.Sorted(maps.Keys(v)) {
+						next = append(next, v[key])
+					}
+				case []any:
+					next = append(next, v...)
+				}
+				continue
+			}
+			if child, ok := lookupJSONPath(value, segment); ok {
+				next = append(next, child)
+			}
+		}
+		current = next
+	}
+
+	return current
+}
+
+// selectJSONElements returns the items a container path selects: the elements of
+// a single array match, or else every non-null match
+func selectJSONElements(data any, path string) []any {
+	matches := slices.DeleteFunc(selectJSONPath(data, path), func(value any) bool {
+		return value == nil
+	})
+	if len(matches) == 1 {
+		if elements, ok := matches[0].([]any); ok {
+			return elements
+		}
+	}
+	return matches
+}
+
+// splitJSONPath splits a path like "$.a.b[0]" into ["a", "b", "0"]
+func splitJSONPath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			continue
+		}
+
+		// Split "name[0][1]" into name and indices
+		name := part
+		if i := strings.Index(part, "["); i >= 0 {
+			name = part[:i]
+			rest := part[i:]
+			if name != "" {
+				segments = append(segments, name)
+			}
+			for rest != "" {
+				end := strings.Index(rest, "]")
+				if !strings.HasPrefix(rest, "[") || end < 0 {
+					return nil, fmt.Errorf("invalid json path segment: %s", part)
+				}
+				segments = append(segments, strings.Trim(rest[1:end], `'"`))
+				rest = rest[end+1:]
+			}
+			continue
+		}
+		segments = append(segments, name)
+	}
+
+	return segments, nil
+}
+
+// jsonValueString converts a decoded JSON scalar to its string form.
+// Objects and arrays are re-encoded as JSON.
+func jsonValueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
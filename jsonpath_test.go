@@ -0,0 +1,88 @@
+package gtmlp
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLookupJSONPath tests resolving paths against decoded JSON
+func TestLookupJSONPath(t *testing.T) {
+	data, err := parseJSON(`{"meta": {"next": "/api?page=2", "cursor": 12345678901234}, "links": [{"href": "a"}, {"href": "b"}], "done": false}`)
+	if err != nil {
+		t.Fatalf("parseJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+		found    bool
+	}{
+		{"meta.next", "/api?page=2", true},
+		{"$.meta.next", "/api?page=2", true},
+		{"meta.cursor", "12345678901234", true},
+		{"links[1].href", "b", true},
+		{"$.links[0]['href']", "a", true},
+		{"done", "false", true},
+		{"meta.missing", "", false},
+		{"links[5].href", "", false},
+		{"meta.next.deeper", "", false},
+	}
+
+	for _, tt := range tests {
+		value, ok := lookupJSONPath(data, tt.path)
+		if ok != tt.found {
+			t.Errorf("lookupJSONPath(%s) found = %v, expected %v", tt.path, ok, tt.found)
+			continue
+		}
+		if ok && jsonValueString(value) != tt.expected {
+			t.Errorf("lookupJSONPath(%s) = %s, expected %s", tt.path, jsonValueString(value), tt.expected)
+		}
+	}
+}
+
+// TestSplitJSONPath_Invalid tests malformed path syntax
+func TestSplitJSONPath_Invalid(t *testing.T) {
+	if _, err := splitJSONPath("items[0"); err == nil {
+		t.Error("Expected error for unterminated index, got nil")
+	}
+}
+
+// TestSelectJSONPath tests wildcard paths
+func TestSelectJSONPath(t *testing.T) {
+	data, err := parseJSON(`{"groups": [{"items": [{"id": 1}, {"id": 2}]}, {"items": [{"id": 3}]}], "byId": {"b": {"id": 5}, "a": {"id": 4}}, "empty": null}`)
+	if err != nil {
+		t.Fatalf("parseJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"groups[*].items[*].id", []string{"1", "2", "3"}},
+		{"$.groups.*.items[0].id", []string{"1", "3"}},
+		{"byId.*.id", []string{"4", "5"}},
+		{"groups[1].items[0].id", []string{"3"}},
+		{"missing[*]", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, value := range selectJSONPath(data, tt.path) {
+			got = append(got, jsonValueString(value))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("selectJSONPath(%s) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+
+	// A single array match is expanded into its elements; null matches are dropped
+	if elements := selectJSONElements(data, "groups"); len(elements) != 2 {
+		t.Errorf("Expected 2 elements, got %d", len(elements))
+	}
+	if elements := selectJSONElements(data, "groups[*].items[*]"); len(elements) != 3 {
+		t.Errorf("Expected 3 elements, got %d", len(elements))
+	}
+	if elements := selectJSONElements(data, "empty"); len(elements) != 0 {
+		t.Errorf("Expected no elements for null, got %d", len(elements))
+	}
+}
@@ -0,0 +1,117 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// isNestedField reports whether a field extracts nested objects (see FieldConfig.Fields)
+func isNestedField(fieldConfig FieldConfig) bool {
+	return len(fieldConfig.Fields) > 0
+}
+
+// extractNested extracts a nested field from the elements its selectors match, using
+// resolve to evaluate each selector and extract to read the fields of one element.
+// The value is the first element's object (nil if none match), or with List one object per element.
+func extractNested[T any](fieldConfig FieldConfig, resolve func(selector string) []T, extract func(element T) (map[string]any, error)) (any, error) {
+	elements := findContainerMatches(fieldConfig.XPath, fieldConfig.AltXPath, resolve)
+	if !fieldConfig.List {
+		if len(elements) == 0 {
+			return nil, nil
+		}
+		return extract(elements[0])
+	}
+
+	objects := make([]any, 0, len(elements))
+	for _, element := range elements {
+		object, err := extract(element)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// extractNestedHTML extracts a nested field relative to an HTML node
+func extractNestedHTML(ctx context.Context, node *html.Node, fieldConfig FieldConfig) (any, error) {
+	return extractNested(fieldConfig, func(selector string) []*html.Node {
+		expr, err := compileSelector(selector)
+		if err != nil {
+			return nil
+		}
+		return htmlquery.QuerySelectorAll(node, expr)
+	}, func(element *html.Node) (map[string]any, error) {
+		return extractHTMLFields(ctx, element, fieldConfig.Fields)
+	})
+}
+
+// nestedSyntax returns the selector syntax of fields nested in a field of syntax
+func nestedSyntax(syntax selectorSyntax) selectorSyntax {
+	if syntax == syntaxTableRow {
+		return syntaxHTML
+	}
+	return syntax
+}
+
+// validateNestedSelector checks the selector of a nested field, which selects elements
+func (c *Config) validateNestedSelector(selector string, syntax selectorSyntax) error {
+	switch syntax {
+	case syntaxJSON, syntaxJSONItem:
+		_, err := splitJSONPath(selector)
+		return err
+	case syntaxXML:
+		_, err := compileXMLSelector(selector, c.Namespaces)
+		return err
+	case syntaxAuto:
+		if strings.HasPrefix(selector, "$") {
+			return c.validateNestedSelector(selector, syntaxJSON)
+		}
+	}
+
+	if isScriptSelector(selector) || isStructuredSelector(selector) || isColumnSelector(selector) {
+		return fmt.Errorf("nested fields need an XPath or css: selector, got %q", selector)
+	}
+	_, err := compileSelector(selector)
+	return err
+}
+
+// validateNestedField checks the options and nested fields of a field with Fields. kind and
+// fieldName name the field in errors.
+func (c *Config) validateNestedField(kind, fieldName string, fieldConfig FieldConfig, syntax selectorSyntax) error {
+	if !isNestedField(fieldConfig) {
+		if fieldConfig.List {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("%s '%s' sets list without nested fields", kind, fieldName),
+			}
+		}
+		return nil
+	}
+
+	if len(fieldConfig.Pipes) > 0 || fieldConfig.Type != "" || fieldConfig.Layout != "" ||
+		fieldConfig.Default != nil || fieldConfig.Output != "" || fieldConfig.Follow != nil {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("%s '%s' has nested fields and cannot use pipes, type, layout, default, output or follow", kind, fieldName),
+		}
+	}
+
+	for name, nested := range fieldConfig.Fields {
+		nestedName := fieldName + "." + name
+		if nested.Follow != nil {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("%s '%s' cannot use follow", kind, nestedName),
+			}
+		}
+		if err := c.validateField(kind, nestedName, nested, nestedSyntax(syntax)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package gtmlp
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const nestedTestHTML = `<html><body>
+<div class="product">
+  <h2>Widget</h2>
+  <div class="seller"><a href="/s/1">Acme</a><span class="rating">4.5</span></div>
+  <ul>
+    <li class="review"><b>Ann</b><p>Great</p></li>
+    <li class="review"><b>Bob</b><p>Fine</p></li>
+  </ul>
+</div>
+<div class="product"><h2>Gadget</h2></div>
+</body></html>`
+
+// TestScrape_NestedFields tests nested objects and lists of objects in HTML items
+func TestScrape_NestedFields(t *testing.T) {
+	config := &Config{
+		Container: "//div[@class='product']",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: ".//h2"},
+			"seller": {XPath: ".//div[@class='seller']", Fields: map[string]FieldConfig{
+				"name":   {XPath: ".//a"},
+				"url":    {XPath: ".//a/@href"},
+				"rating": {XPath: ".//span[@class='rating']", Type: TypeFloat},
+			}},
+			"reviews": {XPath: "css:li.review", List: true, Fields: map[string]FieldConfig{
+				"author": {XPath: ".//b"},
+				"text":   {XPath: ".//p"},
+			}},
+		},
+		Timeout: 30 * time.Second,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	results, err := ScrapeUntyped(context.Background(), nestedTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+
+	expected := []map[string]any{
+		{
+			"name":   "Widget",
+			"seller": map[string]any{"name": "Acme", "url": "/s/1", "rating": 4.5},
+			"reviews": []any{
+				map[string]any{"author": "Ann", "text": "Great"},
+				map[string]any{"author": "Bob", "text": "Fine"},
+			},
+		},
+		{"name": "Gadget", "seller": nil, "reviews": []any{}},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
+
+// TestScrape_NestedFieldsJSONAndXML tests nested fields with the JSON and XML parsers
+func TestScrape_NestedFieldsJSONAndXML(t *testing.T) {
+	jsonConfig := &Config{
+		Parser:    ParserJSON,
+		Container: "$.orders",
+		Fields: map[string]FieldConfig{
+			"id":    {XPath: "id"},
+			"lines": {XPath: "lines", List: true, Fields: map[string]FieldConfig{"sku": {XPath: "sku"}, "qty": {XPath: "qty", Type: TypeInt}}},
+		},
+		Timeout: 30 * time.Second,
+	}
+	if err := jsonConfig.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	data, err := parseJSON(`{"orders":[{"id":"o1","lines":[{"sku":"a","qty":2},{"sku":"b","qty":1}]}]}`)
+	if err != nil {
+		t.Fatalf("parseJSON failed: %v", err)
+	}
+	items, _, err := extractJSONPage(context.Background(), data, jsonConfig)
+	if err != nil {
+		t.Fatalf("extractJSONPage failed: %v", err)
+	}
+	lines, _ := items[0]["lines"].([]any)
+	if len(lines) != 2 || !reflect.DeepEqual(lines[1], map[string]any{"sku": "b", "qty": 1}) {
+		t.Errorf("Unexpected JSON lines: %v", items)
+	}
+
+	xmlConfig := &Config{
+		Parser:    ParserXML,
+		Container: "//order",
+		Fields: map[string]FieldConfig{
+			"customer": {XPath: "customer", Fields: map[string]FieldConfig{"name": {XPath: "@name"}}},
+		},
+		Timeout: 30 * time.Second,
+	}
+	doc, err := parseXML(`<orders><order><customer name="Ann"/></order></orders>`)
+	if err != nil {
+		t.Fatalf("parseXML failed: %v", err)
+	}
+	items, _, err = extractXMLPage(context.Background(), doc, xmlConfig)
+	if err != nil {
+		t.Fatalf("extractXMLPage failed: %v", err)
+	}
+	if !reflect.DeepEqual(items[0]["customer"], map[string]any{"name": "Ann"}) {
+		t.Errorf("Unexpected XML customer: %v", items)
+	}
+}
+
+// TestValidate_NestedFields tests validation of nested fields
+func TestValidate_NestedFields(t *testing.T) {
+	child := map[string]FieldConfig{"name": {XPath: ".//a"}}
+	tests := []struct {
+		name   string
+		field  FieldConfig
+		errMsg string
+	}{
+		{"valid", FieldConfig{XPath: ".//div", Fields: child}, ""},
+		{"list without fields", FieldConfig{XPath: ".//div", List: true}, "sets list without nested fields"},
+		{"pipes", FieldConfig{XPath: ".//div", Pipes: []string{"trim"}, Fields: child}, "cannot use pipes"},
+		{"value selector", FieldConfig{XPath: "jsonld:Product", Fields: child}, "invalid xpath for field 'seller'"},
+		{"invalid nested xpath", FieldConfig{XPath: ".//div", Fields: map[string]FieldConfig{"name": {XPath: ".//a["}}}, "field 'seller.name'"},
+		{"nested follow", FieldConfig{XPath: ".//div", Fields: map[string]FieldConfig{"name": {XPath: ".//a", Follow: &Config{Fields: child}}}}, "'seller.name' cannot use follow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Container: "//li",
+				Fields:    map[string]FieldConfig{"seller": tt.field},
+				Timeout:   30 * time.Second,
+			}
+			err := config.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
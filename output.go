@@ -0,0 +1,160 @@
+package gtmlp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// Field output modes (see FieldConfig.Output)
+const (
+	OutputText       = "text"       // Trimmed text content (default)
+	OutputHTML       = "html"       // Inner HTML
+	OutputOuterHTML  = "outer-html" // HTML of the node itself
+	OutputLines      = "lines"      // Text with a line break between block elements and at <br>
+	OutputNormalized = "normalized" // Text with runs of whitespace collapsed to single spaces
+	OutputOwnText    = "own-text"   // The node's own text, excluding child elements
+	OutputCount      = "count"      // Number of matching nodes
+)
+
+// blockElements start a new line in OutputLines text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "dd": true,
+	"details": true, "dialog": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hgroup": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true,
+	"table": true, "tr": true, "ul": true,
+}
+
+// preProtect and preRestore shield the whitespace of <pre> text from blockText's line normalization
+// by swapping it for private-use runes, restored once the lines are built
+var (
+	preProtect = strings.NewReplacer(" ", "\uE000", "\t", "\uE001", "\n", "\uE002", "\r", "")
+	preRestore = strings.NewReplacer("\uE000", " ", "\uE001", "\t", "\uE002", "\n")
+)
+
+// validateOutput checks a FieldConfig.Output value
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputText, OutputHTML, OutputOuterHTML, OutputLines, OutputNormalized, OutputOwnText, OutputCount:
+		return nil
+	}
+	return fmt.Errorf("unknown output mode %q (must be 'text', 'html', 'outer-html', 'lines', 'normalized', 'own-text' or 'count')", output)
+}
+
+// countNodes returns the number of nodes left in an iterator, as a field value.
+// No matches is empty, so fallbacks and defaults apply before the count becomes "0".
+func countNodes(nodes *xpath.NodeIterator) string {
+	n := 0
+	for nodes.MoveNext() {
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// attributeOutput formats a selected attribute value; only OutputNormalized changes it
+func attributeOutput(value, output string) string {
+	if output == OutputNormalized {
+		return normalizeSpace(value)
+	}
+	return value
+}
+
+// nodeOutput formats a selected HTML node according to an output mode
+func nodeOutput(node *html.Node, output string) string {
+	switch output {
+	case OutputHTML:
+		if node.Type != html.ElementNode {
+			return strings.TrimSpace(htmlquery.OutputHTML(node, true))
+		}
+		return strings.TrimSpace(htmlquery.OutputHTML(node, false))
+	case OutputOuterHTML:
+		return htmlquery.OutputHTML(node, true)
+	case OutputLines:
+		return blockText(node)
+	case OutputNormalized:
+		return normalizeSpace(htmlquery.InnerText(node))
+	case OutputOwnText:
+		if node.Type != html.ElementNode {
+			return strings.TrimSpace(node.Data)
+		}
+		var text strings.Builder
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.TextNode {
+				text.WriteString(child.Data)
+				text.WriteByte(' ')
+			}
+		}
+		return normalizeSpace(text.String())
+	}
+	return extractValue(node).(string)
+}
+
+// blockText returns the readable text of node: whitespace is collapsed within
+// lines, and block elements, table cells and <br> separate the text as a browser would.
+// <pre> text keeps its whitespace. Script and style contents are skipped, and empty lines are dropped.
+func blockText(node *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node, pre bool)
+	walk = func(n *html.Node, pre bool) {
+		switch n.Type {
+		case html.TextNode:
+			if pre {
+				b.WriteString(preProtect.Replace(n.Data))
+				return
+			}
+			// Keep a separator where the text touches surrounding whitespace
+			words := strings.Fields(n.Data)
+			if len(words) == 0 || strings.TrimLeftFunc(n.Data, unicode.IsSpace) != n.Data {
+				b.WriteByte(' ')
+			}
+			b.WriteString(strings.Join(words, " "))
+			if len(words) > 0 && strings.TrimRightFunc(n.Data, unicode.IsSpace) != n.Data {
+				b.WriteByte(' ')
+			}
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "template", "noscript":
+				return
+			case "br":
+				b.WriteByte('\n')
+				return
+			case "td", "th":
+				b.WriteByte(' ')
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			b.WriteByte('\n')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, pre || n.Data == "pre")
+		}
+		if block {
+			b.WriteByte('\n')
+		}
+	}
+	walk(node, false)
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		// A <pre> block's final line break ends its line
+		line = strings.TrimRight(normalizeSpace(line), "\uE002")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return preRestore.Replace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,136 @@
+package gtmlp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const outputTestHTML = `<html><body>
+<div class="product">
+  <h2>Blue   Widget <small>new</small></h2>
+  <div class="price">Price<span>$10</span><em>Sale</em></div>
+  <div class="description">
+    <p>First <b>bold</b> paragraph.</p>
+    <ul><li>One</li><li>Two</li></ul>
+    Line<br>break
+    <script>var hidden = 1;</script>
+    <table><tr><td>Size</td><td>XL</td></tr></table>
+    <pre>if x {
+    return  1
+}
+</pre>
+  </div>
+  <img src="/a.png" alt="  Front
+     view  ">
+  <span class="tag">a</span><span class="tag">b</span><span class="tag">c</span>
+</div>
+</body></html>`
+
+// TestScrape_FieldOutputModes tests every field output mode
+func TestScrape_FieldOutputModes(t *testing.T) {
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"text":       {XPath: `.//div[@class="price"]`},
+			"html":       {XPath: `.//div[@class="price"]`, Output: OutputHTML},
+			"outer":      {XPath: `.//span[@class="tag"]`, Output: OutputOuterHTML},
+			"lines":      {XPath: `.//div[@class="description"]`, Output: OutputLines},
+			"normalized": {XPath: `.//h2`, Output: OutputNormalized},
+			"own":        {XPath: `.//h2`, Output: OutputOwnText},
+			"count":      {XPath: `.//span[@class="tag"]`, Output: OutputCount, Pipes: []string{"toint"}},
+			"none":       {XPath: `.//span[@class="missing"]`, Output: OutputCount},
+			"fallback":   {XPath: `.//span[@class="missing"]`, AltXPath: []string{`.//span[@class="tag"]`}, Output: OutputCount},
+			"defaulted":  {XPath: `.//span[@class="missing"]`, Output: OutputCount, Default: "unknown"},
+			"alt":        {XPath: `.//img/@alt`, Output: OutputNormalized},
+			"textNode":   {XPath: `.//p/b/text()`, Output: OutputHTML},
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	results, err := ScrapeUntyped(context.Background(), outputTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	expected := map[string]any{
+		"text":       "Price$10Sale",
+		"html":       "Price<span>$10</span><em>Sale</em>",
+		"outer":      `<span class="tag">a</span>`,
+		"lines":      "First bold paragraph.\nOne\nTwo\nLine\nbreak\nSize XL\nif x {\n    return  1\n}",
+		"normalized": "Blue Widget new",
+		"own":        "Blue Widget",
+		"count":      3,
+		"none":       "0",
+		"fallback":   "3",
+		"defaulted":  "unknown",
+		"alt":        "Front view",
+		"textNode":   "bold",
+	}
+	for field, want := range expected {
+		if got := results[0][field]; got != want {
+			t.Errorf("Field %s: expected %q, got %q", field, want, got)
+		}
+	}
+}
+
+// TestExtractXMLField_OutputModes tests output modes on XML documents
+func TestExtractXMLField_OutputModes(t *testing.T) {
+	doc, err := parseXML(`<feed><entry><title>Hello <b>XML</b> world</title></entry><entry/></feed>`)
+	if err != nil {
+		t.Fatalf("parseXML failed: %v", err)
+	}
+
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{OutputText, "Hello XML world"},
+		{OutputHTML, "Hello <b>XML</b> world"},
+		{OutputOuterHTML, "<title>Hello <b>XML</b> world</title>"},
+		{OutputOwnText, "Hello world"},
+	}
+	for _, tt := range tests {
+		if got := extractXMLField(doc, "//title", nil, tt.output); got != tt.expected {
+			t.Errorf("Output %q: expected %q, got %q", tt.output, tt.expected, got)
+		}
+	}
+	if got := extractXMLField(doc, "//entry", nil, OutputCount); got != "2" {
+		t.Errorf("Expected count 2, got %q", got)
+	}
+}
+
+// TestValidate_FieldOutput tests validation of output modes
+func TestValidate_FieldOutput(t *testing.T) {
+	config := &Config{
+		Container: "//div",
+		Fields:    map[string]FieldConfig{"body": {XPath: ".//p", Output: "markdown"}},
+		Timeout:   30 * time.Second,
+	}
+	err := config.Validate()
+	if err == nil || !strings.Contains(err.Error(), "invalid output for field 'body'") {
+		t.Errorf("Expected invalid output error, got %v", err)
+	}
+
+	config.Fields["body"] = FieldConfig{XPath: ".//p", Output: OutputLines}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+
+	// Selectors that return values unchanged reject output modes
+	for _, selector := range []string{"jsonld:Product.name", "og:title"} {
+		config.Fields["body"] = FieldConfig{XPath: ".//p", AltXPath: []string{selector}, Output: OutputCount}
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected output error for %s, got nil", selector)
+		}
+	}
+	config.Fields["body"] = FieldConfig{XPath: "$.body", Output: OutputHTML}
+	config.Parser = ParserJSON
+	if err := config.Validate(); err == nil {
+		t.Error("Expected output error for a JSON path, got nil")
+	}
+}
@@ -0,0 +1,251 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xmlquery"
+	"golang.org/x/net/html"
+)
+
+// Body parsers (see Config.Parser). An empty Parser is HTML; ParserAuto
+// picks one per response from the Content-Type, defaulting to HTML.
+const (
+	ParserHTML = "html"
+	ParserJSON = "json"
+	ParserXML  = "xml"
+	ParserAuto = "auto"
+)
+
+// parserPaginationTypes lists the pagination types that work with each non-HTML parser
+var parserPaginationTypes = map[string][]string{
+	ParserJSON: {"json", "link-header"},
+	ParserXML:  {"next-link", "link-header"},
+}
+
+// isJSONContentType reports whether a Content-Type header names a JSON media type
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isXMLContentType reports whether a Content-Type header names an XML media type, e.g. RSS or Atom.
+// XHTML is left to the HTML parser.
+func isXMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/xhtml+xml" {
+		return false
+	}
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// isHTMLContentType reports whether a Content-Type header names HTML or XHTML
+func isHTMLContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// pageParser resolves the parser for a body: Config.Parser, HTML if unset,
+// or from the Content-Type with ParserAuto
+func pageParser(config *Config, contentType string) string {
+	switch config.Parser {
+	case "":
+		return ParserHTML
+	case ParserAuto:
+	default:
+		return config.Parser
+	}
+	if isJSONContentType(contentType) {
+		return ParserJSON
+	}
+	if isXMLContentType(contentType) {
+		return ParserXML
+	}
+	return ParserHTML
+}
+
+// parsedPage is a parsed page body
+type parsedPage struct {
+	parser string
+	html   *html.Node     // ParserHTML
+	json   any            // ParserJSON
+	xml    *xmlquery.Node // ParserXML
+}
+
+// parseBody parses a page body with the parser chosen by the config and Content-Type
+func parseBody(body, contentType, pageURL string, config *Config) (*parsedPage, error) {
+	parser := pageParser(config, contentType)
+	getLogger().Debug("parsing page",
+		"url", pageURL,
+		"parser", parser)
+
+	switch parser {
+	case ParserJSON:
+		data, err := parseJSON(body)
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeParsing,
+				Message: "failed to parse JSON",
+				URL:     pageURL,
+				Cause:   err,
+			}
+		}
+		return &parsedPage{parser: parser, json: data}, nil
+	case ParserXML:
+		doc, err := parseXML(body)
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeParsing,
+				Message: "failed to parse XML",
+				URL:     pageURL,
+				Cause:   err,
+			}
+		}
+		return &parsedPage{parser: parser, xml: doc}, nil
+	default:
+		doc, err := htmlquery.Parse(strings.NewReader(body))
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeParsing,
+				Message: "failed to parse HTML",
+				URL:     pageURL,
+				Cause:   err,
+			}
+		}
+		return &parsedPage{parser: ParserHTML, html: doc}, nil
+	}
+}
+
+// fetchParsed performs a page request and parses the body
+func fetchParsed(pageReq *pageRequest, config *Config) (*fetchedPage, *parsedPage, error) {
+	page, err := fetchPageRequest(pageReq, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed, err := parseBody(page.Body, page.Header.Get("Content-Type"), page.URL, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return page, parsed, nil
+}
+
+// extractParsed extracts the items and document fields of a parsed page
+func extractParsed(ctx context.Context, page *parsedPage, config *Config) ([]map[string]any, map[string]any, error) {
+	switch page.parser {
+	case ParserJSON:
+		return extractJSONPage(ctx, page.json, config)
+	case ParserXML:
+		return extractXMLPage(ctx, page.xml, config)
+	}
+	return extractPage(ctx, page.html, config)
+}
+
+// extractJSONPage extracts items and document fields from a JSON document.
+// Container and field selectors are JSON paths; fields are relative to each container element.
+func extractJSONPage(ctx context.Context, data any, config *Config) ([]map[string]any, map[string]any, error) {
+	var document map[string]any
+	if len(config.DocumentFields) > 0 {
+		var err error
+		if document, err = extractJSONFields(ctx, data, config.DocumentFields, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	elements := findContainerMatches(config.Container, config.AltContainer, func(path string) []any {
+		return selectJSONElements(data, path)
+	})
+
+	items := make([]map[string]any, 0, len(elements))
+	for _, element := range elements {
+		fieldData, err := extractJSONFields(ctx, element, config.Fields, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, fieldData)
+	}
+
+	items, err := finishItems(ctx, items, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	copyDocumentFields(items, document, config)
+
+	return items, document, nil
+}
+
+// extractJSONFields extracts fields whose selectors are JSON paths relative to value.
+// If doc is set, script: selectors read embedded script values from it.
+func extractJSONFields(ctx context.Context, value any, fields map[string]FieldConfig, doc *html.Node) (map[string]any, error) {
+	fieldData := make(map[string]any, len(fields))
+	for fieldName, fieldConfig := range fields {
+		if isNestedField(fieldConfig) {
+			nestedValue, err := extractNested(fieldConfig, func(path string) []any {
+				return selectJSONElements(value, path)
+			}, func(element any) (map[string]any, error) {
+				return extractJSONFields(ctx, element, fieldConfig.Fields, doc)
+			})
+			if err != nil {
+				return nil, err
+			}
+			fieldData[fieldName] = nestedValue
+			continue
+		}
+		fieldValue, err := extractFieldValue(ctx, fieldConfig, func(selector string) any {
+			if doc != nil && isScriptSelector(selector) {
+				return extractScriptField(ctx, doc, selector)
+			}
+			matches := selectJSONPath(value, selector)
+			if len(matches) == 0 {
+				return ""
+			}
+			return jsonValueString(matches[0])
+		})
+		if err != nil {
+			return nil, err
+		}
+		fieldData[fieldName] = fieldValue
+	}
+	return fieldData, nil
+}
+
+// findContainerMatches returns the matches of the first container selector that
+// resolves to any, using resolve to evaluate each selector
+func findContainerMatches[T any](container string, altContainers []string, resolve func(selector string) []T) []T {
+	containers := append([]string{container}, altContainers...)
+	for i, selector := range containers {
+		elements := resolve(selector)
+		if len(elements) == 0 {
+			getLogger().Debug("container xpath returned empty",
+				"xpath", selector)
+			continue
+		}
+		if i > 0 {
+			getLogger().Warn("container fallback used",
+				"primary", container,
+				"used", selector,
+				"fallback_index", i)
+		}
+		return elements
+	}
+	return nil
+}
+
+// validateParser checks Config.Parser
+func validateParser(parser string) error {
+	switch parser {
+	case "", ParserHTML, ParserJSON, ParserXML, ParserAuto:
+		return nil
+	}
+	return &ScrapeError{
+		Type:    ErrTypeConfig,
+		Message: fmt.Sprintf("invalid parser: %s (must be 'html', 'json', 'xml' or 'auto')", parser),
+	}
+}
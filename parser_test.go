@@ -0,0 +1,239 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const jsonAPIBody = `{
+  "meta": {"category": "Tools"},
+  "data": {"items": [
+    {"id": 1, "title": "Hammer", "price": {"amount": "12.50"}, "tags": ["steel"]},
+    {"id": 2, "name": "Wrench", "price": {"amount": "8"}, "tags": []}
+  ]}
+}`
+
+type apiProduct struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Tag      string  `json:"tag"`
+	Category string  `json:"category"`
+}
+
+func jsonAPIConfig() *Config {
+	return &Config{
+		Parser:    ParserAuto,
+		Container: "$.data.items",
+		Fields: map[string]FieldConfig{
+			"id":    {XPath: "$.id", Pipes: []string{"toint"}},
+			"name":  {XPath: "$.title", AltXPath: []string{"$.name"}},
+			"price": {XPath: "$.price.amount", Pipes: []string{"tofloat"}},
+			"tag":   {XPath: "$.tags[0]"},
+		},
+		DocumentFields: map[string]FieldConfig{
+			"category": {XPath: "$.meta.category"},
+		},
+		CopyDocumentFields: true,
+		Timeout:            5 * time.Second,
+		AllowPrivateIPs:    true,
+	}
+}
+
+// TestPageParser tests choosing the parser from config and Content-Type
+func TestPageParser(t *testing.T) {
+	tests := []struct {
+		parser      string
+		contentType string
+		expected    string
+	}{
+		{ParserAuto, "application/json; charset=utf-8", ParserJSON},
+		{ParserAuto, "application/ld+json", ParserJSON},
+		{ParserAuto, "application/vnd.api+json", ParserJSON},
+		{ParserAuto, "text/xml", ParserXML},
+		{ParserAuto, "text/html; charset=utf-8", ParserHTML},
+		{ParserAuto, "", ParserHTML},
+		{"", "application/json", ParserHTML},
+		{ParserHTML, "application/json", ParserHTML},
+		{ParserJSON, "text/plain", ParserJSON},
+	}
+
+	for _, tt := range tests {
+		got := pageParser(&Config{Parser: tt.parser}, tt.contentType)
+		if got != tt.expected {
+			t.Errorf("pageParser(%q, %q) = %q, expected %q", tt.parser, tt.contentType, got, tt.expected)
+		}
+	}
+}
+
+// TestScrape_JSONParser tests scraping a JSON string with the json parser
+func TestScrape_JSONParser(t *testing.T) {
+	config := jsonAPIConfig()
+	config.Parser = ParserJSON
+
+	results, err := Scrape[apiProduct](context.Background(), jsonAPIBody, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+
+	expected := []apiProduct{
+		{ID: 1, Name: "Hammer", Price: 12.5, Tag: "steel", Category: "Tools"},
+		{ID: 2, Name: "Wrench", Price: 8, Category: "Tools"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("Item %d: expected %+v, got %+v", i, expected[i], results[i])
+		}
+	}
+
+	// Invalid JSON is a parsing error
+	_, err = Scrape[apiProduct](context.Background(), "{not json", config)
+	if !Is(err, ErrTypeParsing) {
+		t.Errorf("Expected parsing error, got %v", err)
+	}
+}
+
+// TestScrapeURL_JSONContentType tests detecting JSON responses from the Content-Type
+func TestScrapeURL_JSONContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(jsonAPIBody))
+	}))
+	defer server.Close()
+
+	results, err := ScrapeURLUntyped(context.Background(), server.URL, jsonAPIConfig())
+	if err != nil {
+		t.Fatalf("ScrapeURLUntyped failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[1]["name"] != "Wrench" || results[1]["category"] != "Tools" {
+		t.Errorf("Unexpected item: %v", results[1])
+	}
+}
+
+// TestScrapeURLWithPages_JSONPagination tests JSON items with json pagination
+func TestScrapeURLWithPages_JSONPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		next := `null`
+		if page == "" {
+			next = `"?page=2"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"results": [{"name": "item-%s-a"}, {"name": "item-%s-b"}], "next": %s}`, page, page, next)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container:       "$.results[*]",
+		Fields:          map[string]FieldConfig{"name": {XPath: "$.name"}},
+		Parser:          ParserAuto,
+		Pagination:      &PaginationConfig{Type: "json", JSONPath: "next"},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true,
+	}
+
+	results, err := ScrapeURLWithPages[map[string]any](context.Background(), server.URL, config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+	if results.TotalPages != 2 || results.TotalItems != 4 {
+		t.Fatalf("Expected 2 pages and 4 items, got %d and %d", results.TotalPages, results.TotalItems)
+	}
+	if results.Pages[1].Items[1]["name"] != "item-2-b" {
+		t.Errorf("Unexpected item: %v", results.Pages[1].Items[1])
+	}
+}
+
+// TestScrapeURLWithPages_JSONNeedsHTMLPagination tests HTML pagination on a JSON page
+func TestScrapeURLWithPages_JSONNeedsHTMLPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results": [{"name": "a"}]}`))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container:       "$.results",
+		Fields:          map[string]FieldConfig{"name": {XPath: "$.name"}},
+		Parser:          ParserAuto,
+		Pagination:      &PaginationConfig{Type: "next-link", NextSelector: "//a[@rel='next']/@href"},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true,
+	}
+
+	_, err := ScrapeURLWithPages[map[string]any](context.Background(), server.URL, config)
+	if err == nil || !strings.Contains(err.Error(), "requires an HTML page") {
+		t.Errorf("Expected HTML page error, got %v", err)
+	}
+}
+
+// TestValidate_Parser tests parser and JSON selector validation
+func TestValidate_Parser(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		valid  bool
+	}{
+		{"auto with json paths", func(c *Config) {}, true},
+		{"json parser without $", func(c *Config) {
+			c.Parser = ParserJSON
+			c.Container = "data.items"
+		}, true},
+		{"html parser rejects json path", func(c *Config) {
+			c.Parser = ParserHTML
+		}, false},
+		{"default parser rejects json path", func(c *Config) {
+			c.Parser = ""
+		}, false},
+		{"auto rejects html-only field selector", func(c *Config) {
+			c.Fields["brand"] = FieldConfig{XPath: "jsonld:Product.brand"}
+		}, false},
+		{"auto rejects table container", func(c *Config) {
+			c.Container = "table://table"
+		}, false},
+		{"auto with xpath field", func(c *Config) {
+			c.Container = "//item"
+			c.Fields = map[string]FieldConfig{"name": {XPath: "./title"}}
+			c.DocumentFields = nil
+		}, true},
+		{"unknown parser", func(c *Config) {
+			c.Parser = "csv"
+		}, false},
+		{"invalid json path", func(c *Config) {
+			c.Fields["bad"] = FieldConfig{XPath: "$.items[0"}
+		}, false},
+		{"json parser with next-link", func(c *Config) {
+			c.Parser = ParserJSON
+			c.Pagination = &PaginationConfig{Type: "next-link", NextSelector: "//a/@href"}
+		}, false},
+		{"json parser with json pagination", func(c *Config) {
+			c.Parser = ParserJSON
+			c.Pagination = &PaginationConfig{Type: "json", JSONPath: "next"}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := jsonAPIConfig()
+			tt.modify(config)
+			err := config.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid config, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package gtmlp
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces out requests to the same host.
+// Each call reserves the next free slot, so concurrent callers are serialized per host.
+type rateLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// hostLimiter is shared by all fetches so concurrent scrapes of the same host are limited together
+var hostLimiter = newRateLimiter()
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{next: make(map[string]time.Time)}
+}
+
+// reserve returns how long the caller must wait before requesting host,
+// and books the following slot interval later
+func (l *rateLimiter) reserve(host string, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(interval)
+
+	return at.Sub(now)
+}
+
+// wait blocks until a request to host is allowed
+func (l *rateLimiter) wait(host string, interval time.Duration) {
+	if delay := l.reserve(host, interval); delay > 0 {
+		getLogger().Debug("rate limit delay",
+			"host", host,
+			"delay", delay)
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,28 @@
+package gtmlp
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRateLimiter_Reserve tests that slots are booked per host
+func TestRateLimiter_Reserve(t *testing.T) {
+	limiter := newRateLimiter()
+
+	if d := limiter.reserve("a.example.com", time.Second); d != 0 {
+		t.Errorf("Expected first request without delay, got %v", d)
+	}
+
+	d := limiter.reserve("a.example.com", time.Second)
+	if d < 900*time.Millisecond || d > time.Second {
+		t.Errorf("Expected second request delayed ~1s, got %v", d)
+	}
+
+	if d := limiter.reserve("b.example.com", time.Second); d != 0 {
+		t.Errorf("Expected other host without delay, got %v", d)
+	}
+
+	if d := limiter.reserve("a.example.com", 0); d != 0 {
+		t.Errorf("Expected no delay without interval, got %v", d)
+	}
+}
@@ -0,0 +1,303 @@
+package gtmlp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsCacheTTL is how long a fetched robots.txt is reused (RFC 9309 recommends at most 24h)
+const robotsCacheTTL = 24 * time.Hour
+
+// robotsFailureTTL is how long an unreachable robots.txt keeps disallowing all before it is retried
+const robotsFailureTTL = time.Minute
+
+// maxRobotsSize is the robots.txt size limit (RFC 9309 requires parsing at least 500 KiB)
+const maxRobotsSize = 512 * 1024
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsGroup holds the rules for a set of user agents
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsRules is a parsed robots.txt
+type robotsRules struct {
+	groups      []*robotsGroup
+	sitemaps    []string
+	disallowAll bool // robots.txt was unreachable (5xx or network error)
+}
+
+// robotsEntry is a cached robots.txt for one origin
+type robotsEntry struct {
+	once    sync.Once
+	rules   *robotsRules
+	expires time.Time
+}
+
+// robotsCache caches robots.txt per scheme and host
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+// sharedRobotsCache is shared by all fetches
+var sharedRobotsCache = &robotsCache{entries: make(map[string]*robotsEntry)}
+
+// get returns the robots rules for the origin of u, fetching robots.txt once per TTL.
+// An unreachable robots.txt is retried after robotsFailureTTL.
+func (c *robotsCache) get(u *url.URL, config *Config) *robotsRules {
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	c.mu.Lock()
+	entry := c.entries[key]
+	if entry == nil || time.Now().After(entry.expires) {
+		entry = &robotsEntry{expires: time.Now().Add(robotsCacheTTL)}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.rules = fetchRobots(key+"/robots.txt", config)
+		if entry.rules.disallowAll {
+			c.mu.Lock()
+			entry.expires = time.Now().Add(robotsFailureTTL)
+			c.mu.Unlock()
+		}
+	})
+	return entry.rules
+}
+
+// fetchRobots fetches and parses robots.txt following RFC 9309:
+// 4xx means no restrictions, 5xx or an unreachable server means disallow all
+func fetchRobots(robotsURL string, config *Config) *robotsRules {
+	resp, err := fetchForHealth(robotsURL, config)
+	if err != nil {
+		getLogger().Warn("robots.txt unreachable, disallowing all",
+			"url", robotsURL,
+			"error", err.Error())
+		return &robotsRules{disallowAll: true}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		getLogger().Warn("robots.txt server error, disallowing all",
+			"url", robotsURL,
+			"status", resp.StatusCode)
+		return &robotsRules{disallowAll: true}
+	case resp.StatusCode >= 400:
+		getLogger().Debug("robots.txt not found, allowing all",
+			"url", robotsURL,
+			"status", resp.StatusCode)
+		return &robotsRules{}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return &robotsRules{disallowAll: true}
+	}
+
+	getLogger().Debug("robots.txt fetched",
+		"url", robotsURL,
+		"size_bytes", len(body))
+
+	return parseRobotsTxt(string(body))
+}
+
+// parseRobotsTxt parses robots.txt groups, rules, crawl delays and sitemaps
+func parseRobotsTxt(body string) *robotsRules {
+	rules := &robotsRules{}
+	var current *robotsGroup
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if current == nil || !lastWasAgent {
+				current = &robotsGroup{}
+				rules.groups = append(rules.groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			if current != nil && value != "" {
+				current.rules = append(current.rules, robotsRule{pattern: value, allow: name == "allow"})
+			}
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					current.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			if value != "" {
+				rules.sitemaps = append(rules.sitemaps, value)
+			}
+		}
+		lastWasAgent = false
+	}
+
+	return rules
+}
+
+// groupsFor returns the groups that apply to userAgent: every group naming its
+// product token (case-insensitive exact match), or the "*" groups if none do
+func (r *robotsRules) groupsFor(userAgent string) []*robotsGroup {
+	token := robotsProductToken(userAgent)
+
+	var matched, wildcard []*robotsGroup
+	for _, group := range r.groups {
+		for _, agent := range group.agents {
+			switch {
+			case agent == "*":
+				wildcard = append(wildcard, group)
+			case token != "" && agent == token:
+				matched = append(matched, group)
+			}
+		}
+	}
+
+	if len(matched) > 0 {
+		return matched
+	}
+	return wildcard
+}
+
+// allowed evaluates Allow/Disallow rules with longest-match semantics; Allow wins ties
+func (r *robotsRules) allowed(u *url.URL, userAgent string) bool {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if r.disallowAll {
+		return false
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	bestLen := -1
+	allow := true
+	for _, group := range r.groupsFor(userAgent) {
+		for _, rule := range group.rules {
+			if !robotsPatternMatches(rule.pattern, path) {
+				continue
+			}
+			if len(rule.pattern) > bestLen || (len(rule.pattern) == bestLen && rule.allow) {
+				bestLen = len(rule.pattern)
+				allow = rule.allow
+			}
+		}
+	}
+
+	return allow
+}
+
+// crawlDelay returns the Crawl-delay for userAgent, or zero if none
+func (r *robotsRules) crawlDelay(userAgent string) time.Duration {
+	var delay time.Duration
+	for _, group := range r.groupsFor(userAgent) {
+		if group.crawlDelay > delay {
+			delay = group.crawlDelay
+		}
+	}
+	return delay
+}
+
+// robotsPatternMatches matches a robots.txt path pattern supporting * and a trailing $
+func robotsPatternMatches(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+
+	return !anchored || rest == ""
+}
+
+// robotsProductToken returns the lowercase product token of a User-Agent, e.g. "gtmlp" for "GTMLP/2.0"
+func robotsProductToken(userAgent string) string {
+	token := strings.TrimSpace(userAgent)
+	if i := strings.IndexAny(token, "/ ;("); i >= 0 {
+		token = token[:i]
+	}
+	return strings.ToLower(token)
+}
+
+// robotsUserAgent returns the user agent matched against robots.txt groups
+func robotsUserAgent(config *Config) string {
+	if config.RobotsUserAgent != "" {
+		return config.RobotsUserAgent
+	}
+	return config.UserAgent
+}
+
+// checkRobots rejects URLs disallowed by robots.txt when the config respects robots.txt.
+// Returns the Crawl-delay that applies to the host.
+func checkRobots(u *url.URL, config *Config) (time.Duration, error) {
+	if !config.RespectRobots {
+		return 0, nil
+	}
+
+	rules := sharedRobotsCache.get(u, config)
+	userAgent := robotsUserAgent(config)
+
+	if !rules.allowed(u, userAgent) {
+		getLogger().Warn("url disallowed by robots.txt",
+			"url", u.String(),
+			"user_agent", userAgent)
+		return 0, &ScrapeError{
+			Type:    ErrTypeRobots,
+			Message: fmt.Sprintf("disallowed by robots.txt for user agent %q", userAgent),
+			URL:     u.String(),
+		}
+	}
+
+	return rules.crawlDelay(userAgent), nil
+}
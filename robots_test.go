@@ -0,0 +1,215 @@
+package gtmlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobotsTxt = `# comment
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+
+User-agent: GTMLP
+User-agent: otherbot
+Disallow: /admin
+Allow: /admin/help
+Crawl-delay: 0.05
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+// TestParseRobotsTxt tests group, rule, crawl delay and sitemap parsing
+func TestParseRobotsTxt(t *testing.T) {
+	rules := parseRobotsTxt(testRobotsTxt)
+
+	if len(rules.groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(rules.groups))
+	}
+	if got := rules.groups[1].agents; len(got) != 2 || got[0] != "gtmlp" || got[1] != "otherbot" {
+		t.Errorf("Expected grouped agents [gtmlp otherbot], got %v", got)
+	}
+	if len(rules.sitemaps) != 1 || rules.sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("Expected one sitemap, got %v", rules.sitemaps)
+	}
+	if delay := rules.crawlDelay("GTMLP/2.0"); delay != 50*time.Millisecond {
+		t.Errorf("Expected crawl delay 50ms, got %v", delay)
+	}
+	if delay := rules.crawlDelay("SomeBot/1.0"); delay != 0 {
+		t.Errorf("Expected no crawl delay for wildcard group, got %v", delay)
+	}
+}
+
+// TestRobotsRules_Allowed tests agent selection and longest-match evaluation
+func TestRobotsRules_Allowed(t *testing.T) {
+	rules := parseRobotsTxt(testRobotsTxt)
+
+	tests := []struct {
+		agent   string
+		path    string
+		allowed bool
+	}{
+		{"GTMLP/2.0", "/admin", false},
+		{"GTMLP/2.0", "/admin/help", true},
+		{"GTMLP/2.0", "/private", true}, // specific group replaces "*"
+		{"gtmlp", "/admin", false},
+		{"GTMLPBot/1.0", "/private", false}, // product tokens match exactly
+		{"Bot-GTMLP/1.0", "/admin", true},
+		{"SomeBot/1.0", "/private/x", false},
+		{"SomeBot/1.0", "/private/public/x", true},
+		{"SomeBot/1.0", "/docs/a.pdf", false},
+		{"SomeBot/1.0", "/docs/a.pdf?x=1", true},
+		{"SomeBot/1.0", "/", true},
+		{"SomeBot/1.0", "/robots.txt", true},
+	}
+
+	for _, tt := range tests {
+		u, _ := url.Parse("https://example.com" + tt.path)
+		if got := rules.allowed(u, tt.agent); got != tt.allowed {
+			t.Errorf("allowed(%q, %q) = %v, want %v", tt.path, tt.agent, got, tt.allowed)
+		}
+	}
+}
+
+// TestRobotsPatternMatches tests wildcard and end-anchor matching
+func TestRobotsPatternMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/", "/anything", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish", false},
+		{"/fish$", "/fish", true},
+		{"/fish$", "/fish/", false},
+		{"/*.php", "/index.php?x=1", true},
+		{"/*.php$", "/index.php?x=1", false},
+		{"/a*b*c", "/axxbyyc", true},
+		{"/a*b*c", "/axxc", false},
+		{"/*$", "/whatever", true},
+	}
+
+	for _, tt := range tests {
+		if got := robotsPatternMatches(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("robotsPatternMatches(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestScrapeURL_RespectRobots tests that disallowed URLs are rejected with a typed error
+func TestScrapeURL_RespectRobots(t *testing.T) {
+	var robotsFetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			atomic.AddInt32(&robotsFetches, 1)
+			fmt.Fprint(w, "User-agent: *\nDisallow: /blocked\n")
+		default:
+			fmt.Fprint(w, `<html><body><div class="item"><h2>Item</h2></div></body></html>`)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container:       "//div[@class='item']",
+		Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+		Timeout:         5 * time.Second,
+		UserAgent:       "GTMLP/2.0",
+		AllowPrivateIPs: true, // Allow localhost for testing
+		RespectRobots:   true,
+	}
+
+	items, err := ScrapeURLUntyped(context.Background(), server.URL+"/allowed", config)
+	if err != nil {
+		t.Fatalf("Expected allowed URL to succeed, got %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(items))
+	}
+
+	_, err = ScrapeURLUntyped(context.Background(), server.URL+"/blocked/page", config)
+	var scrapeErr *ScrapeError
+	if !errors.As(err, &scrapeErr) || scrapeErr.Type != ErrTypeRobots {
+		t.Fatalf("Expected robots error, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&robotsFetches); n != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", n)
+	}
+
+	// Without the opt-in robots.txt is ignored
+	config.RespectRobots = false
+	if _, err := ScrapeURLUntyped(context.Background(), server.URL+"/blocked/page", config); err != nil {
+		t.Errorf("Expected robots.txt to be ignored without RespectRobots, got %v", err)
+	}
+}
+
+// TestFetchRobots_StatusCodes tests that missing robots.txt allows all and server errors disallow all
+func TestFetchRobots_StatusCodes(t *testing.T) {
+	config := &Config{Timeout: 5 * time.Second, UserAgent: "GTMLP/2.0"}
+	u, _ := url.Parse("https://example.com/page")
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	if rules := fetchRobots(notFound.URL+"/robots.txt", config); !rules.allowed(u, config.UserAgent) {
+		t.Error("Expected 404 robots.txt to allow all")
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if rules := fetchRobots(failing.URL+"/robots.txt", config); rules.allowed(u, config.UserAgent) {
+		t.Error("Expected 5xx robots.txt to disallow all")
+	}
+
+	// A server error is cached briefly, not for the full TTL
+	cache := &robotsCache{entries: make(map[string]*robotsEntry)}
+	failingURL, _ := url.Parse(failing.URL + "/page")
+	cache.get(failingURL, config)
+	for _, entry := range cache.entries {
+		if time.Until(entry.expires) > robotsFailureTTL {
+			t.Errorf("Expected failed robots.txt to expire within %v, got %v", robotsFailureTTL, time.Until(entry.expires))
+		}
+	}
+}
+
+// TestScrapeURL_RobotsCrawlDelay tests that Crawl-delay spaces out requests to a host
+func TestScrapeURL_RobotsCrawlDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nCrawl-delay: 0.1\n")
+			return
+		}
+		fmt.Fprint(w, `<html><body><div class="item">x</div></body></html>`)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container:       "//div[@class='item']",
+		Fields:          map[string]FieldConfig{"text": {XPath: "."}},
+		Timeout:         5 * time.Second,
+		UserAgent:       "GTMLP/2.0",
+		AllowPrivateIPs: true, // Allow localhost for testing
+		RespectRobots:   true,
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := ScrapeURLUntyped(context.Background(), fmt.Sprintf("%s/p%d", server.URL, i), config); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected crawl delay to space 3 requests over >=200ms, took %v", elapsed)
+	}
+}
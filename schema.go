@@ -0,0 +1,137 @@
+package gtmlp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema types of nested fields (see FieldConfig.Fields)
+const (
+	schemaObject = "object"
+	schemaList   = "list"
+)
+
+// FieldSchema describes one output column of a config
+type FieldSchema struct {
+	Name     string
+	Type     string // FieldConfig.Type, inferred from the conversion pipes ("string" otherwise), or "object"/"list" for nested fields
+	XPath    string
+	AltXPath []string
+	Default  any
+	Document bool // Copied from DocumentFields rather than extracted per item
+}
+
+// ConfigSchema returns the output columns of a config with their types, sorted by
+// name: its fields, those of followed detail configs and copied document fields.
+// A name used more than once is described by its first field.
+func ConfigSchema(config *Config) []FieldSchema {
+	seen := make(map[string]bool)
+	var schema []FieldSchema
+	add := func(name string, field FieldConfig, document bool) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		schema = append(schema, FieldSchema{
+			Name:     name,
+			Type:     fieldSchemaType(field),
+			XPath:    field.XPath,
+			AltXPath: field.AltXPath,
+			Default:  field.Default,
+			Document: document,
+		})
+	}
+
+	var collect func(*Config)
+	collect = func(c *Config) {
+		for _, name := range sortedFieldNames(c.Fields) {
+			field := c.Fields[name]
+			add(name, field, false)
+			if field.Follow != nil {
+				collect(field.Follow)
+			}
+		}
+	}
+	collect(config)
+	if config.CopyDocumentFields {
+		for _, name := range sortedFieldNames(config.DocumentFields) {
+			add(name, config.DocumentFields[name], true)
+		}
+	}
+
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Name < schema[j].Name })
+	return schema
+}
+
+// sortedFieldNames returns the keys of fields in order, so repeated names resolve deterministically
+func sortedFieldNames(fields map[string]FieldConfig) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fieldSchemaType returns a field's declared type, or the type its last conversion pipe produces
+func fieldSchemaType(field FieldConfig) string {
+	if field.Type != "" {
+		return field.Type
+	}
+	if isNestedField(field) {
+		if field.List {
+			return schemaList
+		}
+		return schemaObject
+	}
+	if field.Output == OutputCount {
+		return TypeInt
+	}
+	typ := TypeString
+	for _, pipe := range field.Pipes {
+		switch strings.SplitN(pipe, ":", 2)[0] {
+		case "toint":
+			typ = TypeInt
+		case "tofloat":
+			typ = TypeFloat
+		case "parsetime":
+			typ = TypeTime
+		case "parseurl":
+			typ = TypeURL
+		}
+	}
+	return typ
+}
+
+// ConfigMarkdown documents a config's output columns as a Markdown table
+func ConfigMarkdown(config *Config) string {
+	var b strings.Builder
+	b.WriteString("| Field | Type | Selector | Default |\n")
+	b.WriteString("|-------|------|----------|---------|\n")
+	for _, field := range ConfigSchema(config) {
+		selectors := []string{markdownCode(field.XPath)}
+		for _, alt := range field.AltXPath {
+			selectors = append(selectors, markdownCode(alt))
+		}
+		name := markdownCode(field.Name)
+		if field.Document {
+			name += " (document)"
+		}
+		defaultValue := ""
+		if field.Default != nil {
+			defaultValue = markdownCode(fmt.Sprint(field.Default))
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", name, field.Type, strings.Join(selectors, " or "), defaultValue)
+	}
+	return b.String()
+}
+
+// markdownCode formats s as inline code in a Markdown table cell
+func markdownCode(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
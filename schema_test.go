@@ -0,0 +1,46 @@
+package gtmlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestConfigSchema tests declared and inferred field types
+func TestConfigSchema(t *testing.T) {
+	config := &Config{
+		Fields: map[string]FieldConfig{
+			"title":   {XPath: ".//h2"},
+			"price":   {XPath: ".//span", Pipes: []string{"trim", "tofloat"}},
+			"rating":  {XPath: ".//b", Type: TypeDecimal, Default: "0"},
+			"tags":    {XPath: ".//li", Output: OutputCount},
+			"updated": {XPath: ".//time", Pipes: []string{"parsetime:2006-01-02"}},
+		},
+		DocumentFields:     map[string]FieldConfig{"site": {XPath: "//title"}},
+		CopyDocumentFields: true,
+	}
+
+	var got []string
+	for _, field := range ConfigSchema(config) {
+		got = append(got, field.Name+":"+field.Type)
+	}
+	want := []string{"price:float", "rating:decimal", "site:string", "tags:int", "title:string", "updated:time"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigSchema = %v, want %v", got, want)
+	}
+}
+
+// TestConfigMarkdown tests generated field documentation
+func TestConfigMarkdown(t *testing.T) {
+	config := &Config{
+		Fields: map[string]FieldConfig{
+			"price": {XPath: ".//span[@class='price']", AltXPath: []string{"css:.price | .cost"}, Type: TypeFloat, Default: 0},
+		},
+	}
+
+	want := "| Field | Type | Selector | Default |\n" +
+		"|-------|------|----------|---------|\n" +
+		"| `price` | float | `.//span[@class='price']` or `css:.price \\| .cost` | `0` |\n"
+	if got := ConfigMarkdown(config); got != want {
+		t.Errorf("ConfigMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/antchfx/htmlquery"
 	"github.com/antchfx/xpath"
@@ -27,115 +28,233 @@ func Scrape[T any](ctx context.Context, html string, config *Config) ([]T, error
 		return nil, err
 	}
 
-	// Parse HTML
-	doc, err := htmlquery.Parse(strings.NewReader(html))
+	// Parse HTML, or JSON with the json parser
+	page, err := parseBody(html, "", "", config)
 	if err != nil {
 		getLogger().Error("html parsing failed",
 			"error", err.Error())
-		return nil, &ScrapeError{
-			Type:    ErrTypeParsing,
-			Message: "failed to parse HTML",
-			Cause:   err,
-		}
+		return nil, err
 	}
 
-	// Find container nodes with fallback
-	containerNodes, err := findContainers(doc, config.Container, config.AltContainer)
+	results, _, err := scrapeParsed[T](ctx, page, config)
 	if err != nil {
 		return nil, err
 	}
 
-	var results []T
+	getLogger().Info("scraping completed",
+		"items", len(results),
+		"container", config.Container)
 
-	for containerNodes.MoveNext() {
-		containerNode := containerNodes.Current().(*htmlquery.NodeNavigator).Current()
+	return results, nil
+}
 
-		// Extract fields from this container
-		fieldData := make(map[string]any)
-		for fieldName, fieldConfig := range config.Fields {
-			value, err := extractFieldWithPipes(ctx, containerNode, fieldConfig)
-			if err != nil {
-				return nil, err
-			}
-			fieldData[fieldName] = value
-		}
+// ScrapePage extracts items like Scrape and also returns the page-level
+// DocumentFields, extracted once from the whole document.
+func ScrapePage[T any](ctx context.Context, html string, config *Config) (*PageResult[T], error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
+	page, err := parseBody(html, "", "", config)
+	if err != nil {
+		return nil, err
+	}
+
+	items, document, err := scrapeParsed[T](ctx, page, config)
+	if err != nil {
+		return nil, err
+	}
+
+	pageURL, _ := ctx.Value(contextKey("baseURL")).(string)
+	return &PageResult[T]{
+		URL:       pageURL,
+		PageNum:   1,
+		Items:     items,
+		Document:  document,
+		ScrapedAt: time.Now(),
+	}, nil
+}
+
+// ScrapeUntyped extracts data from HTML using XPath, returning map slices.
+// It finds all container nodes and extracts fields from each one.
+// Returns an empty slice if no containers are found.
+func ScrapeUntyped(ctx context.Context, html string, config *Config) ([]map[string]any, error) {
+	// Validate config
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Parse HTML, or JSON with the json parser
+	page, err := parseBody(html, "", "", config)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _, err := extractParsed(ctx, page, config)
+	return items, err
+}
+
+// scrapeParsed extracts typed items and document fields from a parsed page.
+// Config must be validated by the caller.
+func scrapeParsed[T any](ctx context.Context, page *parsedPage, config *Config) ([]T, map[string]any, error) {
+	// Seen IDs are committed once the items convert, unless the caller delivers them
+	ctx, dedupPage := withDedupPage(ctx, config)
+	defer dedupPage.release()
+
+	items, document, err := extractParsed(ctx, page, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]T, 0, len(items))
+	for _, fieldData := range items {
 		// Convert map to struct
 		var result T
 		if err := mapToStruct(fieldData, &result); err != nil {
-			return nil, &ScrapeError{
+			return nil, nil, &ScrapeError{
 				Type:    ErrTypeParsing,
 				Message: "failed to convert map to struct",
 				Cause:   err,
 			}
 		}
-
 		results = append(results, result)
 	}
 
-	// Return empty slice if no containers found
-	if results == nil {
-		results = []T{}
+	if err := dedupPage.commit(); err != nil {
+		return nil, nil, err
 	}
 
-	getLogger().Info("scraping completed",
-		"items", len(results),
-		"container", config.Container)
+	return results, document, nil
+}
 
-	return results, nil
+// extractPage extracts the items and the document fields of a page.
+// With CopyDocumentFields, document values are also copied into every item.
+func extractPage(ctx context.Context, doc *html.Node, config *Config) ([]map[string]any, map[string]any, error) {
+	ctx = withScripts(ctx, config)
+
+	document, err := extractDocumentFields(ctx, doc, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := extractItems(ctx, doc, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	copyDocumentFields(items, document, config)
+
+	return items, document, nil
 }
 
-// ScrapeUntyped extracts data from HTML using XPath, returning map slices.
-// It finds all container nodes and extracts fields from each one.
+// copyDocumentFields copies document values into every item if CopyDocumentFields is set
+func copyDocumentFields(items []map[string]any, document map[string]any, config *Config) {
+	if !config.CopyDocumentFields {
+		return
+	}
+	for _, item := range items {
+		for name, value := range document {
+			item[name] = value
+		}
+	}
+}
+
+// extractDocumentFields evaluates DocumentFields once against the whole document.
+// Returns nil if the config has no document fields.
+func extractDocumentFields(ctx context.Context, doc *html.Node, config *Config) (map[string]any, error) {
+	if len(config.DocumentFields) == 0 {
+		return nil, nil
+	}
+
+	document := make(map[string]any, len(config.DocumentFields))
+	for fieldName, fieldConfig := range config.DocumentFields {
+		value, err := extractFieldWithPipes(ctx, doc, fieldConfig)
+		if err != nil {
+			return nil, err
+		}
+		document[fieldName] = value
+	}
+
+	return document, nil
+}
+
+// extractItems finds all container nodes in doc and extracts fields from each one.
 // Returns an empty slice if no containers are found.
-func ScrapeUntyped(ctx context.Context, html string, config *Config) ([]map[string]any, error) {
-	// Validate config
-	if err := config.Validate(); err != nil {
+func extractItems(ctx context.Context, doc *html.Node, config *Config) ([]map[string]any, error) {
+	var results []map[string]any
+	var err error
+	if isScriptSelector(config.Container) {
+		// Items come from a JSON array embedded in a script tag
+		results, err = extractScriptItems(ctx, doc, config)
+	} else if isTableSelector(config.Container) {
+		// Items are the data rows of HTML tables
+		results, err = extractTableItems(ctx, doc, config)
+	} else {
+		results, err = extractContainerItems(ctx, doc, config)
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	// Parse HTML
-	doc, err := htmlquery.Parse(strings.NewReader(html))
-	if err != nil {
-		return nil, &ScrapeError{
-			Type:    ErrTypeParsing,
-			Message: "failed to parse HTML",
-			Cause:   err,
+	return finishItems(ctx, results, config)
+}
+
+// finishItems fetches detail pages for follow fields and stamps and deduplicates items
+func finishItems(ctx context.Context, results []map[string]any, config *Config) ([]map[string]any, error) {
+	// Fetch detail pages and merge their fields into each item. Only fetched
+	// pages follow links: scraping an HTML string or file stays offline.
+	if hasFollowFields(config) && isFetchedPage(ctx) {
+		if err := followDetails(ctx, results, config); err != nil {
+			return nil, err
 		}
 	}
 
+	// Stamp item IDs and drop duplicates
+	if config.Dedup != nil {
+		return dedupItems(ctx, results, config)
+	}
+
+	return results, nil
+}
+
+// extractContainerItems extracts the fields of every container node in doc
+func extractContainerItems(ctx context.Context, doc *html.Node, config *Config) ([]map[string]any, error) {
 	// Find container nodes with fallback
 	containerNodes, err := findContainers(doc, config.Container, config.AltContainer)
 	if err != nil {
 		return nil, err
 	}
 
-	var results []map[string]any
+	results := []map[string]any{}
 
 	for containerNodes.MoveNext() {
 		containerNode := containerNodes.Current().(*htmlquery.NodeNavigator).Current()
 
 		// Extract fields from this container
-		fieldData := make(map[string]any)
-		for fieldName, fieldConfig := range config.Fields {
-			value, err := extractFieldWithPipes(ctx, containerNode, fieldConfig)
-			if err != nil {
-				return nil, err
-			}
-			fieldData[fieldName] = value
+		fieldData, err := extractHTMLFields(ctx, containerNode, config.Fields)
+		if err != nil {
+			return nil, err
 		}
 
 		results = append(results, fieldData)
 	}
 
-	// Return empty slice if no containers found
-	if results == nil {
-		results = []map[string]any{}
-	}
-
 	return results, nil
 }
 
+// extractHTMLFields extracts fields relative to an HTML node
+func extractHTMLFields(ctx context.Context, node *html.Node, fields map[string]FieldConfig) (map[string]any, error) {
+	fieldData := make(map[string]any, len(fields))
+	for fieldName, fieldConfig := range fields {
+		value, err := extractFieldWithPipes(ctx, node, fieldConfig)
+		if err != nil {
+			return nil, err
+		}
+		fieldData[fieldName] = value
+	}
+	return fieldData, nil
+}
+
 // findContainers finds container nodes with altContainer fallback support
 func findContainers(doc *html.Node, container string, altContainers []string) (*xpath.NodeIterator, error) {
 	// Build list of container XPaths to try
@@ -149,7 +268,7 @@ func findContainers(doc *html.Node, container string, altContainers []string) (*
 	// Try each container XPath in sequence
 	for i, containerXPath := range containers {
 		// Compile container XPath
-		containerExpr, err := xpath.Compile(containerXPath)
+		containerExpr, err := compileSelector(containerXPath)
 		if err != nil {
 			getLogger().Error("container xpath compilation failed",
 				"xpath", containerXPath,
@@ -190,10 +309,14 @@ func findContainers(doc *html.Node, container string, altContainers []string) (*
 	return emptyExpr.Evaluate(htmlquery.CreateXPathNavigator(doc)).(*xpath.NodeIterator), nil
 }
 
-// extractField extracts a value from a node using XPath
-func extractField(containerNode *html.Node, fieldXPath string) any {
+// extractField extracts a value from a node using XPath, formatted by an output mode (see FieldConfig.Output)
+func extractField(containerNode *html.Node, fieldXPath string, output string) any {
+	if isStructuredSelector(fieldXPath) {
+		return extractStructuredField(containerNode, fieldXPath)
+	}
+
 	// Compile field XPath
-	expr, err := xpath.Compile(fieldXPath)
+	expr, err := compileSelector(fieldXPath)
 	if err != nil {
 		return ""
 	}
@@ -201,6 +324,10 @@ func extractField(containerNode *html.Node, fieldXPath string) any {
 	// Evaluate XPath relative to container node
 	nodeIterator := expr.Evaluate(htmlquery.CreateXPathNavigator(containerNode)).(*xpath.NodeIterator)
 
+	if output == OutputCount {
+		return countNodes(nodeIterator)
+	}
+
 	// Move to first result
 	if !nodeIterator.MoveNext() {
 		return ""
@@ -210,14 +337,14 @@ func extractField(containerNode *html.Node, fieldXPath string) any {
 
 	// Check if it's an attribute node
 	if navigator.NodeType() == xpath.AttributeNode {
-		return navigator.Value()
+		return attributeOutput(navigator.Value(), output)
 	}
 
 	// For other nodes, get the HTML node
 	node := navigator.Current()
 
 	// Extract value based on node type
-	return extractValue(node)
+	return nodeOutput(node, output)
 }
 
 // extractValue extracts text or attribute value from a node
@@ -239,48 +366,30 @@ func extractValue(node *html.Node) any {
 
 // extractFieldWithPipes extracts a value and applies pipes, with altXpath fallback
 func extractFieldWithPipes(ctx context.Context, containerNode *html.Node, fieldConfig FieldConfig) (any, error) {
+	if isNestedField(fieldConfig) {
+		return extractNestedHTML(ctx, containerNode, fieldConfig)
+	}
+	return extractFieldValue(ctx, fieldConfig, func(selector string) any {
+		if isScriptSelector(selector) {
+			return extractScriptField(ctx, containerNode, selector)
+		}
+		return extractField(containerNode, selector, fieldConfig.Output)
+	})
+}
+
+// extractFieldValue resolves a field's selector chain with lookup and applies pipes,
+// with altXpath fallback. lookup returns the raw value for one selector.
+func extractFieldValue(ctx context.Context, fieldConfig FieldConfig, lookup func(selector string) any) (any, error) {
 	// Build list of XPaths to try: primary + alternatives
 	xpaths := []string{fieldConfig.XPath}
 	xpaths = append(xpaths, fieldConfig.AltXPath...)
 
 	// Try each XPath in sequence
 	for xpathIdx, xpath := range xpaths {
-		// Extract raw value with XPath
-		rawValue := extractField(containerNode, xpath)
-
-		// Convert to string for pipe processing
-		inputStr, ok := rawValue.(string)
-		if !ok {
-			inputStr = fmt.Sprintf("%v", rawValue)
-		}
-
-		// Apply pipes if defined
-		value := any(inputStr)
-		if len(fieldConfig.Pipes) > 0 {
-			for _, pipeDef := range fieldConfig.Pipes {
-				pipeName, params := parsePipeDefinition(pipeDef)
-				pipe := getPipe(pipeName)
-
-				if pipe == nil {
-					return "", &ScrapeError{
-						Type:    ErrTypePipe,
-						Message: fmt.Sprintf("unknown pipe '%s'", pipeName),
-					}
-				}
-
-				result, err := pipe(ctx, inputStr, params)
-				if err != nil {
-					return "", &ScrapeError{
-						Type:    ErrTypePipe,
-						Message: fmt.Sprintf("pipe '%s' failed", pipeName),
-						Cause:   &PipeError{PipeName: pipeName, Input: inputStr, Params: params, Cause: err},
-					}
-				}
-
-				value = result
-				// Convert result to string for next pipe
-				inputStr = fmt.Sprintf("%v", result)
-			}
+		// Extract raw value with XPath and apply pipes
+		value, err := applyFieldPipes(ctx, fieldConfig, lookup(xpath))
+		if err != nil {
+			return "", err
 		}
 
 		// Check if result is non-empty after pipes
@@ -291,7 +400,7 @@ func extractFieldWithPipes(ctx context.Context, containerNode *html.Node, fieldC
 					"used", xpath,
 					"fallback_index", xpathIdx)
 			}
-			return value, nil
+			return coerceFieldValue(ctx, fieldConfig, xpath, value)
 		}
 
 		getLogger().Debug("field xpath returned empty after pipes",
@@ -299,13 +408,78 @@ func extractFieldWithPipes(ctx context.Context, containerNode *html.Node, fieldC
 		// Result is empty, try next XPath
 	}
 
-	// All XPaths failed, return empty string
+	// All XPaths failed, use the default
 	getLogger().Warn("all xpaths failed for field",
 		"primary", fieldConfig.XPath,
 		"alternatives", len(fieldConfig.AltXPath))
+	if fieldConfig.Default != nil {
+		// Validate checks that the default converts
+		return coerceValue(ctx, fieldConfig, fieldConfig.Default)
+	}
+	if fieldConfig.Output == OutputCount {
+		// No selector matched anything, so the count is zero
+		value, err := applyFieldPipes(ctx, fieldConfig, "0")
+		if err != nil {
+			return "", err
+		}
+		return coerceFieldValue(ctx, fieldConfig, fieldConfig.XPath, value)
+	}
+	if fieldConfig.Type != "" {
+		return nil, nil
+	}
 	return "", nil
 }
 
+// applyFieldPipes converts a raw value to a string and runs it through the field's pipes
+func applyFieldPipes(ctx context.Context, fieldConfig FieldConfig, rawValue any) (any, error) {
+	// Convert to string for pipe processing
+	inputStr, ok := rawValue.(string)
+	if !ok {
+		inputStr = fmt.Sprintf("%v", rawValue)
+	}
+
+	value := any(inputStr)
+	for _, pipeDef := range fieldConfig.Pipes {
+		pipeName, params := parsePipeDefinition(pipeDef)
+		pipe := getPipe(pipeName)
+
+		if pipe == nil {
+			return "", &ScrapeError{
+				Type:    ErrTypePipe,
+				Message: fmt.Sprintf("unknown pipe '%s'", pipeName),
+			}
+		}
+
+		result, err := pipe(ctx, inputStr, params)
+		if err != nil {
+			return "", &ScrapeError{
+				Type:    ErrTypePipe,
+				Message: fmt.Sprintf("pipe '%s' failed", pipeName),
+				Cause:   &PipeError{PipeName: pipeName, Input: inputStr, Params: params, Cause: err},
+			}
+		}
+
+		value = result
+		// Convert result to string for next pipe
+		inputStr = fmt.Sprintf("%v", result)
+	}
+	return value, nil
+}
+
+// coerceFieldValue converts a selected value to the field's type
+func coerceFieldValue(ctx context.Context, fieldConfig FieldConfig, xpath string, value any) (any, error) {
+	typed, err := coerceValue(ctx, fieldConfig, value)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeFieldType,
+			Message: fmt.Sprintf("value is not a valid %s", fieldConfig.Type),
+			XPath:   xpath,
+			Cause:   err,
+		}
+	}
+	return typed, nil
+}
+
 // isEmpty checks if a value is considered empty after pipe processing
 func isEmpty(value any) bool {
 	if value == nil {
@@ -347,14 +521,12 @@ func ScrapeURL[T any](ctx context.Context, url string, config *Config) ([]T, err
 	// Check if pagination is configured
 	if config.Pagination != nil {
 		// Use pagination logic
-		results, err := scrapeWithPagination[T](ctx, url, config, false)
+		results, err := scrapeWithPagination[T](ctx, url, config, nil)
+		// Return combined items from all pages, including those scraped
+		// before a pagination failure
+		allItems := results.Items()
 		if err != nil {
-			return nil, err
-		}
-		// Return combined items from all pages
-		var allItems []T
-		for _, page := range results.Pages {
-			allItems = append(allItems, page.Items...)
+			return allItems, err
 		}
 		getLogger().Info("scrape url with pagination completed",
 			"url", url,
@@ -364,13 +536,11 @@ func ScrapeURL[T any](ctx context.Context, url string, config *Config) ([]T, err
 	}
 
 	// No pagination, single page scraping (backward compatible)
-	html, err := fetchHTML(url, config)
+	page, err := scrapeSinglePage[T](ctx, url, config)
 	if err != nil {
 		return nil, err
 	}
-	// Add URL to context for parseUrl pipe
-	ctx = WithURL(ctx, url)
-	return Scrape[T](ctx, html, config)
+	return page.Items, nil
 }
 
 // ScrapeURLUntyped fetches a URL and scrapes it, returning maps (no type parameter)
@@ -382,14 +552,12 @@ func ScrapeURLUntyped(ctx context.Context, url string, config *Config) ([]map[st
 	// Check if pagination is configured
 	if config.Pagination != nil {
 		// Use pagination logic
-		results, err := scrapeWithPagination[map[string]any](ctx, url, config, false)
+		results, err := scrapeWithPagination[map[string]any](ctx, url, config, nil)
+		// Return combined items from all pages, including those scraped
+		// before a pagination failure
+		allItems := results.Items()
 		if err != nil {
-			return nil, err
-		}
-		// Return combined items from all pages
-		var allItems []map[string]any
-		for _, page := range results.Pages {
-			allItems = append(allItems, page.Items...)
+			return allItems, err
 		}
 		getLogger().Info("scrape url untyped with pagination completed",
 			"url", url,
@@ -399,11 +567,14 @@ func ScrapeURLUntyped(ctx context.Context, url string, config *Config) ([]map[st
 	}
 
 	// No pagination, single page scraping (backward compatible)
-	html, err := fetchHTML(url, config)
+	_, page, err := fetchParsed(newGetRequest(url).withContext(ctx), config)
 	if err != nil {
 		return nil, err
 	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 	// Add URL to context for parseUrl pipe
-	ctx = WithURL(ctx, url)
-	return ScrapeUntyped(ctx, html, config)
+	items, _, err := extractParsed(withFetchConfig(WithURL(ctx, url), config), page, config)
+	return items, err
 }
@@ -0,0 +1,240 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	neturl "net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// FileOptions configures batch scraping of saved pages
+type FileOptions struct {
+	BaseURLs    map[string]string        // File path (as matched) → page URL
+	BaseURLFunc func(path string) string // Computes the page URL for files not in BaseURLs
+	Concurrency int                      // Files parsed in parallel (default: GOMAXPROCS)
+}
+
+// FileResult holds the items scraped from one file
+type FileResult[T any] struct {
+	Path     string // Source file path
+	BaseURL  string // Base URL used for parseurl ("" if none)
+	Items    []T
+	Document map[string]any // Document field values (see Config.DocumentFields)
+	Err      error          // Read, parse or extraction error for this file
+}
+
+// ScrapeReader parses r with Config.Parser (HTML by default) and extracts items.
+// The base URL for parseurl is the URL set with WithURL, combined with an HTML
+// document's <base href> if present.
+func ScrapeReader[T any](ctx context.Context, r io.Reader, config *Config) ([]T, error) {
+	if err := config.validateExtraction(); err != nil {
+		return nil, err
+	}
+
+	return scrapeReader[T](ctx, r, config)
+}
+
+// ScrapeFile reads a saved HTML page and extracts items, as ScrapeReader
+func ScrapeFile[T any](ctx context.Context, path string, config *Config) ([]T, error) {
+	if err := config.validateExtraction(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: fmt.Sprintf("failed to open file: %s", path),
+			Cause:   err,
+		}
+	}
+	defer f.Close()
+
+	return scrapeReader[T](ctx, f, config)
+}
+
+// ScrapeDir scrapes every file in dir matching the glob pattern, e.g. "pages/*.html"
+func ScrapeDir[T any](ctx context.Context, dir, pattern string, config *Config, options *FileOptions) ([]FileResult[T], error) {
+	return ScrapeFS[T](ctx, os.DirFS(dir), pattern, config, options)
+}
+
+// ScrapeFS scrapes every file in fsys matching the fs.Glob pattern in parallel.
+// Results are returned in path order, one per file; per-file failures are
+// reported in FileResult.Err rather than aborting the batch.
+func ScrapeFS[T any](ctx context.Context, fsys fs.FS, pattern string, config *Config, options *FileOptions) ([]FileResult[T], error) {
+	if err := config.validateExtraction(); err != nil {
+		return nil, err
+	}
+
+	paths, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("invalid file pattern: %s", pattern),
+			Cause:   err,
+		}
+	}
+
+	if options == nil {
+		options = &FileOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	getLogger().Info("file scrape starting",
+		"pattern", pattern,
+		"files", len(paths),
+		"concurrency", concurrency)
+
+	results := make([]FileResult[T], len(paths))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, path := range paths {
+		if ctx.Err() != nil {
+			results[i] = FileResult[T]{Path: path, Err: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = scrapeFSFile[T](ctx, fsys, path, config, options)
+		}(i, path)
+	}
+	wg.Wait()
+
+	failed, items := 0, 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+		items += len(result.Items)
+	}
+
+	getLogger().Info("file scrape complete",
+		"files", len(paths),
+		"items", items,
+		"errors", failed)
+
+	return results, nil
+}
+
+// scrapeFSFile scrapes one file, resolving its base URL from the options
+func scrapeFSFile[T any](ctx context.Context, fsys fs.FS, path string, config *Config, options *FileOptions) FileResult[T] {
+	result := FileResult[T]{Path: path}
+
+	baseURL := options.BaseURLs[path]
+	if baseURL == "" && options.BaseURLFunc != nil {
+		baseURL = options.BaseURLFunc(path)
+	}
+
+	f, err := fsys.Open(path)
+	if err != nil {
+		result.Err = &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: fmt.Sprintf("failed to open file: %s", path),
+			Cause:   err,
+		}
+		return result
+	}
+	defer f.Close()
+
+	page, err := parseReader(f, baseURL, config)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.BaseURL = pageBaseURL(page, baseURL)
+	if result.BaseURL != "" {
+		ctx = WithURL(ctx, result.BaseURL)
+	}
+
+	result.Items, result.Document, result.Err = scrapeParsed[T](ctx, page, config)
+	if result.Err != nil {
+		getLogger().Warn("file scrape failed",
+			"path", path,
+			"error", result.Err.Error())
+	}
+
+	return result
+}
+
+// scrapeReader parses r and extracts items using the context URL and <base href>
+func scrapeReader[T any](ctx context.Context, r io.Reader, config *Config) ([]T, error) {
+	contextURL, _ := ctx.Value(contextKey("baseURL")).(string)
+	page, err := parseReader(r, contextURL, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseURL := pageBaseURL(page, contextURL); baseURL != "" {
+		ctx = WithURL(ctx, baseURL)
+	}
+
+	items, _, err := scrapeParsed[T](ctx, page, config)
+	return items, err
+}
+
+// parseReader reads r and parses it with Config.Parser, HTML by default
+func parseReader(r io.Reader, pageURL string, config *Config) (*parsedPage, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: "failed to read input",
+			URL:     pageURL,
+			Cause:   err,
+		}
+	}
+	return parseBody(string(body), "", pageURL, config)
+}
+
+// pageBaseURL applies an HTML page's <base href> to pageURL; other pages keep pageURL
+func pageBaseURL(page *parsedPage, pageURL string) string {
+	if page.parser != ParserHTML {
+		return pageURL
+	}
+	return documentBaseURL(page.html, pageURL)
+}
+
+// documentBaseURL applies the document's <base href> to pageURL, as browsers do.
+// Returns pageURL unchanged if the document has no usable <base href>.
+func documentBaseURL(doc *html.Node, pageURL string) string {
+	node := htmlquery.FindOne(doc, "//head/base[@href]")
+	if node == nil {
+		return pageURL
+	}
+
+	href := strings.TrimSpace(htmlquery.SelectAttr(node, "href"))
+	ref, err := neturl.Parse(href)
+	if err != nil || href == "" {
+		return pageURL
+	}
+
+	if pageURL == "" {
+		if ref.IsAbs() {
+			return ref.String()
+		}
+		return ""
+	}
+
+	base, err := neturl.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	return base.ResolveReference(ref).String()
+}
@@ -0,0 +1,224 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type fileTestProduct struct {
+	Name string `json:"name"`
+	Link string `json:"link"`
+}
+
+const fileTestPage = `<html><head>%s</head><body>
+	<div class="product"><h2>%s</h2><a href="item">view</a></div>
+</body></html>`
+
+func fileTestHTML(base, name string) string {
+	return fmt.Sprintf(fileTestPage, base, name)
+}
+
+// TestScrapeReader_BaseHref tests that <base href> is resolved against the context URL
+func TestScrapeReader_BaseHref(t *testing.T) {
+	config := &Config{
+		Container: "//div[@class='product']",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: ".//h2/text()"},
+			"link": {XPath: ".//a/@href", Pipes: []string{"parseurl"}},
+		},
+	}
+
+	doc := fileTestHTML(`<base href="/shop/">`, "Alpha")
+	ctx := WithURL(context.Background(), "https://example.com/index.html")
+
+	items, err := ScrapeReader[fileTestProduct](ctx, strings.NewReader(doc), config)
+	if err != nil {
+		t.Fatalf("ScrapeReader failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Link != "https://example.com/shop/item" {
+		t.Errorf("Unexpected items: %+v", items)
+	}
+}
+
+// TestScrapeReader_Parser tests that saved JSON and XML documents use Config.Parser
+func TestScrapeReader_Parser(t *testing.T) {
+	ctx := WithURL(context.Background(), "https://example.com/feed")
+	xmlConfig := &Config{
+		Parser:    ParserXML,
+		Container: "//item",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: "./title"},
+			"link": {XPath: "./link", Pipes: []string{"parseurl"}},
+		},
+	}
+	feed := `<rss><channel><item><title>Alpha</title><link>/alpha</link></item></channel></rss>`
+	items, err := ScrapeReader[fileTestProduct](ctx, strings.NewReader(feed), xmlConfig)
+	if err != nil {
+		t.Fatalf("ScrapeReader failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Name != "Alpha" || items[0].Link != "https://example.com/alpha" {
+		t.Errorf("Unexpected XML items: %+v", items)
+	}
+
+	jsonConfig := &Config{
+		Parser:    ParserJSON,
+		Container: "$.products[*]",
+		Fields:    map[string]FieldConfig{"name": {XPath: "$.name"}},
+	}
+	fsys := fstest.MapFS{"products.json": {Data: []byte(`{"products": [{"name": "Beta"}]}`)}}
+	results, err := ScrapeFS[fileTestProduct](context.Background(), fsys, "*.json", jsonConfig, nil)
+	if err != nil {
+		t.Fatalf("ScrapeFS failed: %v", err)
+	}
+	if results[0].Err != nil || len(results[0].Items) != 1 || results[0].Items[0].Name != "Beta" {
+		t.Errorf("Unexpected JSON result: %+v", results[0])
+	}
+}
+
+// TestScrapeFile tests scraping a saved page with an absolute <base href>
+func TestScrapeFile(t *testing.T) {
+	config := &Config{
+		Container: "//div[@class='product']",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: ".//h2/text()"},
+			"link": {XPath: ".//a/@href", Pipes: []string{"parseurl"}},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "page.html")
+	if err := os.WriteFile(path, []byte(fileTestHTML(`<base href="https://saved.example/a/">`, "Saved")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := ScrapeFile[fileTestProduct](context.Background(), path, config)
+	if err != nil {
+		t.Fatalf("ScrapeFile failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Name != "Saved" || items[0].Link != "https://saved.example/a/item" {
+		t.Errorf("Unexpected items: %+v", items)
+	}
+
+	if _, err := ScrapeFile[fileTestProduct](context.Background(), filepath.Join(t.TempDir(), "missing.html"), config); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+// TestScrapeFS tests batch scraping with per-file base URLs and source tagging
+func TestScrapeFS(t *testing.T) {
+	config := &Config{
+		Container: "//div[@class='product']",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: ".//h2/text()"},
+			"link": {XPath: ".//a/@href", Pipes: []string{"parseurl"}},
+		},
+	}
+
+	fsys := fstest.MapFS{
+		"pages/a.html":   {Data: []byte(fileTestHTML("", "A"))},
+		"pages/b.html":   {Data: []byte(fileTestHTML("", "B"))},
+		"pages/c.html":   {Data: []byte(fileTestHTML(`<base href="https://c.example/">`, "C"))},
+		"pages/skip.txt": {Data: []byte("not html")},
+	}
+
+	options := &FileOptions{
+		BaseURLs: map[string]string{"pages/a.html": "https://a.example/x/"},
+		BaseURLFunc: func(path string) string {
+			return "https://fallback.example/" + filepath.Base(path)
+		},
+		Concurrency: 2,
+	}
+
+	results, err := ScrapeFS[fileTestProduct](context.Background(), fsys, "pages/*.html", config, options)
+	if err != nil {
+		t.Fatalf("ScrapeFS failed: %v", err)
+	}
+
+	want := []struct {
+		path, baseURL, link string
+	}{
+		{"pages/a.html", "https://a.example/x/", "https://a.example/x/item"},
+		{"pages/b.html", "https://fallback.example/b.html", "https://fallback.example/item"},
+		{"pages/c.html", "https://c.example/", "https://c.example/item"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Err != nil {
+			t.Errorf("%s: unexpected error %v", r.Path, r.Err)
+			continue
+		}
+		if r.Path != w.path || r.BaseURL != w.baseURL {
+			t.Errorf("Result %d: got path %q base %q, want %q %q", i, r.Path, r.BaseURL, w.path, w.baseURL)
+		}
+		if len(r.Items) != 1 || r.Items[0].Link != w.link {
+			t.Errorf("%s: unexpected items %+v", r.Path, r.Items)
+		}
+	}
+}
+
+// TestScrapeFS_PerFileErrors tests that extraction failures are reported per file
+func TestScrapeFS_PerFileErrors(t *testing.T) {
+	config := &Config{
+		Container: "//div[@class='product']",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: ".//h2/text()"},
+			"link": {XPath: ".//a/@href", Pipes: []string{"parseurl"}},
+		},
+	}
+
+	fsys := fstest.MapFS{
+		"ok.html":     {Data: []byte(fileTestHTML("", "OK"))},
+		"nobase.html": {Data: []byte(fileTestHTML("", "NoBase"))},
+	}
+
+	// Without any base URL parseurl fails for nobase.html only
+	options := &FileOptions{BaseURLs: map[string]string{"ok.html": "https://ok.example/"}}
+	results, err := ScrapeFS[fileTestProduct](context.Background(), fsys, "*.html", config, options)
+	if err != nil {
+		t.Fatalf("ScrapeFS failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Path != "nobase.html" || results[0].Err == nil {
+		t.Errorf("Expected nobase.html to fail, got %+v", results[0])
+	}
+	if results[1].Path != "ok.html" || results[1].Err != nil || len(results[1].Items) != 1 {
+		t.Errorf("Expected ok.html to succeed, got %+v", results[1])
+	}
+
+	if _, err := ScrapeFS[fileTestProduct](context.Background(), fsys, "[", config, nil); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+// TestScrapeDir tests the os directory wrapper
+func TestScrapeDir(t *testing.T) {
+	config := &Config{
+		Container: "//div[@class='product']",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: ".//h2/text()"},
+			"link": {XPath: ".//a/@href", Pipes: []string{"parseurl"}},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "one.html"), []byte(fileTestHTML(`<base href="https://d.example/">`, "One")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ScrapeDir[fileTestProduct](context.Background(), dir, "*.html", config, nil)
+	if err != nil {
+		t.Fatalf("ScrapeDir failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Err != nil || results[0].Items[0].Name != "One" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
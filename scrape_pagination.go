@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,27 +20,71 @@ const (
 	DefaultPaginationTimeout = 10 * time.Minute
 )
 
-// ScrapeURLWithPages fetches a URL and scrapes it with pagination, returning page-separated results
+// ScrapeURLWithPages fetches a URL and scrapes it with pagination, returning page-separated results.
+// If pagination fails, the pages scraped so far are returned alongside the *PaginationError.
 func ScrapeURLWithPages[T any](ctx context.Context, url string, config *Config) (*PaginatedResults[T], error) {
 	if config.Pagination == nil {
 		// No pagination config, scrape single page
-		items, err := ScrapeURL[T](ctx, url, config)
+		page, err := scrapeSinglePage[T](ctx, url, config)
 		if err != nil {
 			return nil, err
 		}
 		return &PaginatedResults[T]{
-			Pages: []PageResult[T]{{
-				URL:       url,
-				PageNum:   1,
-				Items:     items,
-				ScrapedAt: time.Now(),
-			}},
+			Pages:      []PageResult[T]{*page},
 			TotalPages: 1,
-			TotalItems: len(items),
+			TotalItems: len(page.Items),
 		}, nil
 	}
 
-	return scrapeWithPagination[T](ctx, url, config, true)
+	return scrapeWithPagination[T](ctx, url, config, nil)
+}
+
+// ScrapeURLFunc scrapes a URL like ScrapeURLWithPages but hands each page to fn as soon as
+// it is scraped instead of holding every item in memory. The returned results list the
+// pages and errors without their items. An error from fn stops pagination and is returned.
+func ScrapeURLFunc[T any](ctx context.Context, url string, config *Config, fn func(PageResult[T]) error) (*PaginatedResults[T], error) {
+	if config.Pagination == nil {
+		page, err := scrapeSinglePage[T](ctx, url, config)
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(*page); err != nil {
+			return nil, err
+		}
+		total := len(page.Items)
+		page.Items = nil
+		return &PaginatedResults[T]{
+			Pages:      []PageResult[T]{*page},
+			TotalPages: 1,
+			TotalItems: total,
+		}, nil
+	}
+
+	return scrapeWithPagination[T](ctx, url, config, fn)
+}
+
+// scrapeSinglePage fetches and scrapes one page without following pagination
+func scrapeSinglePage[T any](ctx context.Context, url string, config *Config) (*PageResult[T], error) {
+	_, page, err := fetchParsed(newGetRequest(url).withContext(ctx), config)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	items, document, err := scrapeParsed[T](withFetchConfig(WithURL(ctx, url), config), page, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PageResult[T]{
+		URL:       url,
+		PageNum:   1,
+		Items:     items,
+		Document:  document,
+		ScrapedAt: time.Now(),
+	}, nil
 }
 
 // ExtractPaginationURLs extracts all pagination URLs without scraping
@@ -51,25 +96,23 @@ func ExtractPaginationURLs(ctx context.Context, url string, config *Config) (*Pa
 		}
 	}
 
-	// Fetch first page
-	htmlContent, err := fetchHTML(url, config)
-	if err != nil {
-		return nil, err
+	if config.Pagination.Type == "form" {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "form pagination submits requests and has no page URLs to extract",
+		}
 	}
 
-	doc, err := htmlquery.Parse(strings.NewReader(htmlContent))
+	// Fetch first page
+	page, doc, err := fetchDocument(url, config)
 	if err != nil {
-		return nil, &ScrapeError{
-			Type:    ErrTypeParsing,
-			Message: "failed to parse HTML",
-			Cause:   err,
-		}
+		return nil, err
 	}
 
 	var urls []string
 	switch config.Pagination.Type {
-	case "next-link":
-		urls, err = extractNextLinkChain(ctx, url, doc, config)
+	case "next-link", "link-header", "json":
+		urls, err = extractNextLinkChain(ctx, page, doc, config)
 	case "numbered":
 		urls, err = extractNumberedPages(ctx, url, doc, config)
 	default:
@@ -90,152 +133,236 @@ func ExtractPaginationURLs(ctx context.Context, url string, config *Config) (*Pa
 	}, nil
 }
 
-// scrapeWithPagination handles pagination logic for auto-follow mode
-func scrapeWithPagination[T any](ctx context.Context, startURL string, config *Config, separatePages bool) (*PaginatedResults[T], error) {
+// scrapeWithPagination handles pagination logic for auto-follow mode.
+// The returned results are never nil, so callers can use the pages scraped
+// before a failure. If onPage is set, each page is passed to it and its items
+// are not kept in the results.
+func scrapeWithPagination[T any](ctx context.Context, startURL string, config *Config, onPage func(PageResult[T]) error) (*PaginatedResults[T], error) {
+	results := &PaginatedResults[T]{Pages: []PageResult[T]{}}
+
+	// Validate config once for all pages
+	if err := config.Validate(); err != nil {
+		return results, err
+	}
 	applyPaginationDefaults(config.Pagination)
+	ctx, dedup := withDedup(ctx, config)
 
-	var allPages []PageResult[T]
-	var allItems []T
 	visitedURLs := make(map[string]bool)
-	pageNum := 1
-	currentURL := startURL
+	pendingRequests := []*pageRequest{newGetRequest(startURL)}
+	pageNum := 0
 	startTime := time.Now()
+	var lastErr *PaginationError
 
 	getLogger().Info("pagination starting",
 		"url", startURL,
 		"type", config.Pagination.Type,
-		"max_pages", config.Pagination.MaxPages)
+		"max_pages", config.Pagination.MaxPages,
+		"on_error", config.Pagination.OnError)
+
+	for len(pendingRequests) > 0 {
+		currentRequest := pendingRequests[0]
+		pendingRequests = pendingRequests[1:]
+		currentURL := currentRequest.URL
 
-	for {
 		// Check timeout
 		if time.Since(startTime) > config.Pagination.Timeout {
 			getLogger().Warn("pagination timeout exceeded",
 				"timeout", config.Pagination.Timeout,
 				"elapsed", time.Since(startTime),
-				"pages_scraped", pageNum-1)
+				"pages_scraped", pageNum)
 			break
 		}
 
 		// Check max pages
-		if pageNum > config.Pagination.MaxPages {
+		if pageNum >= config.Pagination.MaxPages {
 			getLogger().Warn("pagination max pages reached",
 				"max_pages", config.Pagination.MaxPages,
-				"total_items", len(allItems))
+				"total_items", results.TotalItems)
 			break
 		}
 
 		// Mark URL as visited
-		normalized := normalizeURL(currentURL)
-		if visitedURLs[normalized] {
+		requestKey := currentRequest.key()
+		if visitedURLs[requestKey] {
 			getLogger().Warn("pagination duplicate url",
 				"url", currentURL,
-				"page", pageNum)
-			break
+				"page", pageNum+1)
+			continue
 		}
-		visitedURLs[normalized] = true
+		visitedURLs[requestKey] = true
+		pageNum++
 
-		// Scrape current page
-		ctx = WithURL(ctx, currentURL)
-		items, err := scrapeCurrentPage[T](ctx, currentURL, config)
+		// Scrape current page and discover the following ones
+		// Seen IDs are committed only once the page is delivered
+		pageCtx, dedupPage := withDedupPage(WithURL(ctx, currentURL), config)
+		items, document, nextRequests, err := scrapePaginatedPage[T](pageCtx, currentRequest, pageNum, config)
 		if err != nil {
-			// Return error with partial data
-			return nil, &PaginationError{
+			dedupPage.release()
+			pagErr := &PaginationError{
 				PageURL:      currentURL,
 				PageNumber:   pageNum,
-				PartialData:  allItems,
-				TotalScraped: len(allItems),
+				PartialData:  results.Items(),
+				TotalScraped: results.TotalItems,
 				Cause:        err,
 			}
+			// The start page always fails the scrape: no other page can be discovered without it
+			if config.Pagination.OnError != OnErrorSkip || pageNum == 1 {
+				// Return error with partial data
+				return results, pagErr
+			}
+
+			getLogger().Warn("pagination page skipped",
+				"page", pageNum,
+				"url", currentURL,
+				"error", err.Error())
+			results.Errors = append(results.Errors, pagErr)
+			lastErr = pagErr
+			continue
 		}
+		lastErr = nil
 
 		// Log progress
 		getLogger().Info("pagination page scraped",
 			"page", pageNum,
 			"items", len(items),
-			"total_items", len(allItems)+len(items),
+			"total_items", results.TotalItems+len(items),
 			"url", currentURL)
 
-		// Store results
-		pageResult := PageResult[T]{
+		// Store results, or stream them
+		page := PageResult[T]{
 			URL:       currentURL,
 			PageNum:   pageNum,
 			Items:     items,
+			Document:  document,
 			ScrapedAt: time.Now(),
 		}
-		allPages = append(allPages, pageResult)
-		allItems = append(allItems, items...)
-
-		// Get next URL
-		nextURL, err := getNextPageURL(ctx, currentURL, config)
-		if err != nil {
-			return nil, &PaginationError{
-				PageURL:      currentURL,
-				PageNumber:   pageNum,
-				PartialData:  allItems,
-				TotalScraped: len(allItems),
-				Cause:        err,
+		if onPage != nil {
+			if err := onPage(page); err != nil {
+				dedupPage.release()
+				return results, err
 			}
+			page.Items = nil
 		}
+		if err := dedupPage.commit(); err != nil {
+			return results, err
+		}
+		results.Pages = append(results.Pages, page)
+		results.TotalPages = len(results.Pages)
+		results.TotalItems += len(items)
+		results.Duplicates = dedup.count()
 
-		if nextURL == "" {
-			// No more pages
-			getLogger().Info("pagination complete",
-				"reason", "no_next_link",
-				"pages", len(allPages))
-			break
+		if len(nextRequests) > 0 {
+			getLogger().Info("pagination following next link",
+				"url", nextRequests[0].URL,
+				"method", nextRequests[0].Method,
+				"page", pageNum+1)
 		}
+		pendingRequests = append(pendingRequests, nextRequests...)
+	}
 
-		getLogger().Info("pagination following next link",
-			"url", nextURL,
-			"page", pageNum+1)
-		currentURL = nextURL
-		pageNum++
+	// Chained pagination cannot continue past a failed page, so a chain
+	// that ends at one did not reach its last page
+	if lastErr != nil && config.Pagination.Type != "numbered" {
+		getLogger().Warn("pagination chain ended at failed page",
+			"page", lastErr.PageNumber,
+			"url", lastErr.PageURL)
+		return results, lastErr
 	}
 
 	getLogger().Info("pagination complete",
-		"pages", len(allPages),
-		"total_items", len(allItems),
+		"pages", results.TotalPages,
+		"skipped", len(results.Errors),
+		"total_items", results.TotalItems,
+		"duplicates", results.Duplicates,
 		"duration", time.Since(startTime).String())
 
-	return &PaginatedResults[T]{
-		Pages:      allPages,
-		TotalPages: len(allPages),
-		TotalItems: len(allItems),
-	}, nil
+	return results, nil
 }
 
-// scrapeCurrentPage scrapes a single page without pagination logic
-func scrapeCurrentPage[T any](ctx context.Context, url string, config *Config) ([]T, error) {
-	htmlContent, err := fetchHTML(url, config)
+// scrapePaginatedPage fetches and scrapes a single page, returning its items,
+// its document fields and the requests for any newly discovered pages
+func scrapePaginatedPage[T any](ctx context.Context, pageReq *pageRequest, pageNum int, config *Config) ([]T, map[string]any, []*pageRequest, error) {
+	page, parsed, err := fetchParsed(pageReq.withContext(ctx), config)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	return Scrape[T](ctx, htmlContent, config)
-}
 
-// getNextPageURL extracts the next page URL based on pagination type
-func getNextPageURL(ctx context.Context, currentURL string, config *Config) (string, error) {
-	htmlContent, err := fetchHTML(currentURL, config)
+	items, document, err := scrapeParsed[T](withFetchConfig(ctx, config), parsed, config)
 	if err != nil {
-		return "", err
+		return nil, nil, nil, err
 	}
 
-	doc, err := htmlquery.Parse(strings.NewReader(htmlContent))
+	nextRequests, err := getNextPageRequests(ctx, page, parsed, pageNum, config)
 	if err != nil {
-		return "", &ScrapeError{
-			Type:    ErrTypeParsing,
-			Message: "failed to parse HTML",
-			Cause:   err,
+		return nil, nil, nil, err
+	}
+
+	return items, document, nextRequests, nil
+}
+
+// getNextPageRequests extracts the page requests to perform after the current page based on pagination type
+func getNextPageRequests(ctx context.Context, page *fetchedPage, parsed *parsedPage, pageNum int, config *Config) ([]*pageRequest, error) {
+	doc := parsed.html
+	if doc == nil && !slices.Contains(parserPaginationTypes[parsed.parser], config.Pagination.Type) {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("%s pagination requires an HTML page", config.Pagination.Type),
+			URL:     page.URL,
 		}
 	}
 
+	// XML pages follow next links found with namespace-aware XPaths
+	if parsed.parser == ParserXML && config.Pagination.Type == "next-link" {
+		nextURL, err := extractXMLNextURL(ctx, page.URL, parsed.xml, config)
+		if err != nil || nextURL == "" {
+			return nil, err
+		}
+		return []*pageRequest{newGetRequest(nextURL)}, nil
+	}
+
 	switch config.Pagination.Type {
-	case "next-link":
-		return extractNextURL(ctx, currentURL, doc, config)
 	case "numbered":
-		// For numbered pagination in auto-follow, we don't use getNextPageURL
-		// Instead, we extract all URLs upfront
-		return "", nil
+		// All page links are extracted upfront from the first page
+		if pageNum > 1 {
+			return nil, nil
+		}
+		urls, err := extractNumberedPages(ctx, page.URL, doc, config)
+		if err != nil {
+			return nil, err
+		}
+		requests := make([]*pageRequest, 0, len(urls))
+		for _, u := range urls {
+			// Skip the current page, which inferred ranges list as page 1
+			if normalizeURL(u) == normalizeURL(page.URL) {
+				continue
+			}
+			requests = append(requests, newGetRequest(u))
+		}
+		return requests, nil
+	case "form":
+		nextRequest, err := extractFormRequest(ctx, page, doc, pageNum+1, config)
+		if err != nil || nextRequest == nil {
+			return nil, err
+		}
+		return []*pageRequest{nextRequest}, nil
+	}
+
+	nextURL, err := getNextPageURL(ctx, page, doc, config)
+	if err != nil || nextURL == "" {
+		return nil, err
+	}
+	return []*pageRequest{newGetRequest(nextURL)}, nil
+}
+
+// getNextPageURL extracts the single next page URL for chained pagination types
+func getNextPageURL(ctx context.Context, page *fetchedPage, doc *html.Node, config *Config) (string, error) {
+	switch config.Pagination.Type {
+	case "next-link":
+		return extractNextURL(ctx, page.URL, doc, config)
+	case "link-header":
+		return extractLinkHeaderURL(ctx, page, config)
+	case "json":
+		return extractJSONNextURL(ctx, page, config)
 	default:
 		return "", &ScrapeError{
 			Type:    ErrTypeConfig,
@@ -246,29 +373,36 @@ func getNextPageURL(ctx context.Context, currentURL string, config *Config) (str
 
 // extractNextURL extracts the next page URL using NextSelector and AltSelectors
 func extractNextURL(ctx context.Context, baseURL string, doc *html.Node, config *Config) (string, error) {
-	selectors := []string{config.Pagination.NextSelector}
-	selectors = append(selectors, config.Pagination.AltSelectors...)
-
-	for _, selector := range selectors {
-		if selector == "" {
-			continue
-		}
-
+	return findNextURL(ctx, baseURL, config, func(selector string) string {
 		// Compile XPath
-		expr, err := xpath.Compile(selector)
+		expr, err := compileSelector(selector)
 		if err != nil {
-			continue // Try next selector
+			return "" // Try next selector
 		}
 
 		// Evaluate XPath
 		nodeIterator := expr.Evaluate(htmlquery.CreateXPathNavigator(doc)).(*xpath.NodeIterator)
 		if !nodeIterator.MoveNext() {
-			continue // Try next selector
+			return "" // Try next selector
 		}
 
 		navigator := nodeIterator.Current().(*htmlquery.NodeNavigator)
-		rawURL := navigator.Value()
+		return navigator.Value()
+	})
+}
 
+// findNextURL returns the first next page URL found by NextSelector and AltSelectors,
+// using lookup to read the raw value of each selector
+func findNextURL(ctx context.Context, baseURL string, config *Config, lookup func(selector string) string) (string, error) {
+	selectors := []string{config.Pagination.NextSelector}
+	selectors = append(selectors, config.Pagination.AltSelectors...)
+
+	for _, selector := range selectors {
+		if selector == "" {
+			continue
+		}
+
+		rawURL := lookup(selector)
 		if rawURL == "" {
 			continue
 		}
@@ -296,8 +430,17 @@ func extractNextURL(ctx context.Context, baseURL string, doc *html.Node, config
 	return "", nil
 }
 
-// extractNumberedPages extracts all page URLs for numbered pagination
+// extractNumberedPages extracts all page URLs for numbered pagination.
+// With a lastPageSelector the full page range is inferred instead of using only visible links.
 func extractNumberedPages(ctx context.Context, baseURL string, doc *html.Node, config *Config) ([]string, error) {
+	if config.Pagination.LastPageSelector != "" {
+		return inferNumberedPages(ctx, baseURL, doc, config)
+	}
+	return extractPageLinks(ctx, baseURL, doc, config)
+}
+
+// extractPageLinks extracts the page URLs linked from the pager
+func extractPageLinks(ctx context.Context, baseURL string, doc *html.Node, config *Config) ([]string, error) {
 	if config.Pagination.PageSelector == "" {
 		return nil, &ScrapeError{
 			Type:    ErrTypeConfig,
@@ -306,7 +449,7 @@ func extractNumberedPages(ctx context.Context, baseURL string, doc *html.Node, c
 	}
 
 	// Compile XPath
-	expr, err := xpath.Compile(config.Pagination.PageSelector)
+	expr, err := compileSelector(config.Pagination.PageSelector)
 	if err != nil {
 		return nil, &ScrapeError{
 			Type:    ErrTypeXPath,
@@ -360,10 +503,11 @@ func extractNumberedPages(ctx context.Context, baseURL string, doc *html.Node, c
 }
 
 // extractNextLinkChain follows next links to build a list of all page URLs
-func extractNextLinkChain(ctx context.Context, startURL string, doc *html.Node, config *Config) ([]string, error) {
+func extractNextLinkChain(ctx context.Context, startPage *fetchedPage, doc *html.Node, config *Config) ([]string, error) {
 	var urls []string
 	visitedURLs := make(map[string]bool)
-	currentURL := startURL
+	currentURL := startPage.URL
+	currentPage := startPage
 	currentDoc := doc
 	pageCount := 0
 
@@ -384,18 +528,13 @@ func extractNextLinkChain(ctx context.Context, startURL string, doc *html.Node,
 		pageCount++
 
 		// Get next URL
-		nextURL, err := extractNextURL(ctx, currentURL, currentDoc, config)
+		nextURL, err := getNextPageURL(ctx, currentPage, currentDoc, config)
 		if err != nil || nextURL == "" {
 			break
 		}
 
 		// Fetch next page
-		htmlContent, err := fetchHTML(nextURL, config)
-		if err != nil {
-			break
-		}
-
-		currentDoc, err = htmlquery.Parse(strings.NewReader(htmlContent))
+		currentPage, currentDoc, err = fetchDocument(nextURL, config)
 		if err != nil {
 			break
 		}
@@ -0,0 +1,135 @@
+package gtmlp
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// extractLinkHeaderURL reads the next page URL from RFC 8288 Link response headers
+func extractLinkHeaderURL(ctx context.Context, page *fetchedPage, config *Config) (string, error) {
+	rel := config.Pagination.LinkRel
+	if rel == "" {
+		rel = "next"
+	}
+
+	rawURL := parseLinkHeader(page.Header.Values("Link"))[strings.ToLower(rel)]
+	if rawURL == "" {
+		return "", nil
+	}
+
+	return resolvePaginationURL(ctx, page.URL, rawURL, config)
+}
+
+// extractJSONNextURL reads the next page URL or cursor from a JSON path in the response body
+func extractJSONNextURL(ctx context.Context, page *fetchedPage, config *Config) (string, error) {
+	data, err := parseJSON(page.Body)
+	if err != nil {
+		return "", &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: "failed to parse JSON response for pagination",
+			URL:     page.URL,
+			Cause:   err,
+		}
+	}
+
+	value, ok := lookupJSONPath(data, config.Pagination.JSONPath)
+	if !ok {
+		return "", nil
+	}
+
+	raw := strings.TrimSpace(jsonValueString(value))
+	if raw == "" || raw == "false" {
+		return "", nil
+	}
+
+	// Cursor mode: set the cursor as a query parameter on the current URL
+	if config.Pagination.CursorParam != "" {
+		u, err := url.Parse(page.URL)
+		if err != nil {
+			return "", err
+		}
+		query := u.Query()
+		query.Set(config.Pagination.CursorParam, raw)
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	return resolvePaginationURL(ctx, page.URL, raw, config)
+}
+
+// resolvePaginationURL applies pagination pipes and resolves the URL against the current page.
+// Returns an empty string if the URL is empty after pipes or cannot be resolved.
+func resolvePaginationURL(ctx context.Context, baseURL, rawURL string, config *Config) (string, error) {
+	processedURL, err := applyPipesToURL(ctx, rawURL, config.Pagination.Pipes)
+	if err != nil || processedURL == "" {
+		return "", nil
+	}
+
+	absoluteURL, err := resolveURL(baseURL, processedURL)
+	if err != nil {
+		return "", nil
+	}
+
+	return absoluteURL, nil
+}
+
+// parseLinkHeader parses RFC 8288 Link header values into a rel → URL map.
+// A link with several space-separated relation types is registered under each of them,
+// and the first link wins when a relation type appears more than once.
+func parseLinkHeader(values []string) map[string]string {
+	links := make(map[string]string)
+
+	for _, value := range values {
+		for _, link := range splitLinkValues(value) {
+			link = strings.TrimSpace(link)
+			if !strings.HasPrefix(link, "<") {
+				continue
+			}
+			end := strings.Index(link, ">")
+			if end < 0 {
+				continue
+			}
+			target := link[1:end]
+
+			for _, param := range strings.Split(link[end+1:], ";") {
+				name, val, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				val = strings.Trim(strings.TrimSpace(val), `"`)
+				for _, rel := range strings.Fields(val) {
+					rel = strings.ToLower(rel)
+					if _, exists := links[rel]; !exists {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+
+	return links
+}
+
+// splitLinkValues splits a Link header value on commas that are outside <...> and quotes
+func splitLinkValues(value string) []string {
+	var parts []string
+	inURL, inQuote := false, false
+	start := 0
+
+	for i, r := range value {
+		switch {
+		case r == '<' && !inQuote:
+			inURL = true
+		case r == '>' && !inQuote:
+			inURL = false
+		case r == '"' && !inURL:
+			inQuote = !inQuote
+		case r == ',' && !inURL && !inQuote:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
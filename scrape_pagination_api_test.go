@@ -0,0 +1,169 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestParseLinkHeader tests RFC 8288 Link header parsing
+func TestParseLinkHeader(t *testing.T) {
+	links := parseLinkHeader([]string{
+		`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=9>; rel="last"`,
+		`</items?page=1>; rel="first prev"; title="a, b"`,
+	})
+
+	expected := map[string]string{
+		"next":  "https://api.example.com/items?page=2",
+		"last":  "https://api.example.com/items?page=9",
+		"first": "/items?page=1",
+		"prev":  "/items?page=1",
+	}
+
+	for rel, want := range expected {
+		if links[rel] != want {
+			t.Errorf("links[%s] = %s, expected %s", rel, links[rel], want)
+		}
+	}
+}
+
+// TestScrapeURLWithPages_LinkHeader tests following Link response headers
+func TestScrapeURLWithPages_LinkHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `</products?page=2>; rel="next"`)
+			w.Write([]byte(testHTMLPage3NoNext))
+		case "2":
+			w.Write([]byte(testHTMLPage3NoNext))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type: "link-header",
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	results, err := ScrapeURLWithPages[map[string]any](context.Background(), server.URL+"/products", config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+
+	if results.TotalPages != 2 {
+		t.Errorf("Expected 2 pages, got %d", results.TotalPages)
+	}
+	if results.Pages[1].URL != server.URL+"/products?page=2" {
+		t.Errorf("Expected second page URL from Link header, got %s", results.Pages[1].URL)
+	}
+}
+
+// TestExtractPaginationURLs_JSONCursor tests cursor pagination from a JSON body
+func TestExtractPaginationURLs_JSONCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"items": [], "meta": {"next_cursor": "abc"}}`)
+		case "abc":
+			fmt.Fprint(w, `{"items": [], "meta": {"next_cursor": "def"}}`)
+		default:
+			fmt.Fprint(w, `{"items": [], "meta": {"next_cursor": null}}`)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:        "json",
+			JSONPath:    "meta.next_cursor",
+			CursorParam: "cursor",
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	info, err := ExtractPaginationURLs(context.Background(), server.URL+"/api/items?limit=10", config)
+	if err != nil {
+		t.Fatalf("ExtractPaginationURLs failed: %v", err)
+	}
+
+	expected := []string{
+		server.URL + "/api/items?limit=10",
+		server.URL + "/api/items?cursor=abc&limit=10",
+		server.URL + "/api/items?cursor=def&limit=10",
+	}
+	if len(info.URLs) != len(expected) {
+		t.Fatalf("Expected %d URLs, got %d: %v", len(expected), len(info.URLs), info.URLs)
+	}
+	for i, want := range expected {
+		if info.URLs[i] != want {
+			t.Errorf("URLs[%d] = %s, expected %s", i, info.URLs[i], want)
+		}
+	}
+}
+
+// TestExtractPaginationURLs_JSONNextURL tests reading a next URL from a JSON body
+func TestExtractPaginationURLs_JSONNextURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprint(w, `{"links": {"next": "/api/items?page=2"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"links": {}}`)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:     "json",
+			JSONPath: "$.links.next",
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	info, err := ExtractPaginationURLs(context.Background(), server.URL+"/api/items", config)
+	if err != nil {
+		t.Fatalf("ExtractPaginationURLs failed: %v", err)
+	}
+
+	if len(info.URLs) != 2 || info.URLs[1] != server.URL+"/api/items?page=2" {
+		t.Errorf("Unexpected URLs: %v", info.URLs)
+	}
+}
+
+// TestValidatePaginationConfig_JSON tests json pagination validation
+func TestValidatePaginationConfig_JSON(t *testing.T) {
+	if err := validatePaginationConfig(&PaginationConfig{Type: "json"}); err == nil {
+		t.Error("Expected error for missing jsonPath, got nil")
+	}
+	if err := validatePaginationConfig(&PaginationConfig{Type: "json", JSONPath: "meta.next"}); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	if err := validatePaginationConfig(&PaginationConfig{Type: "link-header"}); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package gtmlp
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// formBoundary is a fixed multipart boundary so identical form submissions
+// produce identical request bodies
+const formBoundary = "gtmlp-form-boundary-7MA4YWxkTrZu0gW"
+
+// doPostBackPattern matches ASP.NET postback links like javascript:__doPostBack('ctl00$grid','Page$2')
+var doPostBackPattern = regexp.MustCompile(`__doPostBack\(\s*['"]([^'"]*)['"]\s*,\s*['"]([^'"]*)['"]\s*\)`)
+
+// extractFormRequest builds the request that submits the pagination form for the next page.
+// Returns nil if there is no next page.
+func extractFormRequest(ctx context.Context, page *fetchedPage, doc *html.Node, nextPageNum int, config *Config) (*pageRequest, error) {
+	p := config.Pagination
+	var eventTarget, eventArgument string
+
+	// Stop when the next-page control is gone
+	if p.NextSelector != "" {
+		selectors := append([]string{p.NextSelector}, p.AltSelectors...)
+		value, found := findFirstValue(doc, selectors)
+		if !found {
+			getLogger().Info("pagination form next control not found",
+				"url", page.URL,
+				"selector", p.NextSelector)
+			return nil, nil
+		}
+
+		// Carry over postback arguments from javascript:__doPostBack(...) links
+		if m := doPostBackPattern.FindStringSubmatch(value); m != nil {
+			eventTarget, eventArgument = m[1], m[2]
+		}
+	}
+
+	expr, err := compileSelector(p.FormSelector)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeXPath,
+			Message: "invalid formSelector",
+			XPath:   p.FormSelector,
+			Cause:   err,
+		}
+	}
+
+	formNode := htmlquery.QuerySelector(doc, expr)
+	if formNode == nil {
+		getLogger().Warn("pagination form not found",
+			"url", page.URL,
+			"selector", p.FormSelector)
+		return nil, nil
+	}
+
+	// Carry over the form's current values, including __VIEWSTATE and __EVENTVALIDATION
+	fields := formValues(formNode)
+
+	// The page is identified by what changes between submissions, not by
+	// state the server rotates on every response
+	identity := url.Values{}
+	if eventTarget != "" {
+		identity.Set("__EVENTTARGET", eventTarget)
+		identity.Set("__EVENTARGUMENT", eventArgument)
+	}
+
+	// Apply overrides, replacing {page} with the next page number
+	for name, value := range p.FormFields {
+		identity.Set(name, strings.ReplaceAll(value, "{page}", strconv.Itoa(nextPageNum)))
+	}
+	for name, values := range identity {
+		fields[name] = values
+	}
+
+	return buildFormRequest(page.URL, formNode, fields, identity)
+}
+
+// formValues collects the values a browser would submit for the form:
+// enabled inputs except buttons and files, checked checkboxes and radios,
+// textareas and selected options
+func formValues(formNode *html.Node) url.Values {
+	fields := url.Values{}
+	for _, control := range htmlquery.Find(formNode, ".//*[self::input or self::textarea or self::select][@name]") {
+		if htmlquery.ExistsAttr(control, "disabled") {
+			continue
+		}
+		name := htmlquery.SelectAttr(control, "name")
+
+		switch control.Data {
+		case "textarea":
+			fields.Add(name, htmlquery.InnerText(control))
+		case "select":
+			for _, value := range selectedOptions(control) {
+				fields.Add(name, value)
+			}
+		default:
+			switch strings.ToLower(htmlquery.SelectAttr(control, "type")) {
+			case "submit", "button", "image", "reset", "file":
+				continue
+			case "checkbox", "radio":
+				if !htmlquery.ExistsAttr(control, "checked") {
+					continue
+				}
+				value := htmlquery.SelectAttr(control, "value")
+				if !htmlquery.ExistsAttr(control, "value") {
+					value = "on"
+				}
+				fields.Add(name, value)
+			default:
+				fields.Add(name, htmlquery.SelectAttr(control, "value"))
+			}
+		}
+	}
+	return fields
+}
+
+// selectedOptions returns the values of a select's selected options,
+// falling back to the first option for single selects
+func selectedOptions(selectNode *html.Node) []string {
+	options := htmlquery.Find(selectNode, ".//option")
+	var values []string
+	for _, option := range options {
+		if htmlquery.ExistsAttr(option, "selected") {
+			values = append(values, optionValue(option))
+		}
+	}
+	if len(values) == 0 && len(options) > 0 && !htmlquery.ExistsAttr(selectNode, "multiple") {
+		values = append(values, optionValue(options[0]))
+	}
+	return values
+}
+
+// optionValue returns an option's value attribute, or its text when absent
+func optionValue(option *html.Node) string {
+	if htmlquery.ExistsAttr(option, "value") {
+		return htmlquery.SelectAttr(option, "value")
+	}
+	return strings.TrimSpace(htmlquery.InnerText(option))
+}
+
+// buildFormRequest encodes form fields according to the form's action, method and enctype.
+// identity holds the fields that tell pages apart for visited tracking.
+func buildFormRequest(pageURL string, formNode *html.Node, fields, identity url.Values) (*pageRequest, error) {
+	action := strings.TrimSpace(htmlquery.SelectAttr(formNode, "action"))
+	actionURL := pageURL
+	if action != "" {
+		resolved, err := resolveURL(pageURL, action)
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeParsing,
+				Message: "invalid form action",
+				URL:     pageURL,
+				Cause:   err,
+			}
+		}
+		actionURL = resolved
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(htmlquery.SelectAttr(formNode, "method")))
+	if method != http.MethodPost {
+		// Forms default to GET with fields in the query string
+		u, err := url.Parse(actionURL)
+		if err != nil {
+			return nil, err
+		}
+		u.RawQuery = fields.Encode()
+		req := newGetRequest(u.String())
+		req.visitKey = formVisitKey(http.MethodGet, actionURL, identity)
+		return req, nil
+	}
+	visitKey := formVisitKey(http.MethodPost, actionURL, identity)
+
+	enctype := strings.ToLower(strings.TrimSpace(htmlquery.SelectAttr(formNode, "enctype")))
+	if enctype == "multipart/form-data" {
+		body, contentType, err := encodeMultipartForm(fields)
+		if err != nil {
+			return nil, err
+		}
+		return &pageRequest{
+			Method:      http.MethodPost,
+			URL:         actionURL,
+			Body:        body,
+			ContentType: contentType,
+			visitKey:    visitKey,
+		}, nil
+	}
+
+	return &pageRequest{
+		Method:      http.MethodPost,
+		URL:         actionURL,
+		Body:        fields.Encode(),
+		ContentType: "application/x-www-form-urlencoded",
+		visitKey:    visitKey,
+	}, nil
+}
+
+// formVisitKey identifies a form submission by method, action and identity fields
+func formVisitKey(method, actionURL string, identity url.Values) string {
+	return method + " " + normalizeURL(actionURL) + "\n" + identity.Encode()
+}
+
+// encodeMultipartForm encodes fields as multipart/form-data in sorted key order
+func encodeMultipartForm(fields url.Values) (string, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(formBoundary); err != nil {
+		return "", "", err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range fields[k] {
+			if err := writer.WriteField(k, v); err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", "", err
+	}
+
+	return buf.String(), writer.FormDataContentType(), nil
+}
+
+// findFirstValue returns the value of the first node matched by any of the selectors
+func findFirstValue(doc *html.Node, selectors []string) (string, bool) {
+	for _, selector := range selectors {
+		if selector == "" {
+			continue
+		}
+
+		expr, err := compileSelector(selector)
+		if err != nil {
+			continue // Try next selector
+		}
+
+		nodeIterator := expr.Evaluate(htmlquery.CreateXPathNavigator(doc)).(*xpath.NodeIterator)
+		if nodeIterator.MoveNext() {
+			return nodeIterator.Current().Value(), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,272 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// testFormPage renders an ASP.NET-style page with a postback pager
+func testFormPage(page int, hasNext bool) string {
+	next := ""
+	if hasNext {
+		next = fmt.Sprintf(`<a id="next" href="javascript:__doPostBack('ctl00$grid','Page$%d')">Next</a>`, page+1)
+	}
+	return fmt.Sprintf(`<html><body>
+  <form id="aspnetForm" method="post" action="./results.aspx">
+    <input type="hidden" name="__VIEWSTATE" value="state-%d" />
+    <input type="hidden" name="__EVENTTARGET" value="" />
+    <input type="text" name="q" value="shoes" />
+    <input type="submit" name="go" value="Search" />
+    <div class="product"><h2>Product %d</h2></div>
+    %s
+  </form>
+</body></html>`, page, page, next)
+}
+
+// TestScrapeURLWithPages_FormPostBack tests postback-style form pagination
+func TestScrapeURLWithPages_FormPostBack(t *testing.T) {
+	var postedStates []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.Method == http.MethodGet {
+			w.Write([]byte(testFormPage(1, true)))
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path != "/search/results.aspx" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.PostForm.Get("q") != "shoes" || r.PostForm.Has("go") {
+			http.Error(w, "unexpected form inputs", http.StatusBadRequest)
+			return
+		}
+		postedStates = append(postedStates, r.PostForm.Get("__VIEWSTATE"))
+
+		var page int
+		fmt.Sscanf(r.PostForm.Get("__EVENTARGUMENT"), "Page$%d", &page)
+		if r.PostForm.Get("__EVENTTARGET") != "ctl00$grid" || page == 0 {
+			http.Error(w, "missing postback arguments", http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("pageIndex") != fmt.Sprint(page) {
+			http.Error(w, "missing override", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(testFormPage(page, page < 3)))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:         "form",
+			FormSelector: `//form[@id="aspnetForm"]`,
+			NextSelector: `//a[@id="next"]/@href`,
+			FormFields:   map[string]string{"pageIndex": "{page}"},
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	type Product struct {
+		Name string `json:"name"`
+	}
+
+	results, err := ScrapeURLWithPages[Product](context.Background(), server.URL+"/search/results.aspx", config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+
+	if results.TotalPages != 3 {
+		t.Fatalf("Expected 3 pages, got %d", results.TotalPages)
+	}
+	for i, page := range results.Pages {
+		expected := fmt.Sprintf("Product %d", i+1)
+		if len(page.Items) != 1 || page.Items[0].Name != expected {
+			t.Errorf("Page %d: expected %s, got %v", i+1, expected, page.Items)
+		}
+	}
+
+	if strings.Join(postedStates, ",") != "state-1,state-2" {
+		t.Errorf("Expected hidden view state to be carried over, got %v", postedStates)
+	}
+}
+
+// TestScrapeURLWithPages_FormMaxPages tests MaxPages with form pagination without a next control
+func TestScrapeURLWithPages_FormMaxPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		r.ParseForm()
+		var page int
+		fmt.Sscanf(r.Form.Get("page"), "%d", &page)
+		if page == 0 {
+			page = 1
+		}
+		w.Write([]byte(testFormPage(page, false)))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:         "form",
+			FormSelector: `//form`,
+			FormFields:   map[string]string{"page": "{page}"},
+			MaxPages:     4,
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	results, err := ScrapeURLWithPages[map[string]any](context.Background(), server.URL+"/results.aspx", config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+
+	if results.TotalPages != 4 {
+		t.Errorf("Expected 4 pages, got %d", results.TotalPages)
+	}
+}
+
+// TestBuildFormRequest tests form encoding by method and enctype
+func TestBuildFormRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		form        string
+		method      string
+		url         string
+		contentType string
+	}{
+		{
+			name:   "get form",
+			form:   `<form action="/search"><input type="hidden" name="a" value="1"/></form>`,
+			method: http.MethodGet,
+			url:    "https://example.com/search?a=1",
+		},
+		{
+			name:        "urlencoded post",
+			form:        `<form method="POST"><input type="hidden" name="a" value="1"/></form>`,
+			method:      http.MethodPost,
+			url:         "https://example.com/list",
+			contentType: "application/x-www-form-urlencoded",
+		},
+		{
+			name:        "multipart post",
+			form:        `<form method="post" enctype="multipart/form-data"><input type="hidden" name="a" value="1"/></form>`,
+			method:      http.MethodPost,
+			url:         "https://example.com/list",
+			contentType: "multipart/form-data; boundary=" + formBoundary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Pagination: &PaginationConfig{Type: "form", FormSelector: "//form"}}
+			page := &fetchedPage{URL: "https://example.com/list", Body: "<html><body>" + tt.form + "</body></html>"}
+			doc, err := parseDocument(page)
+			if err != nil {
+				t.Fatalf("parseDocument failed: %v", err)
+			}
+
+			req, err := extractFormRequest(context.Background(), page, doc, 2, config)
+			if err != nil {
+				t.Fatalf("extractFormRequest failed: %v", err)
+			}
+			if req.Method != tt.method || req.URL != tt.url || req.ContentType != tt.contentType {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			if tt.method == http.MethodPost && !strings.Contains(req.Body, "1") {
+				t.Errorf("Expected hidden field in body, got %q", req.Body)
+			}
+		})
+	}
+}
+
+// TestFormValues tests which form controls are carried over
+func TestFormValues(t *testing.T) {
+	page := &fetchedPage{URL: "https://example.com/list", Body: `<html><body><form>
+  <input type="hidden" name="state" value="abc"/>
+  <input name="q" value="shoes"/>
+  <input type="submit" name="go" value="Search"/>
+  <input type="text" name="off" value="x" disabled/>
+  <input type="checkbox" name="tag" value="new" checked/>
+  <input type="checkbox" name="tag" value="sale"/>
+  <input type="checkbox" name="stock" checked/>
+  <input type="radio" name="sort" value="price"/>
+  <input type="radio" name="sort" value="name" checked/>
+  <select name="size"><option>S</option><option value="m" selected>Medium</option></select>
+  <select name="color"><option value="red">Red</option><option value="blue">Blue</option></select>
+  <select name="brand" multiple><option value="a">A</option></select>
+  <textarea name="note">hello</textarea>
+</form></body></html>`}
+	doc, err := parseDocument(page)
+	if err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	got := formValues(htmlquery.FindOne(doc, "//form")).Encode()
+	expected := "color=red&note=hello&q=shoes&size=m&sort=name&state=abc&stock=on&tag=new"
+	if got != expected {
+		t.Errorf("formValues = %q, expected %q", got, expected)
+	}
+}
+
+// TestExtractFormRequest_VisitKey tests that rotating form state does not change the visited key
+func TestExtractFormRequest_VisitKey(t *testing.T) {
+	config := &Config{Pagination: &PaginationConfig{
+		Type:         "form",
+		FormSelector: "//form",
+		NextSelector: `//a[@id="next"]/@href`,
+	}}
+
+	keys := make(map[string]bool)
+	for _, state := range []string{"state-1", "state-2"} {
+		page := &fetchedPage{URL: "https://example.com/list", Body: `<html><body><form method="post">
+  <input type="hidden" name="__VIEWSTATE" value="` + state + `"/>
+  <a id="next" href="javascript:__doPostBack('grid','Page$2')">Next</a>
+</form></body></html>`}
+		doc, err := parseDocument(page)
+		if err != nil {
+			t.Fatalf("parseDocument failed: %v", err)
+		}
+		req, err := extractFormRequest(context.Background(), page, doc, 2, config)
+		if err != nil {
+			t.Fatalf("extractFormRequest failed: %v", err)
+		}
+		keys[req.key()] = true
+	}
+
+	if len(keys) != 1 {
+		t.Errorf("Expected one visited key for the same postback, got %v", keys)
+	}
+}
+
+// TestExtractPaginationURLs_Form tests that form pagination cannot be expressed as URLs
+func TestExtractPaginationURLs_Form(t *testing.T) {
+	config := &Config{
+		Pagination: &PaginationConfig{Type: "form", FormSelector: "//form"},
+	}
+
+	_, err := ExtractPaginationURLs(context.Background(), "https://example.com", config)
+	if !Is(err, ErrTypeConfig) {
+		t.Errorf("Expected config error, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package gtmlp
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// pagePlaceholder marks the page number in a page URL pattern
+const pagePlaceholder = "{page}"
+
+// digitRunPattern matches runs of digits in URLs and page labels
+var digitRunPattern = regexp.MustCompile(`\d+`)
+
+// inferNumberedPages generates page URLs 1..N from the highest page number and a page URL pattern.
+// Page 1 is the base URL unless it already matches the pattern.
+// Falls back to the visible page links if the range cannot be inferred.
+func inferNumberedPages(ctx context.Context, baseURL string, doc *html.Node, config *Config) ([]string, error) {
+	applyPaginationDefaults(config.Pagination)
+
+	lastPage, ok := extractLastPageNumber(ctx, doc, config)
+	if !ok {
+		getLogger().Warn("last page number not found, using visible page links",
+			"url", baseURL,
+			"selector", config.Pagination.LastPageSelector)
+		if config.Pagination.PageSelector == "" {
+			return nil, nil
+		}
+		return extractPageLinks(ctx, baseURL, doc, config)
+	}
+
+	pattern := config.Pagination.PageURLPattern
+	if pattern == "" {
+		links, err := extractPageLinks(ctx, baseURL, doc, config)
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, ok = learnPageURLPattern(links)
+		if !ok {
+			getLogger().Warn("page url pattern not learned, using visible page links",
+				"url", baseURL,
+				"links", len(links))
+			return links, nil
+		}
+	}
+
+	if lastPage > config.Pagination.MaxPages {
+		getLogger().Warn("inferred page range truncated to max pages",
+			"last_page", lastPage,
+			"max_pages", config.Pagination.MaxPages)
+		lastPage = config.Pagination.MaxPages
+	}
+
+	getLogger().Info("pagination range inferred",
+		"url", baseURL,
+		"pattern", pattern,
+		"last_page", lastPage)
+
+	urls := make([]string, 0, lastPage)
+	for n := 1; n <= lastPage; n++ {
+		if n == 1 && !matchesPageURLPattern(baseURL, pattern) {
+			urls = append(urls, baseURL)
+			continue
+		}
+
+		pageURL, err := resolveURL(baseURL, strings.ReplaceAll(pattern, pagePlaceholder, strconv.Itoa(n)))
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: "invalid page URL pattern",
+				URL:     pattern,
+				Cause:   err,
+			}
+		}
+		urls = append(urls, pageURL)
+	}
+
+	return urls, nil
+}
+
+// extractLastPageNumber returns the highest page number matched by lastPageSelector
+func extractLastPageNumber(ctx context.Context, doc *html.Node, config *Config) (int, bool) {
+	expr, err := compileSelector(config.Pagination.LastPageSelector)
+	if err != nil {
+		return 0, false
+	}
+
+	lastPage := 0
+	nodeIterator := expr.Evaluate(htmlquery.CreateXPathNavigator(doc)).(*xpath.NodeIterator)
+	for nodeIterator.MoveNext() {
+		value, err := applyPipesToURL(ctx, nodeIterator.Current().Value(), config.Pagination.LastPagePipes)
+		if err != nil {
+			continue
+		}
+
+		n, ok := parsePageNumber(value)
+		if ok && n > lastPage {
+			lastPage = n
+		}
+	}
+
+	return lastPage, lastPage > 0
+}
+
+// parsePageNumber reads the first number from a page label like "87", "Page 87" or "1,024"
+func parsePageNumber(label string) (int, bool) {
+	digits := digitRunPattern.FindString(strings.ReplaceAll(label, ",", ""))
+	if digits == "" {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// learnPageURLPattern learns where the page number sits in the page links.
+// Links sharing the same shape apart from their numbers are compared, and the
+// first number that differs between them becomes the {page} placeholder.
+// A single link uses its last number.
+func learnPageURLPattern(links []string) (string, bool) {
+	groups := make(map[string][]string)
+	var order []string
+	for _, link := range links {
+		if !digitRunPattern.MatchString(link) {
+			continue
+		}
+		shape := digitRunPattern.ReplaceAllString(link, "#")
+		if _, exists := groups[shape]; !exists {
+			order = append(order, shape)
+		}
+		groups[shape] = append(groups[shape], link)
+	}
+
+	// Use the largest group of similar links
+	var best []string
+	for _, shape := range order {
+		if len(groups[shape]) > len(best) {
+			best = groups[shape]
+		}
+	}
+	if len(best) == 0 {
+		return "", false
+	}
+
+	first := best[0]
+	runs := digitRunPattern.FindAllStringIndex(first, -1)
+	pageRun := len(runs) - 1
+
+	if len(best) > 1 {
+		for i, run := range runs {
+			differs := false
+			for _, other := range best[1:] {
+				otherRuns := digitRunPattern.FindAllStringIndex(other, -1)
+				if other[otherRuns[i][0]:otherRuns[i][1]] != first[run[0]:run[1]] {
+					differs = true
+					break
+				}
+			}
+			if differs {
+				pageRun = i
+				break
+			}
+		}
+	}
+
+	run := runs[pageRun]
+	return first[:run[0]] + pagePlaceholder + first[run[1]:], true
+}
+
+// matchesPageURLPattern reports whether rawURL is a page URL generated by pattern
+func matchesPageURLPattern(rawURL, pattern string) bool {
+	parts := strings.Split(pattern, pagePlaceholder)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	re, err := regexp.Compile("^" + strings.Join(parts, `\d+`) + "$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(rawURL)
+}
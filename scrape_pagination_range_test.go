@@ -0,0 +1,143 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testHTMLTruncatedPager = `<html><body>
+  <div class="product"><h2>Product 1</h2></div>
+  <div class="pagination">
+    <span class="current">1</span>
+    <a href="/products?page=2&amp;sort=asc">2</a>
+    <a href="/products?page=3&amp;sort=asc">3</a>
+    <span>…</span>
+    <a href="/products?page=12&amp;sort=asc" class="last">Page 12</a>
+  </div>
+</body></html>`
+
+// TestLearnPageURLPattern tests learning the page number position from links
+func TestLearnPageURLPattern(t *testing.T) {
+	tests := []struct {
+		links    []string
+		expected string
+		ok       bool
+	}{
+		{[]string{"https://e.com/cat/7/page/2", "https://e.com/cat/7/page/3"}, "https://e.com/cat/7/page/{page}", true},
+		{[]string{"https://e.com/list?p=2&size=20", "https://e.com/list?p=5&size=20"}, "https://e.com/list?p={page}&size=20", true},
+		{[]string{"https://e.com/cat/7/page/2"}, "https://e.com/cat/7/page/{page}", true},
+		{[]string{"https://e.com/about", "https://e.com/p/2", "https://e.com/p/3"}, "https://e.com/p/{page}", true},
+		{[]string{"https://e.com/next"}, "", false},
+	}
+
+	for _, tt := range tests {
+		pattern, ok := learnPageURLPattern(tt.links)
+		if ok != tt.ok || pattern != tt.expected {
+			t.Errorf("learnPageURLPattern(%v) = %q, %v; expected %q, %v", tt.links, pattern, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+// TestParsePageNumber tests reading page numbers from labels
+func TestParsePageNumber(t *testing.T) {
+	tests := map[string]int{"87": 87, "Page 12": 12, "1,024": 1024, " 5 ": 5}
+	for label, expected := range tests {
+		n, ok := parsePageNumber(label)
+		if !ok || n != expected {
+			t.Errorf("parsePageNumber(%q) = %d, %v; expected %d", label, n, ok, expected)
+		}
+	}
+
+	if _, ok := parsePageNumber("…"); ok {
+		t.Error("Expected no page number in ellipsis")
+	}
+}
+
+// TestExtractPaginationURLs_InferredRange tests generating the full page range without fetching pages
+func TestExtractPaginationURLs_InferredRange(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(testHTMLTruncatedPager))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:             "numbered",
+			PageSelector:     `//div[@class="pagination"]/a/@href`,
+			LastPageSelector: `//div[@class="pagination"]/a/text()`,
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	info, err := ExtractPaginationURLs(context.Background(), server.URL+"/products", config)
+	if err != nil {
+		t.Fatalf("ExtractPaginationURLs failed: %v", err)
+	}
+
+	if len(info.URLs) != 12 {
+		t.Fatalf("Expected 12 URLs, got %d: %v", len(info.URLs), info.URLs)
+	}
+	if info.URLs[0] != server.URL+"/products" {
+		t.Errorf("Expected page 1 to be the start URL, got %s", info.URLs[0])
+	}
+	if info.URLs[8] != server.URL+"/products?page=9&sort=asc" {
+		t.Errorf("Expected generated page 9 URL, got %s", info.URLs[8])
+	}
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Expected only the first page to be fetched, got %d requests", requests)
+	}
+}
+
+// TestScrapeURLWithPages_InferredRangePattern tests an explicit page URL pattern with MaxPages
+func TestScrapeURLWithPages_InferredRangePattern(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		page := strings.TrimPrefix(r.URL.Path, "/list/")
+		fmt.Fprintf(w, `<html><body><div class="product"><h2>Item %s</h2></div><span class="total">87 pages</span></body></html>`, page)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:             "numbered",
+			LastPageSelector: `//span[@class="total"]/text()`,
+			PageURLPattern:   "/list/{page}",
+			MaxPages:         3,
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	results, err := ScrapeURLWithPages[map[string]any](context.Background(), server.URL+"/list/1", config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+
+	if results.TotalPages != 3 {
+		t.Fatalf("Expected 3 pages, got %d", results.TotalPages)
+	}
+	for i, page := range results.Pages {
+		expected := fmt.Sprintf("Item %d", i+1)
+		if page.Items[0]["name"] != expected {
+			t.Errorf("Page %d: expected %s, got %v", i+1, expected, page.Items[0]["name"])
+		}
+	}
+}
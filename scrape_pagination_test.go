@@ -2,6 +2,7 @@ package gtmlp
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -591,6 +592,20 @@ func TestValidatePaginationConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid onError policy",
+			config: &Config{
+				Container: "//div",
+				Fields:    map[string]FieldConfig{"name": {XPath: ".//h2"}},
+				Pagination: &PaginationConfig{
+					Type:         "next-link",
+					NextSelector: "//a[@rel='next']/@href",
+					OnError:      "retry",
+				},
+				Timeout: 30 * time.Second,
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing pageSelector",
 			config: &Config{
@@ -615,3 +630,171 @@ func TestValidatePaginationConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestScrapeURLWithPages_PartialResultsOnError tests that pages scraped before a failure are returned
+func TestScrapeURLWithPages_PartialResultsOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page/2" {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(testHTMLPage1NextLink))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: `//a[@rel="next"]/@href`,
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	type Product struct {
+		Name string `json:"name"`
+	}
+
+	results, err := ScrapeURLWithPages[Product](context.Background(), server.URL+"/products", config)
+	if err == nil {
+		t.Fatal("Expected error when page fails, got nil")
+	}
+
+	var pagErr *PaginationError
+	if !errors.As(err, &pagErr) {
+		t.Fatalf("Expected PaginationError, got %T", err)
+	}
+
+	if results == nil {
+		t.Fatal("Expected partial results alongside error, got nil")
+	}
+	if results.TotalPages != 1 || len(results.Pages) != 1 {
+		t.Fatalf("Expected 1 page before failure, got %d", len(results.Pages))
+	}
+	if results.Pages[0].Items[1].Name != "Product 2" {
+		t.Errorf("Expected 'Product 2', got %s", results.Pages[0].Items[1].Name)
+	}
+
+	partial, ok := pagErr.PartialData.([]Product)
+	if !ok || len(partial) != 2 {
+		t.Errorf("Expected PartialData to be []Product with 2 items, got %T", pagErr.PartialData)
+	}
+
+	// ScrapeURL also returns the items scraped before failure
+	products, err := ScrapeURL[Product](context.Background(), server.URL+"/products", config)
+	if err == nil {
+		t.Fatal("Expected error when page fails, got nil")
+	}
+	if len(products) != 2 {
+		t.Errorf("Expected 2 partial products, got %d", len(products))
+	}
+}
+
+// TestScrapeURLWithPages_SkipFailedPages tests the skip error policy with numbered pagination
+func TestScrapeURLWithPages_SkipFailedPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/page/1":
+			w.Write([]byte(testHTMLNumberedPagination))
+		case "/page/2":
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		case "/page/3":
+			w.Write([]byte(testHTMLPage3NoNext))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:         "numbered",
+			PageSelector: `//div[@class="pagination"]/a/@href`,
+			OnError:      OnErrorSkip,
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	type Product struct {
+		Name string `json:"name"`
+	}
+
+	results, err := ScrapeURLWithPages[Product](context.Background(), server.URL+"/page/1", config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+
+	if results.TotalPages != 2 {
+		t.Errorf("Expected 2 scraped pages, got %d", results.TotalPages)
+	}
+	if results.TotalItems != 3 {
+		t.Errorf("Expected 3 items, got %d", results.TotalItems)
+	}
+	if len(results.Errors) != 1 {
+		t.Fatalf("Expected 1 skipped page, got %d", len(results.Errors))
+	}
+	if results.Errors[0].PageURL != server.URL+"/page/2" {
+		t.Errorf("Expected skipped page /page/2, got %s", results.Errors[0].PageURL)
+	}
+	if !Is(results.Errors[0], ErrTypeNetwork) {
+		t.Errorf("Expected network error cause, got %v", results.Errors[0].Cause)
+	}
+
+	// A failed start page is not skipped
+	_, err = ScrapeURLWithPages[Product](context.Background(), server.URL+"/page/2", config)
+	var pagErr *PaginationError
+	if !errors.As(err, &pagErr) || pagErr.PageNumber != 1 {
+		t.Errorf("Expected start page error, got %v", err)
+	}
+}
+
+// TestScrapeURLWithPages_SkipChainEndsAtFailure tests that a next-link chain ending at a skipped page returns its error
+func TestScrapeURLWithPages_SkipChainEndsAtFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page/2" {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(testHTMLPage1NextLink))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: `//a[@rel="next"]/@href`,
+			OnError:      OnErrorSkip,
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	type Product struct {
+		Name string `json:"name"`
+	}
+
+	results, err := ScrapeURLWithPages[Product](context.Background(), server.URL+"/products", config)
+	var pagErr *PaginationError
+	if !errors.As(err, &pagErr) || pagErr.PageNumber != 2 {
+		t.Fatalf("Expected error for the page that ended the chain, got %v", err)
+	}
+	if results.TotalItems != 2 || len(results.Errors) != 1 {
+		t.Errorf("Expected 2 items and 1 skipped page, got %d and %d", results.TotalItems, len(results.Errors))
+	}
+}
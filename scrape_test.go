@@ -1023,3 +1023,130 @@ func TestAltXPath_EmptyArrays(t *testing.T) {
 	}
 }
 
+
+const documentFieldsHTML = `<html><head><title>Catalog</title></head><body>
+  <h1>  Spring Sale  </h1>
+  <div class="product"><h2>Product 1</h2><span class="price">$10</span></div>
+  <div class="product"><h2>Product 2</h2><span class="price">$20</span></div>
+</body></html>`
+
+func documentFieldsConfig() *Config {
+	return &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name":  {XPath: `.//h2/text()`},
+			"price": {XPath: `.//span[@class="price"]/text()`},
+		},
+		DocumentFields: map[string]FieldConfig{
+			"title":    {XPath: `//title/text()`},
+			"headline": {XPath: `//h1/text()`, Pipes: []string{"trim"}},
+		},
+		Timeout: 30 * time.Second,
+	}
+}
+
+// TestScrapePage_DocumentFields tests that document fields are extracted once per page
+func TestScrapePage_DocumentFields(t *testing.T) {
+	ctx := WithURL(context.Background(), "https://example.com/catalog")
+	page, err := ScrapePage[Product](ctx, documentFieldsHTML, documentFieldsConfig())
+	if err != nil {
+		t.Fatalf("ScrapePage failed: %v", err)
+	}
+
+	if page.URL != "https://example.com/catalog" {
+		t.Errorf("Expected page URL from context, got %q", page.URL)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(page.Items))
+	}
+	if page.Document["title"] != "Catalog" {
+		t.Errorf("Expected title 'Catalog', got %v", page.Document["title"])
+	}
+	if page.Document["headline"] != "Spring Sale" {
+		t.Errorf("Expected headline 'Spring Sale', got %v", page.Document["headline"])
+	}
+}
+
+// TestScrapeUntyped_CopyDocumentFields tests copying document values into every item
+func TestScrapeUntyped_CopyDocumentFields(t *testing.T) {
+	config := documentFieldsConfig()
+	config.CopyDocumentFields = true
+
+	results, err := ScrapeUntyped(context.Background(), documentFieldsHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for i, item := range results {
+		if item["title"] != "Catalog" {
+			t.Errorf("Item %d: expected title 'Catalog', got %v", i, item["title"])
+		}
+	}
+
+	// Without the flag, items only hold their own fields
+	config.CopyDocumentFields = false
+	results, err = ScrapeUntyped(context.Background(), documentFieldsHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if _, ok := results[0]["title"]; ok {
+		t.Error("Expected document fields not to be copied into items")
+	}
+}
+
+// TestScrapeURLWithPages_Document tests that single page results carry document fields
+func TestScrapeURLWithPages_Document(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(documentFieldsHTML))
+	}))
+	defer server.Close()
+
+	config := documentFieldsConfig()
+	config.AllowPrivateIPs = true
+
+	results, err := ScrapeURLWithPages[Product](context.Background(), server.URL, config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+
+	if len(results.Pages) != 1 {
+		t.Fatalf("Expected 1 page, got %d", len(results.Pages))
+	}
+	if results.Pages[0].Document["title"] != "Catalog" {
+		t.Errorf("Expected title 'Catalog', got %v", results.Pages[0].Document["title"])
+	}
+	if results.TotalItems != 2 {
+		t.Errorf("Expected 2 items, got %d", results.TotalItems)
+	}
+}
+
+// TestValidate_DocumentFields tests validation of document fields
+func TestValidate_DocumentFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]FieldConfig
+		copy   bool
+	}{
+		{"missing xpath", map[string]FieldConfig{"title": {}}, false},
+		{"invalid xpath", map[string]FieldConfig{"title": {XPath: `//title[`}}, false},
+		{"follow", map[string]FieldConfig{"title": {XPath: `//a/@href`, Follow: &Config{
+			Fields: map[string]FieldConfig{"body": {XPath: `//p/text()`}},
+		}}}, false},
+		{"clashes with item field", map[string]FieldConfig{"name": {XPath: `//title/text()`}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := documentFieldsConfig()
+			config.DocumentFields = tt.fields
+			config.CopyDocumentFields = tt.copy
+			if err := config.validateExtraction(); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
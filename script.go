@@ -0,0 +1,600 @@
+package gtmlp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// scriptPrefix marks a selector that reads a named Config.Scripts value,
+// e.g. "script:next.props.pageProps.title"
+const scriptPrefix = "script:"
+
+// defaultScriptXPath is searched when a ScriptConfig has no XPath
+const defaultScriptXPath = "//script"
+
+// isScriptSelector reports whether selector reads from an embedded script value
+func isScriptSelector(selector string) bool {
+	return strings.HasPrefix(selector, scriptPrefix)
+}
+
+// parseScriptSelector splits "script:name.path" into the script name and the JSON path
+func parseScriptSelector(selector string) (name, path string) {
+	rest := strings.TrimSpace(strings.TrimPrefix(selector, scriptPrefix))
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		return rest, ""
+	}
+	return rest[:end], strings.TrimPrefix(rest[end:], ".")
+}
+
+// validateScriptSelector checks that a script: selector names a configured script and has a valid path
+func validateScriptSelector(selector string, scripts map[string]ScriptConfig) error {
+	name, path := parseScriptSelector(selector)
+	if _, ok := scripts[name]; !ok {
+		return fmt.Errorf("unknown script %q in %q", name, selector)
+	}
+	_, err := splitJSONPath(path)
+	return err
+}
+
+// validateScriptConfig checks a named script's XPath and pattern
+func validateScriptConfig(name string, script ScriptConfig) error {
+	if script.XPath != "" {
+		if _, err := compileSelector(script.XPath); err != nil {
+			return &ScrapeError{
+				Type:    ErrTypeXPath,
+				Message: fmt.Sprintf("invalid xpath for script '%s'", name),
+				XPath:   script.XPath,
+				Cause:   err,
+			}
+		}
+	}
+	if script.Pattern != "" {
+		if _, err := regexp.Compile(script.Pattern); err != nil {
+			return &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("invalid pattern for script '%s'", name),
+				Cause:   err,
+			}
+		}
+	}
+	return nil
+}
+
+// scriptState holds the scripts of the config being extracted and caches their
+// decoded values, so each script is parsed once per page
+type scriptState struct {
+	scripts map[string]ScriptConfig
+	mu      sync.Mutex
+	values  map[string]any
+}
+
+// withScripts installs a fresh script state for one page
+func withScripts(ctx context.Context, config *Config) context.Context {
+	return context.WithValue(ctx, contextKey("scripts"), &scriptState{
+		scripts: config.Scripts,
+		values:  make(map[string]any),
+	})
+}
+
+// scriptValue returns the decoded value of a named script on the page containing node.
+// Returns nil if the script is not configured, not found or cannot be decoded.
+func scriptValue(ctx context.Context, node *html.Node, name string) any {
+	state, _ := ctx.Value(contextKey("scripts")).(*scriptState)
+	if state == nil {
+		return nil
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if value, ok := state.values[name]; ok {
+		return value
+	}
+
+	script, ok := state.scripts[name]
+	if !ok {
+		return nil
+	}
+	value, err := findScriptValue(node, script)
+	if err != nil {
+		getLogger().Warn("embedded script value not decoded",
+			"script", name,
+			"error", err.Error())
+	}
+	state.values[name] = value
+	return value
+}
+
+// extractScriptField evaluates a script: selector and returns the value as a string
+func extractScriptField(ctx context.Context, node *html.Node, selector string) string {
+	name, path := parseScriptSelector(selector)
+	value, ok := lookupJSONPath(scriptValue(ctx, node, name), path)
+	if !ok {
+		return ""
+	}
+	return jsonValueString(value)
+}
+
+// findScriptValue locates the script's literal and decodes it. Script elements matching
+// the XPath are tried in order; the first one that yields a value wins.
+func findScriptValue(node *html.Node, script ScriptConfig) (any, error) {
+	scriptXPath := script.XPath
+	if scriptXPath == "" {
+		scriptXPath = defaultScriptXPath
+	}
+	expr, err := compileSelector(scriptXPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pattern *regexp.Regexp
+	if script.Pattern != "" {
+		if pattern, err = regexp.Compile(script.Pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	// Scripts are located from the document root, whichever node the field is evaluated on
+	for node.Parent != nil {
+		node = node.Parent
+	}
+
+	var lastErr error
+	for _, n := range htmlquery.QuerySelectorAll(node, expr) {
+		text := htmlquery.InnerText(n)
+
+		start := 0
+		if pattern != nil {
+			match := pattern.FindStringSubmatchIndex(text)
+			if match == nil {
+				continue
+			}
+			// The literal starts at the first capture group, or right after the match
+			start = match[1]
+			if len(match) >= 4 && match[2] >= 0 {
+				start = match[2]
+			}
+		}
+
+		value, err := parseJSLiteral(text[start:])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return value, nil
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, nil
+}
+
+// parseJSLiteral decodes the JSON or JavaScript literal at the start of src without
+// executing it. Beyond JSON it accepts single-quoted and template strings, unquoted keys,
+// trailing commas, comments, hex numbers, undefined/NaN/Infinity (as null), !0/!1 and
+// JSON.parse("...") wrappers. Text after the literal is ignored.
+func parseJSLiteral(src string) (any, error) {
+	p := &jsParser{src: src}
+	var out strings.Builder
+	if err := p.value(&out); err != nil {
+		return nil, err
+	}
+	return parseJSON(out.String())
+}
+
+// jsParser converts a JavaScript literal into JSON text
+type jsParser struct {
+	src string
+	pos int
+}
+
+func (p *jsParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid javascript literal at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// skip skips whitespace and comments
+func (p *jsParser) skip() {
+	for p.pos < len(p.src) {
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], "//"):
+			end := strings.IndexByte(p.src[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.src)
+			} else {
+				p.pos += end + 1
+			}
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			end := strings.Index(p.src[p.pos+2:], "*/")
+			if end < 0 {
+				p.pos = len(p.src)
+			} else {
+				p.pos += end + 4
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			if !unicode.IsSpace(r) && r != '\uFEFF' {
+				return
+			}
+			p.pos += size
+		}
+	}
+}
+
+func (p *jsParser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// value converts one value and writes its JSON form
+func (p *jsParser) value(out *strings.Builder) error {
+	p.skip()
+	c := p.peek()
+	switch {
+	case c == 0:
+		return p.errorf("unexpected end of input")
+	case c == '{':
+		return p.object(out)
+	case c == '[':
+		return p.array(out)
+	case c == '"' || c == '\'' || c == '`':
+		s, err := p.str()
+		if err != nil {
+			return err
+		}
+		return writeJSONString(out, s)
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number(out)
+	case c == '!':
+		// Minified booleans: !0 and !1
+		p.pos++
+		p.skip()
+		switch p.peek() {
+		case '0':
+			out.WriteString("true")
+		case '1':
+			out.WriteString("false")
+		default:
+			return p.errorf("unsupported negation")
+		}
+		p.pos++
+		return nil
+	}
+
+	ident := p.ident()
+	switch ident {
+	case "true", "false", "null":
+		out.WriteString(ident)
+	case "undefined", "NaN", "Infinity":
+		out.WriteString("null")
+	case "void":
+		// void <literal> is undefined
+		if err := p.value(&strings.Builder{}); err != nil {
+			return err
+		}
+		out.WriteString("null")
+	case "JSON":
+		return p.jsonParse(out)
+	case "":
+		return p.errorf("unexpected character %q", c)
+	default:
+		return p.errorf("unsupported identifier %q (code is not executed)", ident)
+	}
+	return nil
+}
+
+func (p *jsParser) object(out *strings.Builder) error {
+	p.pos++ // {
+	out.WriteByte('{')
+	first := true
+	for {
+		p.skip()
+		if p.peek() == '}' {
+			p.pos++
+			out.WriteByte('}')
+			return nil
+		}
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+
+		key, err := p.key()
+		if err != nil {
+			return err
+		}
+		if err := writeJSONString(out, key); err != nil {
+			return err
+		}
+
+		p.skip()
+		if p.peek() != ':' {
+			return p.errorf("expected ':' after key %q", key)
+		}
+		p.pos++
+		out.WriteByte(':')
+		if err := p.value(out); err != nil {
+			return err
+		}
+
+		p.skip()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return p.errorf("expected ',' or '}' in object")
+		}
+	}
+}
+
+// key reads a quoted, bare identifier or numeric object key
+func (p *jsParser) key() (string, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'' || c == '`':
+		return p.str()
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		return p.src[start:p.pos], nil
+	}
+	if ident := p.ident(); ident != "" {
+		return ident, nil
+	}
+	return "", p.errorf("expected object key")
+}
+
+func (p *jsParser) array(out *strings.Builder) error {
+	p.pos++ // [
+	out.WriteByte('[')
+	first := true
+	for {
+		p.skip()
+		if p.peek() == ']' {
+			p.pos++
+			out.WriteByte(']')
+			return nil
+		}
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+
+		if err := p.value(out); err != nil {
+			return err
+		}
+
+		p.skip()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// ident reads an identifier, returning "" if there is none
+func (p *jsParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if r == '_' || r == '$' || unicode.IsLetter(r) || (p.pos > start && unicode.IsDigit(r)) {
+			p.pos += size
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// number reads a numeric literal and writes it as a JSON number
+func (p *jsParser) number(out *strings.Builder) error {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '.' || c == '_' ||
+			((c == '-' || c == '+') && (p.pos == start || p.src[p.pos-1] == 'e' || p.src[p.pos-1] == 'E')) {
+			p.pos++
+			continue
+		}
+		break
+	}
+
+	literal := strings.ReplaceAll(p.src[start:p.pos], "_", "")
+	literal = strings.TrimPrefix(literal, "+")
+	switch literal {
+	case "Infinity", "-Infinity":
+		out.WriteString("null")
+		return nil
+	}
+
+	sign := ""
+	if strings.HasPrefix(literal, "-") {
+		sign, literal = "-", literal[1:]
+	}
+	if len(literal) > 2 && literal[0] == '0' && strings.ContainsRune("xXoObB", rune(literal[1])) {
+		n, err := strconv.ParseInt(literal, 0, 64)
+		if err != nil {
+			return p.errorf("invalid number %q", literal)
+		}
+		out.WriteString(sign + strconv.FormatInt(n, 10))
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return p.errorf("invalid number %q", literal)
+	}
+	// Keep the original digits when they are already valid JSON
+	if json.Valid([]byte(literal)) {
+		out.WriteString(sign + literal)
+	} else {
+		out.WriteString(sign + strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return nil
+}
+
+// str reads a single, double or backtick quoted string and returns its value
+func (p *jsParser) str() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '$' && quote == '`' && strings.HasPrefix(p.src[p.pos:], "${"):
+			return "", p.errorf("template substitutions are not supported")
+		case c == '\\':
+			if err := p.escape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// escape decodes one backslash escape sequence
+func (p *jsParser) escape(sb *strings.Builder) error {
+	p.pos++ // backslash
+	if p.pos >= len(p.src) {
+		return p.errorf("unterminated escape")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'v':
+		sb.WriteByte('\v')
+	case '0':
+		sb.WriteByte(0)
+	case '\n':
+		// Line continuation
+	case 'x', 'u':
+		digits := 2
+		if c == 'u' {
+			digits = 4
+			if p.peek() == '{' {
+				end := strings.IndexByte(p.src[p.pos:], '}')
+				if end < 0 {
+					return p.errorf("invalid unicode escape")
+				}
+				digits = end - 1
+				p.pos++
+				defer func() { p.pos++ }()
+			}
+		}
+		if p.pos+digits > len(p.src) {
+			return p.errorf("invalid escape")
+		}
+		n, err := strconv.ParseUint(p.src[p.pos:p.pos+digits], 16, 32)
+		if err != nil {
+			return p.errorf("invalid escape")
+		}
+		p.pos += digits
+		r := rune(n)
+		// Combine UTF-16 surrogate pairs
+		if r >= 0xD800 && r < 0xDC00 && strings.HasPrefix(p.src[p.pos:], `\u`) && p.pos+6 <= len(p.src) {
+			if low, err := strconv.ParseUint(p.src[p.pos+2:p.pos+6], 16, 32); err == nil && low >= 0xDC00 && low < 0xE000 {
+				r = (r-0xD800)<<10 + (rune(low) - 0xDC00) + 0x10000
+				p.pos += 6
+			}
+		}
+		sb.WriteRune(r)
+	default:
+		sb.WriteByte(c)
+	}
+	return nil
+}
+
+// jsonParse handles JSON.parse("...") by decoding the string argument as JSON
+func (p *jsParser) jsonParse(out *strings.Builder) error {
+	if !strings.HasPrefix(p.src[p.pos:], ".parse") {
+		return p.errorf("unsupported identifier \"JSON\"")
+	}
+	p.pos += len(".parse")
+	p.skip()
+	if p.peek() != '(' {
+		return p.errorf("expected '(' after JSON.parse")
+	}
+	p.pos++
+	p.skip()
+	if c := p.peek(); c != '"' && c != '\'' && c != '`' {
+		return p.errorf("JSON.parse argument must be a string literal")
+	}
+	data, err := p.str()
+	if err != nil {
+		return err
+	}
+	p.skip()
+	if p.peek() != ')' {
+		return p.errorf("expected ')' after JSON.parse argument")
+	}
+	p.pos++
+
+	if !json.Valid([]byte(data)) {
+		return p.errorf("JSON.parse argument is not valid JSON")
+	}
+	out.WriteString(data)
+	return nil
+}
+
+// writeJSONString writes s as a JSON string
+func writeJSONString(out *strings.Builder, s string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	out.Write(data)
+	return nil
+}
+
+// extractScriptItems builds items from the JSON array a script: container selects.
+// Field selectors are JSON paths relative to each element ("$" is the element itself).
+func extractScriptItems(ctx context.Context, doc *html.Node, config *Config) ([]map[string]any, error) {
+	elements := findContainerMatches(config.Container, config.AltContainer, func(selector string) []any {
+		name, path := parseScriptSelector(selector)
+		return selectJSONElements(scriptValue(ctx, doc, name), path)
+	})
+
+	results := make([]map[string]any, 0, len(elements))
+	for _, element := range elements {
+		fieldData, err := extractJSONFields(ctx, element, config.Fields, doc)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, fieldData)
+	}
+
+	return results, nil
+}
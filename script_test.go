@@ -0,0 +1,242 @@
+package gtmlp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const scriptTestHTML = `<html><head>
+<script id="__NEXT_DATA__" type="application/json">
+{"props": {"pageProps": {"title": "Catalog", "products": [
+  {"id": 1, "name": "Widget", "price": {"amount": 9.5}, "url": "/p/1"},
+  {"id": 2, "name": "Gadget", "price": {"amount": 20}, "url": "/p/2"}
+]}}}
+</script>
+<script>
+  // app bootstrap
+  window.dataLayer = [];
+  var initialState = {
+    user: {name: 'Ann', 'isAdmin': !1, tags: ["a", 'b',],},
+    count: 0x1F, ratio: .5, missing: undefined, // trailing comment
+    note: "line\nbreak é \x41",
+  };
+  window.__NUXT__ = JSON.parse('{"route":"/shop","items":[{"sku":"A1"}]}');
+</script>
+</head><body><div class="product"><h2>DOM Widget</h2></div></body></html>`
+
+func scriptTestConfig() *Config {
+	return &Config{
+		Scripts: map[string]ScriptConfig{
+			"next":  {XPath: `//script[@id="__NEXT_DATA__"]`},
+			"state": {Pattern: `initialState\s*=`},
+			"nuxt":  {Pattern: `window\.__NUXT__\s*=\s*(JSON\.parse)`},
+		},
+		Timeout: 30 * time.Second,
+	}
+}
+
+// TestParseJSLiteral tests converting JavaScript literals to JSON values
+func TestParseJSLiteral(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		path     string
+		expected string
+	}{
+		{"json object", `{"a": {"b": [1, 2]}} trailing code;`, "a.b[1]", "2"},
+		{"unquoted keys", `{foo: {bar$: 'x'}}`, "foo.bar$", "x"},
+		{"single quotes with escapes", `{s: 'it\'s "q" A\x42'}`, "s", `it's "q" AB`},
+		{"template string", "{s: `multi\nline`}", "s", "multi\nline"},
+		{"trailing commas", `{a: [1, 2,], b: 3,}`, "a", "[1,2]"},
+		{"comments", "{/* c */ a: 1, // x\n b: 2}", "b", "2"},
+		{"hex", `{h: 0xff, e: 1e3}`, "h", "255"},
+		{"leading dot", `{d: .25}`, "d", "0.25"},
+		{"negative trailing dot", `{n: -5.}`, "n", "-5"},
+		{"undefined is null", `{u: undefined, i: Infinity}`, "u", ""},
+		{"minified booleans", `{t: !0, f: !1}`, "f", "false"},
+		{"void", `{v: void 0}`, "v", ""},
+		{"numeric keys", `{1: "one"}`, "1", "one"},
+		{"surrogate pair", `{e: "\ud83d\ude00 \u{1F600}"}`, "e", "😀 😀"},
+		{"json parse", `JSON.parse("{\"a\":1}")`, "a", "1"},
+		{"top-level array", `[{"x": 1}]`, "[0].x", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseJSLiteral(tt.input)
+			if err != nil {
+				t.Fatalf("parseJSLiteral failed: %v", err)
+			}
+			got, ok := lookupJSONPath(value, tt.path)
+			if !ok {
+				t.Fatalf("Path %q not found in %v", tt.path, value)
+			}
+			if s := jsonValueString(got); s != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, s)
+			}
+		})
+	}
+}
+
+// TestParseJSLiteral_Rejected tests that code is rejected rather than executed
+func TestParseJSLiteral_Rejected(t *testing.T) {
+	inputs := []string{
+		`{a: someVariable}`,
+		`{a: fn()}`,
+		"{a: `x${y}`}",
+		`{a: 1`,
+		`{a 1}`,
+		`JSON.parse("{bad")`,
+		``,
+	}
+
+	for _, input := range inputs {
+		if _, err := parseJSLiteral(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+// TestScrapeUntyped_ScriptFields tests reading script values into fields
+func TestScrapeUntyped_ScriptFields(t *testing.T) {
+	config := scriptTestConfig()
+	config.Container = `//div[@class="product"]`
+	config.Fields = map[string]FieldConfig{
+		"name":    {XPath: `.//h2/text()`},
+		"title":   {XPath: "script:next.props.pageProps.title"},
+		"user":    {XPath: "script:state.user.name"},
+		"admin":   {XPath: "script:state.user.isAdmin"},
+		"count":   {XPath: "script:state.count", Pipes: []string{"toint"}},
+		"tag":     {XPath: "script:state.user.tags[1]"},
+		"route":   {XPath: "script:nuxt.route"},
+		"missing": {XPath: "script:state.nothing", AltXPath: []string{"script:nuxt.items[0].sku"}},
+	}
+
+	results, err := ScrapeUntyped(context.Background(), scriptTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	expected := map[string]any{
+		"name":    "DOM Widget",
+		"title":   "Catalog",
+		"user":    "Ann",
+		"admin":   "false",
+		"count":   31,
+		"tag":     "b",
+		"route":   "/shop",
+		"missing": "A1",
+	}
+	for field, want := range expected {
+		if got := results[0][field]; got != want {
+			t.Errorf("Field %s: expected %v (%T), got %v (%T)", field, want, want, got, got)
+		}
+	}
+}
+
+// TestScrape_ScriptContainer tests items built from a JSON array in a script
+func TestScrape_ScriptContainer(t *testing.T) {
+	type scriptProduct struct {
+		ID    int     `json:"id"`
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+		Title string  `json:"title"`
+	}
+
+	config := scriptTestConfig()
+	config.Container = "script:next.props.pageProps.missing"
+	config.AltContainer = []string{"script:next.props.pageProps.products"}
+	config.Fields = map[string]FieldConfig{
+		"id":    {XPath: "id", Pipes: []string{"toint"}},
+		"name":  {XPath: "$.name"},
+		"price": {XPath: "price.amount", Pipes: []string{"tofloat"}},
+		"title": {XPath: "script:next.props.pageProps.title"},
+	}
+
+	results, err := Scrape[scriptProduct](context.Background(), scriptTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[1] != (scriptProduct{ID: 2, Name: "Gadget", Price: 20, Title: "Catalog"}) {
+		t.Errorf("Unexpected item: %+v", results[1])
+	}
+}
+
+// TestScrape_ScriptContainerNotFound tests that a missing script yields no items
+func TestScrape_ScriptContainerNotFound(t *testing.T) {
+	config := scriptTestConfig()
+	config.Scripts["absent"] = ScriptConfig{Pattern: `__APOLLO_STATE__\s*=`}
+	config.Container = "script:absent.items"
+	config.Fields = map[string]FieldConfig{"name": {XPath: "name"}}
+
+	results, err := ScrapeUntyped(context.Background(), scriptTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+// TestValidate_Scripts tests validation of script configs and selectors
+func TestValidate_Scripts(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		errMsg string
+	}{
+		{"unknown script in field", func(c *Config) {
+			c.Fields["x"] = FieldConfig{XPath: "script:other.x"}
+		}, "invalid xpath"},
+		{"unknown script in container", func(c *Config) {
+			c.Container = "script:other.items"
+		}, "invalid container"},
+		{"invalid pattern", func(c *Config) {
+			c.Scripts["bad"] = ScriptConfig{Pattern: `(`}
+		}, "invalid pattern"},
+		{"invalid script xpath", func(c *Config) {
+			c.Scripts["bad"] = ScriptConfig{XPath: `//script[`}
+		}, "invalid xpath for script"},
+		{"mixed alt container", func(c *Config) {
+			c.Container = "script:next.props.pageProps.products"
+			c.AltContainer = []string{`//div`}
+		}, "altContainer[0]"},
+		{"invalid json path field", func(c *Config) {
+			c.Container = "script:next.props.pageProps.products"
+			c.Fields["x"] = FieldConfig{XPath: "a[0"}
+		}, "invalid xpath"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := scriptTestConfig()
+			config.Container = `//div`
+			config.Fields = map[string]FieldConfig{"name": {XPath: "script:next.props"}}
+			tt.modify(config)
+
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	// JSON paths are valid fields for a script container
+	config := scriptTestConfig()
+	config.Container = "script:next.props.pageProps.products"
+	config.Fields = map[string]FieldConfig{"name": {XPath: "name"}, "first": {XPath: "tags[0]"}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
@@ -23,8 +23,8 @@ func validateURL(rawURL string, config *Config) error {
 		}
 	}
 
-	// SSRF protection (unless AllowPrivateIPs is enabled)
-	if !config.AllowPrivateIPs {
+	// SSRF protection (unless AllowPrivateIPs is enabled or responses are replayed)
+	if !config.AllowPrivateIPs && !config.Session.replaying() {
 		if err := checkSSRF(u); err != nil {
 			return err
 		}
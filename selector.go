@@ -0,0 +1,462 @@
+package gtmlp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
+
+// cssPrefix marks a selector as CSS instead of XPath, e.g. "css:div.product > h2::text"
+const cssPrefix = "css:"
+
+// isCSSSelector reports whether selector uses the css: prefix
+func isCSSSelector(selector string) bool {
+	return strings.HasPrefix(selector, cssPrefix)
+}
+
+// selectorXPath returns the XPath for a selector, translating css: selectors
+func selectorXPath(selector string) (string, error) {
+	if !isCSSSelector(selector) {
+		return selector, nil
+	}
+	return cssToXPath(strings.TrimPrefix(selector, cssPrefix))
+}
+
+// compileSelector compiles an XPath or css: selector
+func compileSelector(selector string) (*xpath.Expr, error) {
+	expr, err := selectorXPath(selector)
+	if err != nil {
+		return nil, err
+	}
+	return xpath.Compile(expr)
+}
+
+// cssToXPath translates a CSS selector group into an equivalent relative XPath.
+// Supported: type, *, #id, .class, attribute selectors (= ~= |= ^= $= *=),
+// descendant, >, + and ~ combinators, :first-child, :last-child, :only-child,
+// :nth-child(n|odd|even), :first-of-type, :last-of-type, :nth-of-type(n),
+// :not(compound), :contains("text"), :empty, and the ::text and ::attr(name)
+// pseudo-elements, which select text nodes or an attribute of the match.
+func cssToXPath(css string) (string, error) {
+	p := &cssParser{input: strings.TrimSpace(css)}
+	if p.input == "" {
+		return "", fmt.Errorf("empty css selector")
+	}
+
+	var branches []string
+	for {
+		branch, err := p.parseSelector()
+		if err != nil {
+			return "", fmt.Errorf("invalid css selector %q: %w", css, err)
+		}
+		branches = append(branches, branch)
+
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		if !p.consume(',') {
+			return "", fmt.Errorf("invalid css selector %q: unexpected %q at offset %d", css, p.peek(), p.pos)
+		}
+	}
+
+	return strings.Join(branches, " | "), nil
+}
+
+// cssParser is a small recursive descent parser over a CSS selector
+type cssParser struct {
+	input string
+	pos   int
+}
+
+func (p *cssParser) done() bool { return p.pos >= len(p.input) }
+
+func (p *cssParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *cssParser) consume(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *cssParser) skipSpace() bool {
+	start := p.pos
+	for !p.done() && strings.IndexByte(" \t\n\r\f", p.peek()) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// parseSelector parses compounds joined by combinators, plus an optional pseudo-element
+func (p *cssParser) parseSelector() (string, error) {
+	p.skipSpace()
+
+	var b strings.Builder
+	combinator := byte(' ')
+	for {
+		step, err := p.parseCompound()
+		if err != nil {
+			return "", err
+		}
+
+		switch combinator {
+		case ' ':
+			if b.Len() == 0 {
+				b.WriteString(".//")
+			} else {
+				b.WriteString("//")
+			}
+			b.WriteString(step.expr())
+		case '>':
+			b.WriteString("/")
+			b.WriteString(step.expr())
+		case '+':
+			b.WriteString("/following-sibling::*[1]/self::")
+			b.WriteString(step.expr())
+		case '~':
+			b.WriteString("/following-sibling::")
+			b.WriteString(step.expr())
+		}
+
+		// Pseudo-elements end the selector
+		if strings.HasPrefix(p.input[p.pos:], "::") {
+			p.pos += 2
+			extractor, err := p.parsePseudoElement()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(extractor)
+			return b.String(), nil
+		}
+
+		hadSpace := p.skipSpace()
+		switch c := p.peek(); {
+		case c == '>' || c == '+' || c == '~':
+			p.pos++
+			p.skipSpace()
+			combinator = c
+		case c == ',' || p.done():
+			return b.String(), nil
+		case hadSpace:
+			combinator = ' '
+		default:
+			return "", fmt.Errorf("unexpected %q at offset %d", c, p.pos)
+		}
+	}
+}
+
+// cssStep is one compound selector: a tag and its conditions
+type cssStep struct {
+	tag        string
+	conditions []string
+}
+
+func (s cssStep) expr() string {
+	var b strings.Builder
+	b.WriteString(s.tag)
+	for _, condition := range s.conditions {
+		b.WriteString("[")
+		b.WriteString(condition)
+		b.WriteString("]")
+	}
+	return b.String()
+}
+
+// parseCompound parses a type selector followed by id, class, attribute and pseudo-class selectors
+func (p *cssParser) parseCompound() (cssStep, error) {
+	start := p.pos
+	step := cssStep{tag: "*"}
+	if !p.consume('*') {
+		if name := p.parseIdent(); name != "" {
+			step.tag = strings.ToLower(name)
+		}
+	}
+
+	for {
+		switch p.peek() {
+		case '#':
+			p.pos++
+			id := p.parseIdent()
+			if id == "" {
+				return step, fmt.Errorf("expected id after '#' at offset %d", p.pos)
+			}
+			step.conditions = append(step.conditions, "@id="+xpathLiteral(id))
+			continue
+		case '.':
+			p.pos++
+			class := p.parseIdent()
+			if class == "" {
+				return step, fmt.Errorf("expected class after '.' at offset %d", p.pos)
+			}
+			step.conditions = append(step.conditions, classCondition("@class", class))
+			continue
+		case '[':
+			p.pos++
+			condition, err := p.parseAttribute()
+			if err != nil {
+				return step, err
+			}
+			step.conditions = append(step.conditions, condition)
+			continue
+		case ':':
+			if !strings.HasPrefix(p.input[p.pos:], "::") {
+				p.pos++
+				condition, err := p.parsePseudoClass(step.tag)
+				if err != nil {
+					return step, err
+				}
+				step.conditions = append(step.conditions, condition)
+				continue
+			}
+		}
+
+		if p.pos == start {
+			if p.done() {
+				return step, fmt.Errorf("unexpected end of selector")
+			}
+			return step, fmt.Errorf("unexpected %q at offset %d", p.peek(), p.pos)
+		}
+		return step, nil
+	}
+}
+
+// parseIdent parses a CSS identifier
+func (p *cssParser) parseIdent() string {
+	start := p.pos
+	for !p.done() {
+		c := p.peek()
+		if c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80 {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.input[start:p.pos]
+}
+
+// parseValue parses a quoted string or an identifier
+func (p *cssParser) parseValue() (string, error) {
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		if value := p.parseIdent(); value != "" {
+			return value, nil
+		}
+		return "", fmt.Errorf("expected value at offset %d", p.pos)
+	}
+
+	p.pos++
+	end := strings.IndexByte(p.input[p.pos:], quote)
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string at offset %d", p.pos-1)
+	}
+	value := p.input[p.pos : p.pos+end]
+	p.pos += end + 1
+	return value, nil
+}
+
+// parseAttribute parses the inside of [...] after the opening bracket
+func (p *cssParser) parseAttribute() (string, error) {
+	p.skipSpace()
+	name := p.parseIdent()
+	if name == "" {
+		return "", fmt.Errorf("expected attribute name at offset %d", p.pos)
+	}
+	attr := "@" + strings.ToLower(name)
+	p.skipSpace()
+
+	if p.consume(']') {
+		return attr, nil
+	}
+
+	op := ""
+	if c := p.peek(); strings.IndexByte("~|^$*", c) >= 0 {
+		op = string(c)
+		p.pos++
+	}
+	if !p.consume('=') {
+		return "", fmt.Errorf("expected '=' in attribute selector at offset %d", p.pos)
+	}
+	p.skipSpace()
+	value, err := p.parseValue()
+	if err != nil {
+		return "", err
+	}
+	p.skipSpace()
+	if !p.consume(']') {
+		return "", fmt.Errorf("expected ']' at offset %d", p.pos)
+	}
+
+	// An empty value never matches a prefix, suffix or substring selector
+	if value == "" && (op == "^" || op == "$" || op == "*") {
+		return "false()", nil
+	}
+
+	literal := xpathLiteral(value)
+	switch op {
+	case "":
+		return attr + "=" + literal, nil
+	case "~":
+		return classCondition(attr, value), nil
+	case "|":
+		return fmt.Sprintf("%s=%s or starts-with(%s, %s)", attr, literal, attr, xpathLiteral(value+"-")), nil
+	case "^":
+		return fmt.Sprintf("starts-with(%s, %s)", attr, literal), nil
+	case "$":
+		return fmt.Sprintf("substring(%s, string-length(%s) - %d) = %s", attr, attr, len(value)-1, literal), nil
+	default: // "*"
+		return fmt.Sprintf("contains(%s, %s)", attr, literal), nil
+	}
+}
+
+// parsePseudoClass parses a pseudo-class after the ':'
+func (p *cssParser) parsePseudoClass(tag string) (string, error) {
+	name := strings.ToLower(p.parseIdent())
+	switch name {
+	case "first-child":
+		return "not(preceding-sibling::*)", nil
+	case "last-child":
+		return "not(following-sibling::*)", nil
+	case "only-child":
+		return "not(preceding-sibling::*) and not(following-sibling::*)", nil
+	case "first-of-type", "last-of-type":
+		if tag == "*" {
+			return "", fmt.Errorf(":%s requires a type selector", name)
+		}
+		axis := "preceding-sibling"
+		if name == "last-of-type" {
+			axis = "following-sibling"
+		}
+		return fmt.Sprintf("not(%s::%s)", axis, tag), nil
+	case "empty":
+		return "not(*) and not(normalize-space())", nil
+	case "nth-child", "nth-of-type":
+		arg, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		sibling := "*"
+		if name == "nth-of-type" {
+			if tag == "*" {
+				return "", fmt.Errorf(":nth-of-type requires a type selector")
+			}
+			sibling = tag
+		}
+		count := fmt.Sprintf("count(preceding-sibling::%s)", sibling)
+		switch strings.ToLower(strings.TrimSpace(arg)) {
+		case "odd":
+			return count + " mod 2 = 0", nil
+		case "even":
+			return count + " mod 2 = 1", nil
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(arg))
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("unsupported :%s argument %q (use a positive number, odd or even)", name, arg)
+		}
+		return fmt.Sprintf("%s = %d", count, n-1), nil
+	case "contains":
+		arg, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		text, err := (&cssParser{input: strings.TrimSpace(arg)}).parseValue()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("contains(string(.), %s)", xpathLiteral(text)), nil
+	case "not":
+		arg, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		inner := &cssParser{input: strings.TrimSpace(arg)}
+		step, err := inner.parseCompound()
+		if err != nil {
+			return "", err
+		}
+		if !inner.done() {
+			return "", fmt.Errorf(":not() accepts a single compound selector")
+		}
+		return "not(self::" + step.expr() + ")", nil
+	case "":
+		return "", fmt.Errorf("expected pseudo-class at offset %d", p.pos)
+	default:
+		return "", fmt.Errorf("unsupported pseudo-class :%s", name)
+	}
+}
+
+// parsePseudoElement parses ::text or ::attr(name) and returns the XPath step to append
+func (p *cssParser) parsePseudoElement() (string, error) {
+	name := strings.ToLower(p.parseIdent())
+	switch name {
+	case "text":
+		return "/text()", nil
+	case "attr":
+		arg, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		attr := strings.TrimSpace(arg)
+		if attr == "" {
+			return "", fmt.Errorf("::attr() requires an attribute name")
+		}
+		return "/@" + strings.ToLower(attr), nil
+	default:
+		return "", fmt.Errorf("unsupported pseudo-element ::%s (use ::text or ::attr(name))", name)
+	}
+}
+
+// parseArgument parses a parenthesized argument, respecting nested parentheses and quotes
+func (p *cssParser) parseArgument() (string, error) {
+	if !p.consume('(') {
+		return "", fmt.Errorf("expected '(' at offset %d", p.pos)
+	}
+	start := p.pos
+	depth := 1
+	var quote byte
+	for !p.done() {
+		c := p.peek()
+		p.pos++
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return p.input[start : p.pos-1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unterminated '(' at offset %d", start-1)
+}
+
+// classCondition matches a whitespace-separated token in an attribute
+func classCondition(attr, token string) string {
+	return fmt.Sprintf("contains(concat(' ', normalize-space(%s), ' '), %s)", attr, xpathLiteral(" "+token+" "))
+}
+
+// xpathLiteral quotes s as an XPath string literal
+func xpathLiteral(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	parts := strings.Split(s, "'")
+	return "concat('" + strings.Join(parts, `', "'", '`) + "')"
+}
@@ -0,0 +1,168 @@
+package gtmlp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+)
+
+const selectorTestHTML = `<html><body>
+<ul id="list">
+	<li class="item featured" data-sku="A-1"><a href="/a">Alpha</a><span class="price">10</span></li>
+	<li class="item" data-sku="B-2"><a href="/b">Beta</a><span class="price">20</span></li>
+	<li class="item sold-out" data-sku="C-3"><a href="/c">Gamma</a></li>
+</ul>
+<p class="note">It's "quoted"</p>
+<div class="pager"><a rel="next" href="?page=2">Next</a></div>
+</body></html>`
+
+// TestCSSToXPath_Matches tests CSS selectors against a document by match count
+func TestCSSToXPath_Matches(t *testing.T) {
+	doc, err := htmlquery.Parse(strings.NewReader(selectorTestHTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		css   string
+		count int
+	}{
+		{"li", 3},
+		{"*", 15},
+		{"#list > li.item", 3},
+		{"ul li.featured", 1},
+		{".item.sold-out", 1},
+		{"li:not(.sold-out)", 2},
+		{"[data-sku]", 3},
+		{"li[data-sku='B-2']", 1},
+		{"li[data-sku^=A]", 1},
+		{"li[data-sku$='-3']", 1},
+		{"li[data-sku*='-']", 3},
+		{"li[class~=featured]", 1},
+		{"li[data-sku|=C]", 1},
+		{"li[data-sku^='']", 0},
+		{"li[data-sku$='']", 0},
+		{"li[data-sku*='']", 0},
+		{"li[data-sku='']", 0},
+		{"li:first-child", 1},
+		{"li:last-child", 1},
+		{"li:nth-child(2)", 1},
+		{"li:nth-child(odd)", 2},
+		{"li:nth-child(even)", 1},
+		{"li:first-of-type > a", 1},
+		{"li.featured + li", 1},
+		{"li.featured ~ li", 2},
+		{"li:contains('Gamma')", 1},
+		{"a, span.price", 6},
+		{"li a::attr(href)", 3},
+		{"li > span::text", 2},
+		{"p:contains(\"It's\")", 1},
+		{"a[rel=next]::attr(href)", 1},
+	}
+
+	for _, tt := range tests {
+		expr, err := compileSelector("css:" + tt.css)
+		if err != nil {
+			t.Errorf("%q: compile failed: %v", tt.css, err)
+			continue
+		}
+		if got := len(htmlquery.QuerySelectorAll(doc, expr)); got != tt.count {
+			xp, _ := cssToXPath(tt.css)
+			t.Errorf("%q (%s): got %d matches, want %d", tt.css, xp, got, tt.count)
+		}
+	}
+}
+
+// TestCSSToXPath_Invalid tests that malformed selectors are rejected
+func TestCSSToXPath_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"li[",
+		"li[data-sku=]",
+		"li:unknown",
+		"li::before",
+		"li:nth-child(2n+1)",
+		":first-of-type",
+		"li,",
+		"li > ",
+		"li:not(a b)",
+		"a::attr()",
+		"li !",
+	}
+	for _, css := range invalid {
+		if xp, err := cssToXPath(css); err == nil {
+			t.Errorf("%q: expected error, got %q", css, xp)
+		}
+	}
+}
+
+// TestXPathLiteral tests quoting of strings with quotes
+func TestXPathLiteral(t *testing.T) {
+	tests := map[string]string{
+		"plain":     "'plain'",
+		"it's":      `"it's"`,
+		`it's "ok"`: `concat('it', "'", 's "ok"')`,
+	}
+	for input, want := range tests {
+		if got := xpathLiteral(input); got != want {
+			t.Errorf("xpathLiteral(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+// TestScrape_CSSSelectors tests CSS selectors for containers, fields and fallbacks
+func TestScrape_CSSSelectors(t *testing.T) {
+	config := &Config{
+		Container:    "css:ul.missing > li",
+		AltContainer: []string{"css:#list > li"},
+		Fields: map[string]FieldConfig{
+			"name":  {XPath: "css:a::text"},
+			"link":  {XPath: "css:a::attr(href)"},
+			"price": {XPath: "css:span.cost::text", AltXPath: []string{".//span[@class='price']/text()"}},
+			"sku":   {XPath: "css:li::attr(data-sku)", AltXPath: []string{"./@data-sku"}},
+		},
+		Timeout: 5 * time.Second,
+	}
+
+	items, err := ScrapeUntyped(context.Background(), selectorTestHTML, config)
+	if err != nil {
+		t.Fatalf("ScrapeUntyped failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(items))
+	}
+	first := items[0]
+	if first["name"] != "Alpha" || first["link"] != "/a" || first["price"] != "10" || first["sku"] != "A-1" {
+		t.Errorf("Unexpected first item: %v", first)
+	}
+}
+
+// TestValidate_CSSSelectors tests that Validate and ValidateXPath check CSS selectors
+func TestValidate_CSSSelectors(t *testing.T) {
+	config := &Config{
+		Container: "css:li[",
+		Fields:    map[string]FieldConfig{"name": {XPath: "css:a::text"}},
+		Timeout:   5 * time.Second,
+	}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected Validate to reject invalid CSS container")
+	}
+
+	results := ValidateXPath(selectorTestHTML, map[string]string{
+		"items": "css:li.item",
+		"bad":   "css:li:unknown",
+		"xpath": "//li",
+	})
+	if !results["items"].Valid || results["items"].MatchCount != 3 {
+		t.Errorf("Expected css:li.item to match 3, got %+v", results["items"])
+	}
+	if results["bad"].Valid {
+		t.Error("Expected invalid CSS selector to be reported")
+	}
+	if results["xpath"].MatchCount != 3 {
+		t.Errorf("Expected XPath to keep working, got %+v", results["xpath"])
+	}
+}
@@ -0,0 +1,447 @@
+package gtmlp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SessionMode selects whether a Session records or replays HTTP traffic
+type SessionMode string
+
+const (
+	// SessionRecord performs real requests and records every exchange
+	SessionRecord SessionMode = "record"
+	// SessionReplay serves recorded responses without network access
+	SessionReplay SessionMode = "replay"
+)
+
+// DefaultRedactedHeaders are the headers whose values a recording session replaces with "REDACTED"
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+// Session records HTTP exchanges to a HAR archive or replays them from one.
+// Set it on Config.Session; it applies to every fetch made with that config,
+// including pagination, detail pages, robots.txt and sitemaps.
+type Session struct {
+	mode    SessionMode
+	path    string
+	mu      sync.Mutex
+	entries []harEntry
+	served  map[string]int      // replay position per request key
+	redact  map[string]struct{} // canonical names of headers redacted when recording
+}
+
+// RecordSession creates a session that records exchanges; call Save to write the archive to path
+func RecordSession(path string) *Session {
+	s := &Session{mode: SessionRecord, path: path}
+	s.RedactHeaders(DefaultRedactedHeaders...)
+	return s
+}
+
+// ReplaySession loads a HAR archive and serves its responses instead of the network
+func ReplaySession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("failed to read session archive: %s", path),
+			Cause:   err,
+		}
+	}
+
+	var archive harArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: fmt.Sprintf("failed to parse session archive: %s", path),
+			Cause:   err,
+		}
+	}
+
+	getLogger().Debug("session archive loaded",
+		"path", path,
+		"entries", len(archive.Log.Entries))
+
+	return &Session{
+		mode:    SessionReplay,
+		path:    path,
+		entries: archive.Log.Entries,
+		served:  make(map[string]int),
+	}, nil
+}
+
+// OpenSession opens a session in the given mode, e.g. from an environment variable.
+// An empty mode returns a nil session, which leaves fetches untouched.
+func OpenSession(path string, mode SessionMode) (*Session, error) {
+	switch mode {
+	case "":
+		return nil, nil
+	case SessionRecord:
+		return RecordSession(path), nil
+	case SessionReplay:
+		return ReplaySession(path)
+	default:
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("invalid session mode: %s (must be 'record' or 'replay')", mode),
+		}
+	}
+}
+
+// Mode returns the session mode
+func (s *Session) Mode() SessionMode {
+	return s.mode
+}
+
+// RedactHeaders replaces the headers whose values are redacted in recorded exchanges.
+// Calling it with no names records every header verbatim.
+func (s *Session) RedactHeaders(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redact = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		s.redact[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+}
+
+// Len returns the number of recorded exchanges
+func (s *Session) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Save writes the recorded exchanges to the archive path. It is a no-op in replay mode.
+func (s *Session) Save() error {
+	if s.mode != SessionRecord {
+		return nil
+	}
+
+	s.mu.Lock()
+	archive := harArchive{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "gtmlp", Version: "2.0"},
+		Entries: s.entries,
+	}}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("failed to write session archive: %s", s.path),
+			Cause:   err,
+		}
+	}
+
+	getLogger().Info("session archive saved",
+		"path", s.path,
+		"entries", len(archive.Log.Entries))
+
+	return nil
+}
+
+// replaying reports whether fetches are served from the archive (nil-safe)
+func (s *Session) replaying() bool {
+	return s != nil && s.mode == SessionReplay
+}
+
+// transport wraps base so requests are recorded or replayed
+func (s *Session) transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &sessionTransport{session: s, base: base}
+}
+
+// sessionTransport is the http.RoundTripper installed by a Session
+type sessionTransport struct {
+	session *Session
+	base    http.RoundTripper
+}
+
+// RoundTrip records the exchange or serves it from the archive
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	if t.session.mode == SessionReplay {
+		return t.session.replay(req, reqBody)
+	}
+	return t.session.record(req, reqBody, t.base)
+}
+
+// sessionKey matches requests by method, normalized URL and body
+func sessionKey(method, url string, body []byte) string {
+	return (&pageRequest{Method: method, URL: url, Body: string(body)}).key()
+}
+
+// record performs the request and appends the exchange to the session
+func (s *Session) record(req *http.Request, reqBody []byte, base http.RoundTripper) (*http.Response, error) {
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	elapsed := time.Since(start)
+
+	entry := harEntry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Time:            float64(elapsed.Microseconds()) / 1000,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: "HTTP/1.1",
+			Headers:     s.harHeaders(req.Header),
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Headers:     s.harHeaders(resp.Header),
+			Content:     harBody(body, resp.Header.Get("Content-Type")),
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Timings: harTimings{Send: 0, Wait: float64(elapsed.Microseconds()) / 1000, Receive: 0},
+	}
+	if len(reqBody) > 0 {
+		entry.Request.PostData = &harPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(reqBody),
+		}
+	}
+
+	s.mu.Lock()
+	s.entries = append(s.entries, entry)
+	s.mu.Unlock()
+
+	getLogger().Debug("session exchange recorded",
+		"url", entry.Request.URL,
+		"method", req.Method,
+		"status", resp.StatusCode)
+
+	return resp, nil
+}
+
+// replay serves the next recorded response for the request. Repeated requests
+// get the matching entries in recorded order, then the last one again.
+func (s *Session) replay(req *http.Request, reqBody []byte) (*http.Response, error) {
+	key := sessionKey(req.Method, req.URL.String(), reqBody)
+
+	s.mu.Lock()
+	var matches []*harEntry
+	for i := range s.entries {
+		entry := &s.entries[i]
+		var postBody []byte
+		if entry.Request.PostData != nil {
+			postBody = []byte(entry.Request.PostData.Text)
+		}
+		if sessionKey(entry.Request.Method, entry.Request.URL, postBody) == key {
+			matches = append(matches, entry)
+		}
+	}
+	var entry *harEntry
+	if len(matches) > 0 {
+		pos := s.served[key]
+		if pos >= len(matches) {
+			pos = len(matches) - 1
+		}
+		entry = matches[pos]
+		s.served[key] = pos + 1
+	}
+	s.mu.Unlock()
+
+	if entry == nil {
+		getLogger().Error("session replay miss",
+			"url", req.URL.String(),
+			"method", req.Method)
+		return nil, &ScrapeError{
+			Type:    ErrTypeNetwork,
+			Message: fmt.Sprintf("no recorded response for %s %s", req.Method, req.URL.String()),
+			URL:     req.URL.String(),
+		}
+	}
+
+	body, err := entry.Response.Content.bytes()
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: "failed to decode recorded response body",
+			URL:     req.URL.String(),
+			Cause:   err,
+		}
+	}
+
+	header := make(http.Header)
+	for _, h := range entry.Response.Headers {
+		header.Add(h.Name, h.Value)
+	}
+
+	getLogger().Debug("session exchange replayed",
+		"url", req.URL.String(),
+		"method", req.Method,
+		"status", entry.Response.Status)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, entry.Response.StatusText),
+		StatusCode:    entry.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// HAR 1.2 archive structures (the subset gtmlp reads and writes)
+
+type harArchive struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Headers     []harHeader  `json:"headers"`
+	PostData    *harPostData `json:"postData,omitempty"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []harHeader `json:"headers"`
+	Content     harContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harHeaders flattens headers into HAR name/value pairs, sorted so archives diff cleanly.
+// Values of redacted headers are replaced.
+func (s *Session) harHeaders(header http.Header) []harHeader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := make([]harHeader, 0, len(header))
+	for _, name := range names {
+		_, redacted := s.redact[http.CanonicalHeaderKey(name)]
+		for _, value := range header[name] {
+			if redacted {
+				value = "REDACTED"
+			}
+			headers = append(headers, harHeader{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+// harBody stores text bodies verbatim and binary bodies as base64
+func harBody(body []byte, mimeType string) harContent {
+	content := harContent{Size: len(body), MimeType: mimeType}
+	if utf8.Valid(body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+	return content
+}
+
+// bytes decodes the recorded body
+func (c harContent) bytes() ([]byte, error) {
+	if c.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(c.Text)
+	}
+	return []byte(c.Text), nil
+}
@@ -0,0 +1,229 @@
+package gtmlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test listing pages for session recording
+const testHTMLSessionPage1 = `<html><body>
+  <div class="item"><h2>One</h2></div>
+  <a class="next" href="/page2">Next</a>
+</body></html>`
+
+const testHTMLSessionPage2 = `<html><body><div class="item"><h2>Two</h2></div></body></html>`
+
+// TestSession_RecordAndReplay tests that a recorded paginated scrape replays without the server
+func TestSession_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page1":
+			fmt.Fprint(w, testHTMLSessionPage1)
+		case "/page2":
+			fmt.Fprint(w, testHTMLSessionPage2)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	archive := filepath.Join(t.TempDir(), "fixtures", "listing.har")
+
+	config := &Config{
+		Container:       "//div[@class='item']",
+		Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: "//a[@class='next']/@href",
+		},
+	}
+
+	recorder := RecordSession(archive)
+	config.Session = recorder
+	recorded, err := ScrapeURLUntyped(context.Background(), server.URL+"/page1", config)
+	if err != nil {
+		t.Fatalf("Record scrape failed: %v", err)
+	}
+	if recorder.Len() != 2 {
+		t.Errorf("Expected 2 recorded exchanges, got %d", recorder.Len())
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// No network from here on
+	server.Close()
+
+	player, err := ReplaySession(archive)
+	if err != nil {
+		t.Fatalf("ReplaySession failed: %v", err)
+	}
+	config.Session = player
+	replayed, err := ScrapeURLUntyped(context.Background(), server.URL+"/page1", config)
+	if err != nil {
+		t.Fatalf("Replay scrape failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("Replayed items differ:\nrecorded: %v\nreplayed: %v", recorded, replayed)
+	}
+	if len(replayed) != 2 {
+		t.Errorf("Expected 2 items, got %d", len(replayed))
+	}
+}
+
+// TestSession_RedactHeaders tests that credentials are not written to the archive
+func TestSession_RedactHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie-secret"})
+		fmt.Fprint(w, `<html><body><div class="item"><h2>One</h2></div></body></html>`)
+	}))
+	defer server.Close()
+	archive := filepath.Join(t.TempDir(), "auth.har")
+
+	recorder := RecordSession(archive)
+	config := &Config{
+		Container:       "//div[@class='item']",
+		Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Session:         recorder,
+		Headers:         map[string]string{"Authorization": "Bearer token-secret", "X-Trace": "trace-1"},
+	}
+	if _, err := ScrapeURLUntyped(context.Background(), server.URL, config); err != nil {
+		t.Fatalf("Record scrape failed: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"token-secret", "cookie-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %q to be redacted from the archive", secret)
+		}
+	}
+	if !strings.Contains(string(data), "trace-1") {
+		t.Error("Expected other headers to be recorded")
+	}
+
+	// Without redaction, headers are recorded verbatim
+	recorder = RecordSession(archive)
+	recorder.RedactHeaders()
+	config.Session = recorder
+	if _, err := ScrapeURLUntyped(context.Background(), server.URL, config); err != nil {
+		t.Fatalf("Record scrape failed: %v", err)
+	}
+	if value := recorder.entries[0].Request.Headers; headerNamed(value, "Authorization") != "Bearer token-secret" {
+		t.Errorf("Expected verbatim Authorization header, got %v", value)
+	}
+}
+
+// headerNamed returns the value of the first HAR header called name
+func headerNamed(headers []harHeader, name string) string {
+	for _, header := range headers {
+		if header.Name == name {
+			return header.Value
+		}
+	}
+	return ""
+}
+
+// TestSession_ReplayMiss tests that unmatched requests fail without retries
+func TestSession_ReplayMiss(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page1":
+			fmt.Fprint(w, testHTMLSessionPage1)
+		case "/page2":
+			fmt.Fprint(w, testHTMLSessionPage2)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	archive := filepath.Join(t.TempDir(), "listing.har")
+
+	recorder := RecordSession(archive)
+	config := &Config{
+		Container:       "//div[@class='item']",
+		Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Session:         recorder,
+	}
+	if _, err := ScrapeURLUntyped(context.Background(), server.URL+"/page2", config); err != nil {
+		t.Fatalf("Record scrape failed: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	server.Close()
+
+	player, err := ReplaySession(archive)
+	if err != nil {
+		t.Fatalf("ReplaySession failed: %v", err)
+	}
+	config.Session = player
+	config.MaxRetries = 3
+
+	start := time.Now()
+	_, err = ScrapeURLUntyped(context.Background(), server.URL+"/unknown", config)
+	var scrapeErr *ScrapeError
+	if !errors.As(err, &scrapeErr) || scrapeErr.Type != ErrTypeNetwork {
+		t.Fatalf("Expected network error for unmatched request, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected replay miss to fail without retry backoff")
+	}
+}
+
+// TestOpenSession tests mode selection
+func TestOpenSession(t *testing.T) {
+	session, err := OpenSession("unused.har", "")
+	if err != nil || session != nil {
+		t.Errorf("Expected nil session for empty mode, got %v, %v", session, err)
+	}
+
+	session, err = OpenSession("out.har", SessionRecord)
+	if err != nil || session.Mode() != SessionRecord {
+		t.Errorf("Expected record session, got %v, %v", session, err)
+	}
+
+	if _, err := OpenSession("missing.har", SessionReplay); err == nil {
+		t.Error("Expected error replaying a missing archive")
+	}
+
+	if _, err := OpenSession("x.har", "bogus"); err == nil {
+		t.Error("Expected error for invalid mode")
+	}
+}
+
+// TestHarBody tests that binary bodies round-trip through base64
+func TestHarBody(t *testing.T) {
+	binary := []byte{0x1f, 0x8b, 0xff, 0x00}
+	content := harBody(binary, "application/octet-stream")
+	if content.Encoding != "base64" {
+		t.Errorf("Expected base64 encoding for binary body, got %q", content.Encoding)
+	}
+	decoded, err := content.bytes()
+	if err != nil || !reflect.DeepEqual(decoded, binary) {
+		t.Errorf("Expected binary body to round-trip, got %v, %v", decoded, err)
+	}
+
+	text := harBody([]byte("<html></html>"), "text/html")
+	if text.Encoding != "" || text.Text != "<html></html>" {
+		t.Errorf("Expected text body stored verbatim, got %+v", text)
+	}
+}
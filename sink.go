@@ -0,0 +1,365 @@
+package gtmlp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink receives scraped items as they are produced.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	// Write persists one item (a struct or a map[string]any)
+	Write(item any) error
+	// Close flushes buffered output. It does not close the underlying writer or database.
+	Close() error
+}
+
+// WriteAll writes every item to sink
+func WriteAll[T any](sink Sink, items []T) error {
+	for _, item := range items {
+		if err := sink.Write(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScrapeURLToSink scrapes a URL (following pagination if configured) and writes
+// each page's items to sink as soon as the page is scraped. The sink is not closed.
+func ScrapeURLToSink[T any](ctx context.Context, url string, config *Config, sink Sink) (*PaginatedResults[T], error) {
+	return ScrapeURLFunc(ctx, url, config, func(page PageResult[T]) error {
+		return WriteAll(sink, page.Items)
+	})
+}
+
+// CrawlToSink runs a crawl and writes each item's data to sink as it arrives. The sink is not closed.
+func CrawlToSink(ctx context.Context, config *CrawlConfig, sink Sink) (*CrawlResult, error) {
+	return CrawlFunc(ctx, config, func(item CrawlItem) error {
+		return sink.Write(item.Data)
+	})
+}
+
+// ConfigColumns returns the output columns for a config: its field names and
+// those of followed detail configs, sorted (see ConfigSchema)
+func ConfigColumns(config *Config) []string {
+	schema := ConfigSchema(config)
+	columns := make([]string, len(schema))
+	for i, field := range schema {
+		columns[i] = field.Name
+	}
+	return columns
+}
+
+// sinkRecord converts an item into a map, using its JSON field names for structs
+func sinkRecord(item any) (map[string]any, error) {
+	if record, ok := item.(map[string]any); ok {
+		return record, nil
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var record map[string]any
+	if err := decoder.Decode(&record); err != nil {
+		return nil, fmt.Errorf("sink item must be a struct or map, got %T", item)
+	}
+	return record, nil
+}
+
+// flattenRecord flattens nested maps into dotted keys ("price.amount") and slices
+// of scalars into "|"-joined strings. Other slices are JSON-encoded.
+func flattenRecord(record map[string]any) map[string]any {
+	flat := make(map[string]any, len(record))
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, child := range v {
+				walk(prefix+"."+key, child)
+			}
+		case []any:
+			flat[prefix] = joinSlice(v)
+		default:
+			flat[prefix] = v
+		}
+	}
+	for key, value := range record {
+		walk(key, value)
+	}
+	return flat
+}
+
+// joinSlice renders a slice as a single cell value
+func joinSlice(values []any) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		switch value.(type) {
+		case map[string]any, []any:
+			data, _ := json.Marshal(values)
+			return string(data)
+		}
+		parts = append(parts, formatCell(value))
+	}
+	return strings.Join(parts, "|")
+}
+
+// formatCell renders a scalar value as text
+func formatCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// JSONLSink writes one JSON object per line
+type JSONLSink struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLSink creates a JSON Lines sink on w
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	buf := bufio.NewWriter(w)
+	return &JSONLSink{w: buf, enc: json.NewEncoder(buf)}
+}
+
+// Write encodes item as one line
+func (s *JSONLSink) Write(item any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(item)
+}
+
+// Close flushes buffered lines
+func (s *JSONLSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Flush()
+}
+
+// CSVSink writes items as CSV rows with a fixed column order.
+// Nested values are flattened (see ConfigColumns for the default columns).
+type CSVSink struct {
+	mu          sync.Mutex
+	w           *csv.Writer
+	columns     []string
+	wroteHeader bool
+}
+
+// NewCSVSink creates a CSV sink on w with the given column order, e.g. ConfigColumns(config)
+func NewCSVSink(w io.Writer, columns []string) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(w), columns: columns}
+}
+
+// Write writes item as one row, writing the header row first
+func (s *CSVSink) Write(item any) error {
+	record, err := sinkRecord(item)
+	if err != nil {
+		return err
+	}
+	flat := flattenRecord(record)
+
+	row := make([]string, len(s.columns))
+	for i, column := range s.columns {
+		row[i] = formatCell(flat[column])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writeHeader(); err != nil {
+		return err
+	}
+	return s.w.Write(row)
+}
+
+// writeHeader writes the header row once
+func (s *CSVSink) writeHeader() error {
+	if s.wroteHeader {
+		return nil
+	}
+	s.wroteHeader = true
+	return s.w.Write(s.columns)
+}
+
+// Close writes the header if no rows were written and flushes
+func (s *CSVSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writeHeader(); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// SQLiteSink writes items into a SQLite table through database/sql.
+// The caller opens db with a SQLite driver of their choice.
+type SQLiteSink struct {
+	mu      sync.Mutex
+	stmt    *sql.Stmt
+	columns []string
+}
+
+// NewSQLiteSink creates table if it does not exist, with one column per config field
+// (INTEGER for toint, REAL for tofloat, TEXT otherwise). If key is set it is the
+// primary key and writes upsert on it; otherwise every write inserts a row.
+func NewSQLiteSink(ctx context.Context, db *sql.DB, table string, config *Config, key string) (*SQLiteSink, error) {
+	columns := ConfigColumns(config)
+	if len(columns) == 0 {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "sqlite sink needs at least one field",
+		}
+	}
+	if key != "" && !slices.Contains(columns, key) {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("sqlite sink key %q is not a config field", key),
+		}
+	}
+
+	types := sqliteColumnTypes(config)
+	definitions := make([]string, len(columns))
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+		definitions[i] = quoted[i] + " " + types[column]
+		if column == key {
+			definitions[i] += " PRIMARY KEY"
+		}
+	}
+
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quoteIdentifier(table), strings.Join(definitions, ", "))
+	if _, err := db.ExecContext(ctx, create); err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("failed to create table %s", table),
+			Cause:   err,
+		}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdentifier(table), strings.Join(quoted, ", "), placeholders)
+	if key != "" {
+		updates := make([]string, 0, len(columns))
+		for i, column := range columns {
+			if column != key {
+				updates = append(updates, fmt.Sprintf("%s = excluded.%s", quoted[i], quoted[i]))
+			}
+		}
+		if len(updates) == 0 {
+			insert += fmt.Sprintf(" ON CONFLICT(%s) DO NOTHING", quoteIdentifier(key))
+		} else {
+			insert += fmt.Sprintf(" ON CONFLICT(%s) DO UPDATE SET %s", quoteIdentifier(key), strings.Join(updates, ", "))
+		}
+	}
+
+	stmt, err := db.PrepareContext(ctx, insert)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("failed to prepare insert into %s", table),
+			Cause:   err,
+		}
+	}
+
+	getLogger().Debug("sqlite sink ready",
+		"table", table,
+		"columns", len(columns),
+		"key", key)
+
+	return &SQLiteSink{stmt: stmt, columns: columns}, nil
+}
+
+// Write inserts or upserts item as one row
+func (s *SQLiteSink) Write(item any) error {
+	record, err := sinkRecord(item)
+	if err != nil {
+		return err
+	}
+	flat := flattenRecord(record)
+
+	args := make([]any, len(s.columns))
+	for i, column := range s.columns {
+		args[i] = sqliteValue(flat[column])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.stmt.Exec(args...)
+	return err
+}
+
+// Close releases the prepared statement
+func (s *SQLiteSink) Close() error {
+	return s.stmt.Close()
+}
+
+// sqliteColumnTypes maps each config field to a SQLite column type from its field type
+func sqliteColumnTypes(config *Config) map[string]string {
+	types := make(map[string]string)
+	for _, field := range ConfigSchema(config) {
+		switch field.Type {
+		case TypeInt, TypeBool, TypeDuration:
+			types[field.Name] = "INTEGER"
+		case TypeFloat:
+			types[field.Name] = "REAL"
+		case TypeDecimal:
+			types[field.Name] = "NUMERIC"
+		default:
+			types[field.Name] = "TEXT"
+		}
+	}
+	return types
+}
+
+// sqliteValue converts a flattened value into a database/sql argument
+func sqliteValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case time.Duration:
+		return int64(v)
+	case nil, string, int, int64, float64, bool:
+		return v
+	default:
+		return formatCell(v)
+	}
+}
+
+// quoteIdentifier quotes a SQL identifier
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
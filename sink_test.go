@@ -0,0 +1,132 @@
+package gtmlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sinkTestProduct struct {
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Tags  []string `json:"tags"`
+}
+
+// TestJSONLSink tests one JSON object per line
+func TestJSONLSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+
+	items := []sinkTestProduct{{Name: "A", Price: 1.5}, {Name: "B", Price: 2}}
+	if err := WriteAll(sink, items); err != nil {
+		t.Fatalf("WriteAll failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != `{"name":"A","price":1.5,"tags":null}` {
+		t.Errorf("Unexpected JSONL output:\n%s", buf.String())
+	}
+}
+
+// TestCSVSink tests column order, flattening and header handling
+func TestCSVSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf, []string{"name", "price", "tags", "seller.name"})
+
+	if err := sink.Write(sinkTestProduct{Name: "Widget, large", Price: 9.99, Tags: []string{"a", "b"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := sink.Write(map[string]any{"name": "Gadget", "seller": map[string]any{"name": "ACME"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	want := "name,price,tags,seller.name\n\"Widget, large\",9.99,a|b,\nGadget,,,ACME\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected CSV output:\n%q\nwant:\n%q", buf.String(), want)
+	}
+
+	// Header is written even without rows
+	buf.Reset()
+	empty := NewCSVSink(&buf, []string{"a", "b"})
+	empty.Close()
+	if buf.String() != "a,b\n" {
+		t.Errorf("Expected header-only output, got %q", buf.String())
+	}
+}
+
+// TestConfigColumns tests sorted columns including followed detail fields
+func TestConfigColumns(t *testing.T) {
+	config := &Config{
+		Fields: map[string]FieldConfig{
+			"title": {XPath: ".//h2"},
+			"link": {XPath: ".//a/@href", Follow: &Config{
+				Fields: map[string]FieldConfig{"sku": {XPath: "//sku"}, "title": {XPath: "//h1"}},
+			}},
+		},
+	}
+
+	want := []string{"link", "sku", "title"}
+	if got := ConfigColumns(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigColumns = %v, want %v", got, want)
+	}
+}
+
+// TestScrapeURLToSink tests that paginated items are streamed to the sink page by page
+func TestScrapeURLToSink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		next := ""
+		if page == "1" {
+			next = `<a class="next" href="?page=2">Next</a>`
+		}
+		fmt.Fprintf(w, `<html><body>
+			<div class="product"><h2>P%s-a</h2></div>
+			<div class="product"><h2>P%s-b</h2></div>%s
+		</body></html>`, page, page, next)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container:       "//div[@class='product']",
+		Fields:          map[string]FieldConfig{"name": {XPath: ".//h2/text()"}},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: "//a[@class='next']/@href",
+		},
+	}
+
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf, ConfigColumns(config))
+	results, err := ScrapeURLToSink[sinkTestProduct](context.Background(), server.URL, config, sink)
+	if err != nil {
+		t.Fatalf("ScrapeURLToSink failed: %v", err)
+	}
+	sink.Close()
+
+	if want := "name\nP1-a\nP1-b\nP2-a\nP2-b\n"; buf.String() != want {
+		t.Errorf("Unexpected CSV output %q, want %q", buf.String(), want)
+	}
+	if results.TotalPages != 2 || results.TotalItems != 4 {
+		t.Errorf("Expected 2 pages and 4 items, got %d and %d", results.TotalPages, results.TotalItems)
+	}
+	if items := results.Items(); len(items) != 0 {
+		t.Errorf("Expected streamed items not to be held in results, got %d", len(items))
+	}
+}
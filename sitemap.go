@@ -0,0 +1,513 @@
+package gtmlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sitemap limits
+const (
+	DefaultSitemapConcurrency = 4
+	maxSitemapDepth           = 5                // Maximum nesting of sitemap indexes
+	maxSitemapSize            = 50 * 1024 * 1024 // Uncompressed size limit from the sitemap protocol
+)
+
+// SitemapURL is a page entry from a sitemap urlset
+type SitemapURL struct {
+	Loc        string
+	LastMod    time.Time // Zero if not given
+	ChangeFreq string
+	Priority   float64
+}
+
+// SitemapOptions filters sitemap entries and controls sitemap scraping
+type SitemapOptions struct {
+	Include     []string  // URL regexes; if set, entries must match at least one
+	Exclude     []string  // URL regexes; matching entries are skipped
+	Since       time.Time // Skip entries last modified before this time (entries without lastmod are kept)
+	Until       time.Time // Skip entries last modified after this time
+	MaxURLs     int       // Maximum entries to return (0 = unlimited)
+	Concurrency int       // Concurrent page scrapes for ScrapeSitemap (default: 4)
+}
+
+// sitemapDocument matches both <urlset> and <sitemapindex> documents
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// sitemapEntry is a <url> or <sitemap> element
+type sitemapEntry struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+// sitemapFilter holds compiled SitemapOptions and the page locations already collected
+type sitemapFilter struct {
+	options *SitemapOptions
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	seen    map[string]bool // Normalized locations, so each page is collected once
+}
+
+// DiscoverSitemaps finds the sitemaps of a site from the Sitemap: lines of its
+// robots.txt, falling back to /sitemap.xml
+func DiscoverSitemaps(ctx context.Context, siteURL string, config *Config) ([]string, error) {
+	robotsURL, err := resolveURL(siteURL, "/robots.txt")
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeNetwork,
+			Message: "invalid site URL",
+			URL:     siteURL,
+			Cause:   err,
+		}
+	}
+
+	var sitemaps []string
+	body, err := fetchHTML(robotsURL, config)
+	if err != nil {
+		getLogger().Warn("robots.txt not available for sitemap discovery",
+			"url", robotsURL,
+			"error", err.Error())
+	} else {
+		sitemaps = parseRobotsTxt(body).sitemaps
+	}
+
+	if len(sitemaps) == 0 {
+		fallback, _ := resolveURL(siteURL, "/sitemap.xml")
+		sitemaps = []string{fallback}
+	}
+
+	getLogger().Info("sitemaps discovered",
+		"site", siteURL,
+		"sitemaps", len(sitemaps))
+
+	return sitemaps, nil
+}
+
+// FetchSitemapURLs fetches a sitemap or sitemap index (optionally gzipped) and returns
+// the page entries that pass the filter. Sitemap indexes are followed recursively.
+func FetchSitemapURLs(ctx context.Context, sitemapURL string, config *Config, options *SitemapOptions) ([]SitemapURL, error) {
+	filter, err := newSitemapFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []SitemapURL
+	visited := make(map[string]bool)
+	if err := collectSitemapURLs(ctx, sitemapURL, config, filter, visited, 0, &entries, nil); err != nil {
+		return entries, err
+	}
+
+	getLogger().Info("sitemap urls collected",
+		"sitemap", sitemapURL,
+		"urls", len(entries))
+
+	return entries, nil
+}
+
+// collectSitemapURLs appends the filtered entries of one sitemap to entries.
+// If failed is non-nil, child sitemaps that fail to fetch are recorded there and skipped.
+func collectSitemapURLs(ctx context.Context, sitemapURL string, config *Config, filter *sitemapFilter, visited map[string]bool, depth int, entries *[]SitemapURL, failed *[]*PaginationError) error {
+	if filter.full(len(*entries)) {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	normalized := normalizeURL(sitemapURL)
+	if visited[normalized] {
+		return nil
+	}
+	visited[normalized] = true
+
+	doc, err := fetchSitemap(sitemapURL, config)
+	if err != nil {
+		return err
+	}
+
+	// Sitemap index: follow child sitemaps
+	if len(doc.Sitemaps) > 0 {
+		if depth >= maxSitemapDepth {
+			getLogger().Warn("sitemap index nesting too deep",
+				"url", sitemapURL,
+				"depth", depth)
+			return nil
+		}
+
+		for _, child := range doc.Sitemaps {
+			loc := strings.TrimSpace(child.Loc)
+			if loc == "" || !filter.modifiedSince(parseLastMod(child.LastMod)) {
+				continue
+			}
+			childURL, err := resolveURL(sitemapURL, loc)
+			if err != nil {
+				continue
+			}
+			err = collectSitemapURLs(ctx, childURL, config, filter, visited, depth+1, entries, failed)
+			if err == nil {
+				continue
+			}
+			if failed == nil || ctx.Err() != nil {
+				return err
+			}
+			recordSitemapFailure(failed, childURL, err)
+		}
+		return nil
+	}
+
+	for _, u := range doc.URLs {
+		entry := SitemapURL{
+			Loc:        strings.TrimSpace(u.Loc),
+			LastMod:    parseLastMod(u.LastMod),
+			ChangeFreq: strings.TrimSpace(u.ChangeFreq),
+		}
+		if p, err := strconv.ParseFloat(strings.TrimSpace(u.Priority), 64); err == nil {
+			entry.Priority = p
+		}
+
+		if entry.Loc == "" || !filter.matches(entry) {
+			continue
+		}
+		normalized := normalizeURL(entry.Loc)
+		if filter.seen[normalized] {
+			continue
+		}
+		filter.seen[normalized] = true
+		*entries = append(*entries, entry)
+		if filter.full(len(*entries)) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// fetchSitemap fetches and parses a sitemap, decompressing gzip content
+func fetchSitemap(sitemapURL string, config *Config) (*sitemapDocument, error) {
+	resp, err := fetch(sitemapURL, config)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSitemapSize))
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeNetwork,
+			Message: "failed to read sitemap",
+			URL:     sitemapURL,
+			Cause:   err,
+		}
+	}
+
+	// .xml.gz sitemaps are served as raw gzip data
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeParsing,
+				Message: "invalid gzip sitemap",
+				URL:     sitemapURL,
+				Cause:   err,
+			}
+		}
+		defer reader.Close()
+
+		data, err = io.ReadAll(io.LimitReader(reader, maxSitemapSize))
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeParsing,
+				Message: "failed to decompress sitemap",
+				URL:     sitemapURL,
+				Cause:   err,
+			}
+		}
+	}
+
+	var doc sitemapDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: "failed to parse sitemap XML",
+			URL:     sitemapURL,
+			Cause:   err,
+		}
+	}
+
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: fmt.Sprintf("unexpected sitemap root element <%s>", doc.XMLName.Local),
+			URL:     sitemapURL,
+		}
+	}
+
+	return &doc, nil
+}
+
+// ScrapeSitemap collects the page URLs of a sitemap and scrapes each one like ScrapeURLWithPages.
+// If sitemapURL is a site root rather than an XML sitemap, its sitemaps are discovered first.
+// Filters and MaxURLs apply across all sitemaps, and each URL is scraped once.
+// Each scraped URL is returned as a page; failed URLs are recorded in Errors and skipped.
+func ScrapeSitemap[T any](ctx context.Context, sitemapURL string, config *Config, options *SitemapOptions) (*PaginatedResults[T], error) {
+	sitemaps := []string{sitemapURL}
+	if !isSitemapURL(sitemapURL) {
+		discovered, err := DiscoverSitemaps(ctx, sitemapURL, config)
+		if err != nil {
+			return nil, err
+		}
+		sitemaps = discovered
+	}
+
+	filter, err := newSitemapFilter(options)
+	if err != nil {
+		return nil, err
+	}
+	// Failed child and discovered sitemaps are skipped; a failed explicit sitemap fails the run
+	var entries []SitemapURL
+	var sitemapFailures []*PaginationError
+	visited := make(map[string]bool)
+	for _, sm := range sitemaps {
+		err := collectSitemapURLs(ctx, sm, config, filter, visited, 0, &entries, &sitemapFailures)
+		if err == nil {
+			continue
+		}
+		if isSitemapURL(sitemapURL) || ctx.Err() != nil {
+			return nil, err
+		}
+		recordSitemapFailure(&sitemapFailures, sm, err)
+	}
+
+	concurrency := DefaultSitemapConcurrency
+	if options != nil && options.Concurrency > 0 {
+		concurrency = options.Concurrency
+	}
+
+	getLogger().Info("sitemap scrape starting",
+		"sitemap", sitemapURL,
+		"urls", len(entries),
+		"concurrency", concurrency)
+
+	ctx, dedup := withDedup(ctx, config)
+	pages := make([]*PageResult[T], len(entries))
+	failures := make([]*PaginationError, len(entries))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, pageURL string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			page, err := scrapeSitemapPage[T](ctx, pageURL, config)
+			if err != nil {
+				getLogger().Warn("sitemap page failed",
+					"url", pageURL,
+					"error", err.Error())
+				failures[i] = &PaginationError{
+					PageURL:    pageURL,
+					PageNumber: i + 1,
+					Cause:      err,
+				}
+				return
+			}
+			page.PageNum = i + 1
+			pages[i] = page
+		}(i, entry.Loc)
+	}
+	wg.Wait()
+
+	results := &PaginatedResults[T]{Pages: []PageResult[T]{}, Errors: sitemapFailures}
+	for i := range entries {
+		if pages[i] != nil {
+			results.Pages = append(results.Pages, *pages[i])
+			results.TotalItems += len(pages[i].Items)
+		}
+		if failures[i] != nil {
+			results.Errors = append(results.Errors, failures[i])
+		}
+	}
+	results.TotalPages = len(results.Pages)
+	results.Duplicates = dedup.count()
+
+	getLogger().Info("sitemap scrape complete",
+		"pages", results.TotalPages,
+		"failed", len(results.Errors),
+		"total_items", results.TotalItems)
+
+	return results, ctx.Err()
+}
+
+// scrapeSitemapPage scrapes one sitemap URL, following pagination if configured.
+// A paginated URL yields its items from every page and the first page's document fields.
+func scrapeSitemapPage[T any](ctx context.Context, pageURL string, config *Config) (*PageResult[T], error) {
+	if config.Pagination == nil {
+		return scrapeSinglePage[T](ctx, pageURL, config)
+	}
+
+	results, err := scrapeWithPagination[T](ctx, pageURL, config, nil)
+	if err != nil {
+		return nil, err
+	}
+	page := &PageResult[T]{
+		URL:       pageURL,
+		Items:     results.Items(),
+		ScrapedAt: time.Now(),
+	}
+	if len(results.Pages) > 0 {
+		page.Document = results.Pages[0].Document
+	}
+	return page, nil
+}
+
+// recordSitemapFailure records a sitemap that could not be fetched; it has no page number
+func recordSitemapFailure(failed *[]*PaginationError, sitemapURL string, err error) {
+	getLogger().Warn("sitemap fetch failed",
+		"url", sitemapURL,
+		"error", err.Error())
+	*failed = append(*failed, &PaginationError{
+		PageURL: sitemapURL,
+		Cause:   err,
+	})
+}
+
+// isSitemapURL reports whether a URL points at an XML sitemap rather than a site root
+func isSitemapURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	path := strings.ToLower(u.Path)
+	return strings.HasSuffix(path, ".xml") || strings.HasSuffix(path, ".xml.gz") || strings.HasSuffix(path, ".gz")
+}
+
+// newSitemapFilter compiles sitemap options
+func newSitemapFilter(options *SitemapOptions) (*sitemapFilter, error) {
+	if options == nil {
+		options = &SitemapOptions{}
+	}
+	filter := &sitemapFilter{options: options, seen: make(map[string]bool)}
+
+	for i, pattern := range options.Include {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("invalid include[%d] pattern", i),
+				Cause:   err,
+			}
+		}
+		filter.include = append(filter.include, re)
+	}
+
+	for i, pattern := range options.Exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeConfig,
+				Message: fmt.Sprintf("invalid exclude[%d] pattern", i),
+				Cause:   err,
+			}
+		}
+		filter.exclude = append(filter.exclude, re)
+	}
+
+	return filter, nil
+}
+
+// matches reports whether a page entry passes the URL and date filters
+func (f *sitemapFilter) matches(entry SitemapURL) bool {
+	if !f.modifiedInRange(entry.LastMod) {
+		return false
+	}
+
+	for _, re := range f.exclude {
+		if re.MatchString(entry.Loc) {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(entry.Loc) {
+			return true
+		}
+	}
+	return false
+}
+
+// modifiedInRange reports whether lastMod is within Since/Until; unknown dates are kept
+func (f *sitemapFilter) modifiedInRange(lastMod time.Time) bool {
+	if !f.modifiedSince(lastMod) {
+		return false
+	}
+	return lastMod.IsZero() || f.options.Until.IsZero() || !lastMod.After(f.options.Until)
+}
+
+// modifiedSince reports whether lastMod is not before Since; unknown dates are kept.
+// Sitemap index children are only checked against Since: their lastmod is that of
+// their newest entry, so older entries inside may still be before Until.
+func (f *sitemapFilter) modifiedSince(lastMod time.Time) bool {
+	return lastMod.IsZero() || f.options.Since.IsZero() || !lastMod.Before(f.options.Since)
+}
+
+// full reports whether MaxURLs entries have been collected
+func (f *sitemapFilter) full(n int) bool {
+	return f.options.MaxURLs > 0 && n >= f.options.MaxURLs
+}
+
+// parseLastMod parses a W3C datetime lastmod value, returning the zero time if invalid
+func parseLastMod(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	getLogger().Debug("invalid sitemap lastmod",
+		"value", value)
+	return time.Time{}
+}
@@ -0,0 +1,348 @@
+package gtmlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test sitemaps: an index with a recent gzipped child and an old one
+const testSitemapIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>/sitemap_products.xml.gz</loc><lastmod>2026-05-01</lastmod></sitemap>
+  <sitemap><loc>/sitemap_old.xml</loc><lastmod>2019-01-01</lastmod></sitemap>
+</sitemapindex>`
+
+// testSitemapProducts is formatted with the server URL
+const testSitemapProducts = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/product/1</loc><lastmod>2026-05-01T10:00:00+00:00</lastmod><priority>0.8</priority></url>
+  <url><loc>%[1]s/product/2</loc><lastmod>2025-01-01</lastmod></url>
+  <url><loc>%[1]s/product/3</loc></url>
+  <url><loc>%[1]s/about</loc></url>
+</urlset>`
+
+// TestFetchSitemapURLs_IndexAndFilters tests sitemap index traversal, gzip and filters
+func TestFetchSitemapURLs_IndexAndFilters(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap_index.xml":
+			fmt.Fprint(w, testSitemapIndex)
+		case "/sitemap_products.xml.gz":
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			fmt.Fprintf(gz, testSitemapProducts, server.URL)
+			gz.Close()
+			w.Header().Set("Content-Type", "application/x-gzip")
+			w.Write(buf.Bytes())
+		case "/sitemap_old.xml":
+			t.Error("Expected old sitemap to be skipped by lastmod filter")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	options := &SitemapOptions{
+		Include: []string{`/product/`},
+		Since:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	entries, err := FetchSitemapURLs(context.Background(), server.URL+"/sitemap_index.xml", config, options)
+	if err != nil {
+		t.Fatalf("FetchSitemapURLs failed: %v", err)
+	}
+
+	// product/2 is too old, about does not match, product/3 has no lastmod and is kept
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d: %v", len(entries), entries)
+	}
+	if entries[0].Loc != server.URL+"/product/1" || entries[0].Priority != 0.8 {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[0].LastMod.IsZero() {
+		t.Error("Expected lastmod to be parsed")
+	}
+	if entries[1].Loc != server.URL+"/product/3" {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+
+	// Until does not skip an index child modified later, only its later entries
+	options = &SitemapOptions{
+		Include: []string{`/product/`},
+		Since:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	entries, err = FetchSitemapURLs(context.Background(), server.URL+"/sitemap_index.xml", config, options)
+	if err != nil {
+		t.Fatalf("FetchSitemapURLs failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Loc != server.URL+"/product/2" || entries[1].Loc != server.URL+"/product/3" {
+		t.Errorf("Expected product/2 and product/3, got %v", entries)
+	}
+}
+
+// TestDiscoverSitemaps tests discovery from robots.txt and the /sitemap.xml fallback
+func TestDiscoverSitemaps(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "User-agent: *\nDisallow: /admin\nSitemap: %s/sitemap_index.xml\n", server.URL)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	sitemaps, err := DiscoverSitemaps(context.Background(), server.URL+"/some/page", config)
+	if err != nil {
+		t.Fatalf("DiscoverSitemaps failed: %v", err)
+	}
+	if len(sitemaps) != 1 || sitemaps[0] != server.URL+"/sitemap_index.xml" {
+		t.Errorf("Unexpected sitemaps: %v", sitemaps)
+	}
+
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+
+	sitemaps, err = DiscoverSitemaps(context.Background(), empty.URL, config)
+	if err != nil {
+		t.Fatalf("DiscoverSitemaps failed: %v", err)
+	}
+	if len(sitemaps) != 1 || sitemaps[0] != empty.URL+"/sitemap.xml" {
+		t.Errorf("Expected /sitemap.xml fallback, got %v", sitemaps)
+	}
+}
+
+// TestScrapeSitemap tests scraping every sitemap URL with a config
+func TestScrapeSitemap(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nSitemap: %s/sitemap_index.xml\n", server.URL)
+		case r.URL.Path == "/sitemap_index.xml":
+			fmt.Fprint(w, testSitemapIndex)
+		case r.URL.Path == "/sitemap_products.xml.gz":
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			fmt.Fprintf(gz, testSitemapProducts, server.URL)
+			gz.Close()
+			w.Header().Set("Content-Type", "application/x-gzip")
+			w.Write(buf.Bytes())
+		case r.URL.Path == "/sitemap_old.xml":
+			t.Error("Expected old sitemap to be skipped by lastmod filter")
+		case r.URL.Path == "/product/3":
+			http.Error(w, "gone", http.StatusGone)
+		case strings.HasPrefix(r.URL.Path, "/product/"):
+			fmt.Fprintf(w, `<html><body><div class="product"><h2>Product %s</h2></div></body></html>`, strings.TrimPrefix(r.URL.Path, "/product/"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container: `//div[@class="product"]`,
+		Fields: map[string]FieldConfig{
+			"name": {XPath: `.//h2/text()`},
+		},
+		DocumentFields: map[string]FieldConfig{
+			"heading": {XPath: `//h2/text()`},
+		},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	type Product struct {
+		Name string `json:"name"`
+	}
+
+	options := &SitemapOptions{
+		Include:     []string{`/product/`},
+		Since:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Concurrency: 2,
+	}
+
+	results, err := ScrapeSitemap[Product](context.Background(), server.URL, config, options)
+	if err != nil {
+		t.Fatalf("ScrapeSitemap failed: %v", err)
+	}
+
+	if results.TotalPages != 2 || results.TotalItems != 2 {
+		t.Errorf("Expected 2 pages and items, got %d and %d", results.TotalPages, results.TotalItems)
+	}
+	if results.Pages[1].Items[0].Name != "Product 2" {
+		t.Errorf("Expected results in sitemap order, got %v", results.Pages)
+	}
+	if results.Pages[0].Document["heading"] != "Product 1" {
+		t.Errorf("Expected document fields on the page, got %v", results.Pages[0].Document)
+	}
+	if len(results.Errors) != 1 || results.Errors[0].PageURL != server.URL+"/product/3" {
+		t.Errorf("Expected product/3 to fail, got %v", results.Errors)
+	}
+}
+
+// TestScrapeSitemap_MultipleSitemaps tests that MaxURLs and dedup apply across all discovered sitemaps
+func TestScrapeSitemap_MultipleSitemaps(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		urlset := func(ids ...string) {
+			fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+			for _, id := range ids {
+				fmt.Fprintf(w, `<url><loc>%s/p/%s</loc></url>`, server.URL, id)
+			}
+			fmt.Fprint(w, `</urlset>`)
+		}
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprintf(w, "Sitemap: %[1]s/a.xml\nSitemap: %[1]s/b.xml\n", server.URL)
+		case "/a.xml":
+			urlset("1", "2")
+		case "/b.xml":
+			urlset("2", "3", "4")
+		default:
+			mu.Lock()
+			hits[r.URL.Path]++
+			mu.Unlock()
+			fmt.Fprintf(w, `<html><body><div class="product"><h2>%s</h2></div></body></html>`, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container:       `//div[@class="product"]`,
+		Fields:          map[string]FieldConfig{"name": {XPath: `.//h2/text()`}},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	results, err := ScrapeSitemap[map[string]any](context.Background(), server.URL, config, &SitemapOptions{MaxURLs: 3})
+	if err != nil {
+		t.Fatalf("ScrapeSitemap failed: %v", err)
+	}
+	if results.TotalPages != 3 {
+		t.Errorf("Expected MaxURLs to limit the scrape to 3 pages, got %d", results.TotalPages)
+	}
+	expected := map[string]int{"/p/1": 1, "/p/2": 1, "/p/3": 1}
+	if !reflect.DeepEqual(hits, expected) {
+		t.Errorf("Expected each URL scraped once, got %v", hits)
+	}
+}
+
+// TestScrapeSitemap_FailedChildSitemap tests that a child sitemap that fails to fetch is recorded and skipped
+func TestScrapeSitemap_FailedChildSitemap(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap_index.xml":
+			fmt.Fprint(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>/missing.xml</loc></sitemap>
+  <sitemap><loc>/products.xml</loc></sitemap>
+</sitemapindex>`)
+		case "/products.xml":
+			fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>%s/p/1</loc></url></urlset>`, server.URL)
+		case "/p/1":
+			fmt.Fprint(w, `<html><body><div class="product"><h2>Product 1</h2></div></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Container:       `//div[@class="product"]`,
+		Fields:          map[string]FieldConfig{"name": {XPath: `.//h2/text()`}},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	results, err := ScrapeSitemap[map[string]any](context.Background(), server.URL+"/sitemap_index.xml", config, nil)
+	if err != nil {
+		t.Fatalf("ScrapeSitemap failed: %v", err)
+	}
+	if results.TotalPages != 1 || results.Pages[0].Items[0]["name"] != "Product 1" {
+		t.Errorf("Expected the remaining sitemap to be scraped, got %v", results.Pages)
+	}
+	if len(results.Errors) != 1 || results.Errors[0].PageURL != server.URL+"/missing.xml" || results.Errors[0].PageNumber != 0 {
+		t.Errorf("Expected the missing sitemap to be recorded, got %v", results.Errors)
+	}
+
+	// A failed explicit sitemap still fails the run
+	if _, err := ScrapeSitemap[map[string]any](context.Background(), server.URL+"/missing.xml", config, nil); err == nil {
+		t.Error("Expected error for a missing sitemap")
+	}
+
+	// FetchSitemapURLs reports child failures
+	if _, err := FetchSitemapURLs(context.Background(), server.URL+"/sitemap_index.xml", config, nil); err == nil {
+		t.Error("Expected FetchSitemapURLs to fail on the missing child sitemap")
+	}
+}
+
+// TestScrapeSitemap_Cancelled tests that a cancelled context stops waiting for a free worker
+func TestScrapeSitemap_Cancelled(t *testing.T) {
+	release := make(chan struct{})
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sitemap.xml" {
+			fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>%[1]s/p/1</loc></url><url><loc>%[1]s/p/2</loc></url></urlset>`, server.URL)
+			return
+		}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := &Config{
+		Container:       `//div[@class="product"]`,
+		Fields:          map[string]FieldConfig{"name": {XPath: `.//h2/text()`}},
+		Timeout:         30 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := ScrapeSitemap[map[string]any](ctx, server.URL+"/sitemap.xml", config, &SitemapOptions{Concurrency: 1})
+	if err == nil {
+		t.Error("Expected context error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to stop the scrape, took %v", elapsed)
+	}
+}
+
+// TestParseLastMod tests W3C datetime parsing
+func TestParseLastMod(t *testing.T) {
+	tests := []string{"2026-05-01", "2026-05-01T10:00:00+02:00", "2026-05-01T10:00+02:00", "2026-05-01T10:00:00.123Z"}
+	for _, value := range tests {
+		if parseLastMod(value).IsZero() {
+			t.Errorf("parseLastMod(%s) returned zero time", value)
+		}
+	}
+
+	if !parseLastMod("yesterday").IsZero() {
+		t.Error("Expected zero time for invalid lastmod")
+	}
+}
@@ -0,0 +1,142 @@
+// Package sqlitetest tests the SQLite sink against a real driver. It is a
+// separate module so the driver stays out of gtmlp's own requirements.
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hanivan/gtmlp"
+	_ "modernc.org/sqlite"
+)
+
+// TestSQLiteSink tests table creation, column types and upsert by key
+func TestSQLiteSink(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	config := &gtmlp.Config{
+		Fields: map[string]gtmlp.FieldConfig{
+			"sku":   {XPath: ".//sku"},
+			"name":  {XPath: ".//h2"},
+			"price": {XPath: ".//price", Pipes: []string{"trim", "tofloat"}},
+			"stock": {XPath: ".//stock", Pipes: []string{"toint"}},
+		},
+	}
+
+	sink, err := gtmlp.NewSQLiteSink(context.Background(), db, "products", config, "sku")
+	if err != nil {
+		t.Fatalf("gtmlp.NewSQLiteSink failed: %v", err)
+	}
+
+	writes := []map[string]any{
+		{"sku": "A1", "name": "Old name", "price": 1.0, "stock": 3},
+		{"sku": "B2", "name": "Other", "price": 2.5, "stock": 0},
+		{"sku": "A1", "name": "New name", "price": 1.25, "stock": 7},
+	}
+	for _, item := range writes {
+		if err := sink.Write(item); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM products`).Scan(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 rows after upsert, got %d", count)
+	}
+
+	var name string
+	var price float64
+	var stock int64
+	if err := db.QueryRow(`SELECT name, price, stock FROM products WHERE sku = 'A1'`).Scan(&name, &price, &stock); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if name != "New name" || price != 1.25 || stock != 7 {
+		t.Errorf("Expected upserted row, got %q %v %v", name, price, stock)
+	}
+
+	var stockType string
+	db.QueryRow(`SELECT type FROM pragma_table_info('products') WHERE name = 'stock'`).Scan(&stockType)
+	if stockType != "INTEGER" {
+		t.Errorf("Expected INTEGER column for toint field, got %q", stockType)
+	}
+
+	if _, err := gtmlp.NewSQLiteSink(context.Background(), db, "products", config, "missing"); err == nil {
+		t.Error("Expected error for key that is not a field")
+	}
+}
+
+// TestSQLiteSink_FieldTypes tests column types and values for typed fields
+func TestSQLiteSink_FieldTypes(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	config := &gtmlp.Config{
+		Fields: map[string]gtmlp.FieldConfig{
+			"sku":     {XPath: ".//sku"},
+			"price":   {XPath: ".//price", Type: gtmlp.TypeDecimal},
+			"active":  {XPath: ".//active", Type: gtmlp.TypeBool},
+			"length":  {XPath: ".//length", Type: gtmlp.TypeDuration},
+			"updated": {XPath: ".//updated", Type: gtmlp.TypeTime},
+		},
+	}
+	sink, err := gtmlp.NewSQLiteSink(context.Background(), db, "items", config, "sku")
+	if err != nil {
+		t.Fatalf("gtmlp.NewSQLiteSink failed: %v", err)
+	}
+	item := map[string]any{
+		"sku":     "A1",
+		"price":   json.Number("19.90"),
+		"active":  true,
+		"length":  90 * time.Second,
+		"updated": time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if err := sink.Write(item); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	sink.Close()
+
+	columnTypes := map[string]string{}
+	rows, err := db.Query(`SELECT name, type FROM pragma_table_info('items')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var name, typ string
+		rows.Scan(&name, &typ)
+		columnTypes[name] = typ
+	}
+	rows.Close()
+	wantTypes := map[string]string{"sku": "TEXT", "price": "NUMERIC", "active": "INTEGER", "length": "INTEGER", "updated": "TEXT"}
+	if !reflect.DeepEqual(columnTypes, wantTypes) {
+		t.Errorf("Column types = %v, want %v", columnTypes, wantTypes)
+	}
+
+	var price float64
+	var active bool
+	var length int64
+	var updated string
+	if err := db.QueryRow(`SELECT price, active, length, updated FROM items`).Scan(&price, &active, &length, &updated); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if price != 19.9 || !active || time.Duration(length) != 90*time.Second || !strings.HasPrefix(updated, "2024-03-01T10:00:00") {
+		t.Errorf("Unexpected row: %v %v %v %q", price, active, length, updated)
+	}
+}
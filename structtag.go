@@ -0,0 +1,548 @@
+package gtmlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// structTag is the struct tag read by ConfigFromStruct, e.g.
+// `gtmlp:"xpath=.//h2/text();alt=.//h3;pipes=trim"`
+const structTag = "gtmlp"
+
+// Struct tag keys for fields and for the "_" marker field
+var (
+	fieldTagKeys  = []string{"xpath", "alt", "pipes", "pipe", "type", "layout", "default", "output"}
+	markerTagKeys = []string{"container", "alt", "parser"}
+)
+
+// Go types with a field type of their own
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+	numberType   = reflect.TypeFor[json.Number]()
+)
+
+// structMarker holds the options of a struct's `_ struct{}` marker field
+type structMarker struct {
+	Container    string
+	AltContainer []string
+	Parser       string
+}
+
+// ConfigFromStruct builds a Config from the gtmlp tags of struct T, so the item type
+// and its selectors are declared together:
+//
+//	type Product struct {
+//		_     struct{} `gtmlp:"container=//div[@class='product']"`
+//		Name  string   `json:"name" gtmlp:"xpath=.//h2/text();pipes=trim"`
+//		Price float64  `json:"price" gtmlp:"xpath=.//span[@class='price'];alt=.//span[@class='sale']"`
+//	}
+//
+// Fields are named by their JSON names. Fields without a gtmlp tag, or tagged "-", are
+// not scraped. Unless the tag sets a type, numeric, bool, time.Time, time.Duration and
+// json.Number fields get the matching field type. Struct fields, and slices of structs,
+// become nested fields. container, if set, replaces the container of the marker field.
+// The result has the same defaults as ParseConfig and is not validated.
+func ConfigFromStruct[T any](container string) (*Config, error) {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("%s is not a struct", t),
+		}
+	}
+
+	fields, marker, err := structFields(t, nil)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("invalid gtmlp tags in %s", t),
+			Cause:   err,
+		}
+	}
+
+	config := &Config{
+		Container:    marker.Container,
+		AltContainer: marker.AltContainer,
+		Parser:       marker.Parser,
+		Fields:       fields,
+		Timeout:      30 * time.Second,
+		UserAgent:    "GTMLP/2.0",
+	}
+	if container != "" {
+		config.Container = container
+		config.AltContainer = nil
+	}
+	return config, nil
+}
+
+// structFields reads the tagged fields and marker of struct type t. parents are the
+// struct types being read, to reject recursive types.
+func structFields(t reflect.Type, parents []reflect.Type) (map[string]FieldConfig, structMarker, error) {
+	var marker structMarker
+	if slices.Contains(parents, t) {
+		return nil, marker, fmt.Errorf("recursive type %s", t)
+	}
+	parents = append(parents, t)
+
+	fields := make(map[string]FieldConfig)
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup(structTag)
+
+		if sf.Name == "_" {
+			if tagged {
+				var err error
+				if marker, err = parseMarkerTag(tag); err != nil {
+					return nil, marker, fmt.Errorf("marker field: %w", err)
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		// Fields of embedded structs are promoted, as in encoding/json
+		if sf.Anonymous && !tagged && jsonName(sf) == "" {
+			embedded := derefType(sf.Type)
+			if embedded.Kind() == reflect.Struct {
+				promoted, _, err := structFields(embedded, parents)
+				if err != nil {
+					return nil, marker, err
+				}
+				for name, field := range promoted {
+					if _, exists := fields[name]; !exists {
+						fields[name] = field
+					}
+				}
+			}
+			continue
+		}
+
+		if !tagged {
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, marker, fmt.Errorf("field %s is unexported", sf.Name)
+		}
+		name := jsonName(sf)
+		if name == "-" {
+			return nil, marker, fmt.Errorf("field %s is not decoded from JSON (json:\"-\")", sf.Name)
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if _, exists := fields[name]; exists {
+			return nil, marker, fmt.Errorf("field %s: duplicate name %q", sf.Name, name)
+		}
+
+		field, err := parseFieldTag(tag)
+		if err != nil {
+			return nil, marker, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+
+		// Structs and slices of structs are nested fields
+		goType := derefType(sf.Type)
+		elemType := goType
+		if goType.Kind() == reflect.Slice || goType.Kind() == reflect.Array {
+			elemType = derefType(goType.Elem())
+		}
+		if elemType.Kind() == reflect.Struct && elemType != timeType {
+			nested, nestedMarker, err := structFields(elemType, parents)
+			if err != nil {
+				return nil, marker, err
+			}
+			field.Fields = nested
+			field.List = elemType != goType
+			if field.XPath == "" {
+				field.XPath = nestedMarker.Container
+				field.AltXPath = append(nestedMarker.AltContainer, field.AltXPath...)
+			}
+		} else {
+			if elemType != goType {
+				return nil, marker, fmt.Errorf("field %s: slices of %s are not supported", sf.Name, goType.Elem())
+			}
+			if field.Type == "" {
+				field.Type = goFieldType(goType)
+			}
+		}
+
+		if field.XPath == "" {
+			return nil, marker, fmt.Errorf("field %s has no xpath", sf.Name)
+		}
+		fields[name] = field
+	}
+	return fields, marker, nil
+}
+
+// jsonName returns the name in a field's json tag ("" if none)
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	return name
+}
+
+// derefType returns the type pointers to t point to
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// goFieldType returns the field type for values of Go type t, or "" for text
+func goFieldType(t reflect.Type) string {
+	switch t {
+	case timeType:
+		return TypeTime
+	case durationType:
+		return TypeDuration
+	case numberType:
+		return TypeDecimal
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeInt
+	case reflect.Float32, reflect.Float64:
+		return TypeFloat
+	case reflect.Bool:
+		return TypeBool
+	}
+	return ""
+}
+
+// parseFieldTag parses a field's gtmlp tag: ";"-separated key=value options.
+// A first option that is not key=value is the xpath.
+func parseFieldTag(tag string) (FieldConfig, error) {
+	var field FieldConfig
+	options, err := parseTagOptions(tag, fieldTagKeys, "xpath")
+	if err != nil {
+		return field, err
+	}
+	for _, option := range options {
+		switch option[0] {
+		case "xpath":
+			field.XPath = option[1]
+		case "alt":
+			field.AltXPath = append(field.AltXPath, option[1])
+		case "pipes":
+			for _, pipe := range strings.Split(option[1], ",") {
+				if pipe = strings.TrimSpace(pipe); pipe != "" {
+					field.Pipes = append(field.Pipes, pipe)
+				}
+			}
+		case "pipe":
+			field.Pipes = append(field.Pipes, option[1])
+		case "type":
+			field.Type = option[1]
+		case "layout":
+			field.Layout = option[1]
+		case "default":
+			field.Default = option[1]
+		case "output":
+			field.Output = option[1]
+		}
+	}
+	return field, nil
+}
+
+// parseMarkerTag parses the gtmlp tag of a `_ struct{}` marker field
+func parseMarkerTag(tag string) (structMarker, error) {
+	var marker structMarker
+	options, err := parseTagOptions(tag, markerTagKeys, "container")
+	if err != nil {
+		return marker, err
+	}
+	for _, option := range options {
+		switch option[0] {
+		case "container":
+			marker.Container = option[1]
+		case "alt":
+			marker.AltContainer = append(marker.AltContainer, option[1])
+		case "parser":
+			marker.Parser = option[1]
+		}
+	}
+	return marker, nil
+}
+
+// parseTagOptions splits a gtmlp tag into key/value pairs. Options are separated by
+// ";" ("\;" is a literal semicolon). A first option without a known key is the value of
+// firstKey, so "//h2" means "xpath=//h2".
+func parseTagOptions(tag string, keys []string, firstKey string) ([][2]string, error) {
+	var options [][2]string
+	for i, part := range splitTag(tag) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if !found || !slices.Contains(keys, key) {
+			if i > 0 {
+				return nil, fmt.Errorf("unknown gtmlp tag option %q (must be %s)", part, strings.Join(keys, ", "))
+			}
+			key, value = firstKey, part
+		}
+		options = append(options, [2]string{key, strings.TrimSpace(value)})
+	}
+	return options, nil
+}
+
+// splitTag splits a gtmlp tag at unescaped semicolons
+func splitTag(tag string) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ';':
+			part.WriteByte(';')
+			i++
+		case tag[i] == ';':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(tag[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// GenerateStruct returns Go source for package packageName declaring a struct type
+// named typeName, with json and gtmlp tags for every field of config, so that
+// ConfigFromStruct gives back its container and fields. Nested fields become struct
+// types named after the parent type and field. Detail page fields and copied document
+// fields are included without gtmlp tags.
+func GenerateStruct(config *Config, packageName, typeName string) (string, error) {
+	g := &structGenerator{imports: make(map[string]bool), typeNames: make(map[string]bool)}
+
+	marker := []string{"container=" + escapeTagValue(config.Container)}
+	for _, alt := range config.AltContainer {
+		marker = append(marker, "alt="+escapeTagValue(alt))
+	}
+	if config.Parser != "" {
+		marker = append(marker, "parser="+config.Parser)
+	}
+
+	// Columns that are not item fields
+	var extra []FieldSchema
+	for _, field := range ConfigSchema(config) {
+		if _, ok := config.Fields[field.Name]; !ok {
+			extra = append(extra, field)
+		}
+	}
+
+	g.typeNames[typeName] = true
+	g.writeStruct(typeName, strings.Join(marker, ";"), config.Fields, extra)
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "package %s\n\n", packageName)
+	switch imports := slices.Sorted(maps.Keys(g.imports)); len(imports) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&src, "import %q\n\n", imports[0])
+	default:
+		src.WriteString("import (\n")
+		for _, path := range imports {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		src.WriteString(")\n\n")
+	}
+	src.WriteString(g.types.String())
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return "", &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: "failed to format generated struct",
+			Cause:   err,
+		}
+	}
+	return string(formatted), nil
+}
+
+// structGenerator accumulates the type declarations of GenerateStruct
+type structGenerator struct {
+	types     strings.Builder
+	imports   map[string]bool
+	typeNames map[string]bool
+}
+
+// writeStruct declares struct type typeName and the types of its nested fields.
+// marker is the gtmlp tag of its `_ struct{}` field ("" for none) and extra are
+// fields declared without gtmlp tags.
+func (g *structGenerator) writeStruct(typeName, marker string, fields map[string]FieldConfig, extra []FieldSchema) {
+	type nestedType struct {
+		name   string
+		fields map[string]FieldConfig
+	}
+	var nested []nestedType
+	goNames := make(map[string]bool)
+
+	var body strings.Builder
+	if marker != "" {
+		fmt.Fprintf(&body, "\t_ struct{} %s\n", structFieldTag("", marker))
+	}
+	for _, name := range sortedFieldNames(fields) {
+		field := fields[name]
+		goName := uniqueName(goIdentifier(name), goNames)
+
+		var goType string
+		options := []string{"xpath=" + escapeTagValue(field.XPath)}
+		for _, alt := range field.AltXPath {
+			options = append(options, "alt="+escapeTagValue(alt))
+		}
+		if isNestedField(field) {
+			nestedName := uniqueName(typeName+goName, g.typeNames)
+			nested = append(nested, nestedType{nestedName, field.Fields})
+			goType = nestedName
+			if field.List {
+				goType = "[]" + nestedName
+			}
+		} else {
+			typ := fieldSchemaType(field)
+			goType = g.goTypeName(typ)
+			options = append(options, fieldTagOptions(field, typ)...)
+		}
+		fmt.Fprintf(&body, "\t%s %s %s\n", goName, goType, structFieldTag(name, strings.Join(options, ";")))
+	}
+	for _, field := range extra {
+		comment := "From a detail page"
+		if field.Document {
+			comment = "Document field"
+		}
+		goName := uniqueName(goIdentifier(field.Name), goNames)
+		fmt.Fprintf(&body, "\t%s %s %s // %s\n", goName, g.goTypeName(field.Type), structFieldTag(field.Name, ""), comment)
+	}
+
+	fmt.Fprintf(&g.types, "type %s struct {\n%s}\n\n", typeName, body.String())
+	for _, n := range nested {
+		g.writeStruct(n.name, "", n.fields, nil)
+	}
+}
+
+// fieldTagOptions returns the gtmlp tag options of a value field after its selectors.
+// typ is the field's schema type; it is left out when the Go type implies it.
+func fieldTagOptions(field FieldConfig, typ string) []string {
+	var options []string
+	if len(field.Pipes) > 0 {
+		if slices.ContainsFunc(field.Pipes, func(pipe string) bool { return strings.Contains(pipe, ",") }) {
+			for _, pipe := range field.Pipes {
+				options = append(options, "pipe="+escapeTagValue(pipe))
+			}
+		} else {
+			options = append(options, "pipes="+escapeTagValue(strings.Join(field.Pipes, ",")))
+		}
+	}
+	implied := ""
+	if t, ok := goTypes[typ]; ok {
+		implied = goFieldType(t)
+	}
+	if typ != implied && (field.Type != "" || typ != TypeString) {
+		options = append(options, "type="+typ)
+	}
+	if field.Layout != "" {
+		options = append(options, "layout="+escapeTagValue(field.Layout))
+	}
+	if field.Default != nil {
+		options = append(options, "default="+escapeTagValue(fmt.Sprint(field.Default)))
+	}
+	if field.Output != "" {
+		options = append(options, "output="+field.Output)
+	}
+	return options
+}
+
+// goTypes maps schema types to the Go types of generated fields (string otherwise)
+var goTypes = map[string]reflect.Type{
+	TypeInt:      reflect.TypeFor[int](),
+	TypeFloat:    reflect.TypeFor[float64](),
+	TypeBool:     reflect.TypeFor[bool](),
+	TypeTime:     timeType,
+	TypeDuration: durationType,
+	TypeDecimal:  numberType,
+	schemaObject: reflect.TypeFor[map[string]any](),
+	schemaList:   reflect.TypeFor[[]map[string]any](),
+}
+
+// goTypeName returns the Go type name for a schema type and records its import
+func (g *structGenerator) goTypeName(typ string) string {
+	t, ok := goTypes[typ]
+	if !ok {
+		return "string"
+	}
+	if t.PkgPath() != "" {
+		g.imports[t.PkgPath()] = true
+	}
+	return t.String()
+}
+
+// goIdentifier converts a field name to an exported Go identifier, e.g. "product_url" to "ProductURL"
+func goIdentifier(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	id := b.String()
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) {
+		id = "Field" + id
+	}
+	return id
+}
+
+// initialisms are written in upper case in generated identifiers
+var initialisms = map[string]bool{
+	"api": true, "css": true, "html": true, "http": true, "id": true, "ip": true,
+	"json": true, "sku": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// uniqueName returns name, or name with a number appended if used has it, and marks it used
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
+
+// escapeTagValue escapes the option separator in a gtmlp tag value
+func escapeTagValue(value string) string {
+	return strings.ReplaceAll(value, ";", `\;`)
+}
+
+// structFieldTag formats a struct tag with optional json and gtmlp keys as a Go literal
+func structFieldTag(jsonName, gtmlp string) string {
+	var keys []string
+	if jsonName != "" {
+		keys = append(keys, "json:"+strconv.Quote(jsonName))
+	}
+	if gtmlp != "" {
+		keys = append(keys, structTag+":"+strconv.Quote(gtmlp))
+	}
+	tag := strings.Join(keys, " ")
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
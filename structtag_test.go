@@ -0,0 +1,214 @@
+package gtmlp
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tagReview struct {
+	Author string `json:"author" gtmlp:".//b"`
+	Text   string `json:"text" gtmlp:"xpath=.//p"`
+}
+
+type tagSeller struct {
+	_      struct{} `gtmlp:"container=.//div[@class='seller']"`
+	Name   string   `json:"name" gtmlp:"xpath=.//a"`
+	Rating float64  `json:"rating" gtmlp:"xpath=.//span[@class='rating']"`
+}
+
+type tagBase struct {
+	Name string `json:"name" gtmlp:"xpath=.//h3; alt=.//h2; pipes=trim"`
+}
+
+type tagProduct struct {
+	_ struct{} `gtmlp:"container=//div[@class='product'];alt=//article"`
+	tagBase
+	Seller   *tagSeller  `json:"seller" gtmlp:""`
+	Reviews  []tagReview `json:"reviews" gtmlp:"xpath=css:li.review"`
+	Internal string      `json:"internal"`
+	Skipped  string      `json:"skipped" gtmlp:"-"`
+}
+
+// TestConfigFromStruct tests building a config from struct tags
+func TestConfigFromStruct(t *testing.T) {
+	config, err := ConfigFromStruct[tagProduct]("")
+	if err != nil {
+		t.Fatalf("ConfigFromStruct failed: %v", err)
+	}
+
+	expected := map[string]FieldConfig{
+		"name": {XPath: ".//h3", AltXPath: []string{".//h2"}, Pipes: []string{"trim"}},
+		"seller": {XPath: ".//div[@class='seller']", Fields: map[string]FieldConfig{
+			"name":   {XPath: ".//a"},
+			"rating": {XPath: ".//span[@class='rating']", Type: TypeFloat},
+		}},
+		"reviews": {XPath: "css:li.review", List: true, Fields: map[string]FieldConfig{
+			"author": {XPath: ".//b"},
+			"text":   {XPath: ".//p"},
+		}},
+	}
+	if !reflect.DeepEqual(config.Fields, expected) {
+		t.Errorf("Expected fields %+v, got %+v", expected, config.Fields)
+	}
+	if config.Container != "//div[@class='product']" || !reflect.DeepEqual(config.AltContainer, []string{"//article"}) || config.Timeout != 30*time.Second {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	results, err := Scrape[tagProduct](context.Background(), nestedTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	first := results[0]
+	if first.Name != "Widget" || first.Seller == nil || first.Seller.Name != "Acme" || first.Seller.Rating != 4.5 ||
+		len(first.Reviews) != 2 || first.Reviews[1].Author != "Bob" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if results[1].Seller != nil || len(results[1].Reviews) != 0 {
+		t.Errorf("Expected no seller or reviews, got %+v", results[1])
+	}
+
+	// A container parameter replaces the marker's
+	config, err = ConfigFromStruct[*tagProduct]("//section")
+	if err != nil || config.Container != "//section" || config.AltContainer != nil {
+		t.Errorf("Expected container parameter to be used, got %+v, %v", config, err)
+	}
+}
+
+// TestConfigFromStruct_Errors tests invalid struct tags
+func TestConfigFromStruct_Errors(t *testing.T) {
+	type unknownOption struct {
+		Name string `gtmlp:"xpath=.//h2;trim"`
+	}
+	type noXPath struct {
+		Name string `gtmlp:"pipes=trim"`
+	}
+	type scalarSlice struct {
+		Tags []string `gtmlp:".//li"`
+	}
+	type recursive struct {
+		Children []recursive `gtmlp:".//li"`
+	}
+
+	tests := []struct {
+		name   string
+		build  func(container string) (*Config, error)
+		errMsg string
+	}{
+		{"unknown option", ConfigFromStruct[unknownOption], "unknown gtmlp tag option"},
+		{"no xpath", ConfigFromStruct[noXPath], "has no xpath"},
+		{"scalar slice", ConfigFromStruct[scalarSlice], "slices of string are not supported"},
+		{"recursive", ConfigFromStruct[recursive], "recursive type"},
+		{"not a struct", ConfigFromStruct[string], "is not a struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build("")
+			if err == nil || !Is(err, ErrTypeConfig) {
+				t.Fatalf("Expected config error, got %v", err)
+			}
+			if msg := err.Error() + " " + errorCause(err); !strings.Contains(msg, tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %q", tt.errMsg, msg)
+			}
+		})
+	}
+}
+
+// errorCause returns the message of a ScrapeError's cause, if any
+func errorCause(err error) string {
+	if scrapeErr, ok := err.(*ScrapeError); ok && scrapeErr.Cause != nil {
+		return scrapeErr.Cause.Error()
+	}
+	return ""
+}
+
+// genItem and genItemTags are the types GenerateStruct writes in TestGenerateStruct
+type genItem struct {
+	_          struct{}      `gtmlp:"container=//div[@class='item'];alt=//article"`
+	Count      int           `json:"count" gtmlp:"xpath=.//li;output=count"`
+	Note       string        `json:"note" gtmlp:"xpath=.//p[@x='a\\;b'];default=n/a"`
+	Price      float64       `json:"price" gtmlp:"xpath=.//span[@class='price'];pipes=trim,tofloat"`
+	ProductURL string        `json:"product_url" gtmlp:"xpath=.//a/@href;type=url"`
+	Published  time.Time     `json:"published" gtmlp:"xpath=.//time;layout=2006-01-02"`
+	Tags       []genItemTags `json:"tags" gtmlp:"xpath=.//li"`
+}
+
+type genItemTags struct {
+	Label string `json:"label" gtmlp:"xpath=."`
+}
+
+// TestGenerateStruct tests Go source generation and reading it back with ConfigFromStruct
+func TestGenerateStruct(t *testing.T) {
+	config := &Config{
+		Container:    "//div[@class='item']",
+		AltContainer: []string{"//article"},
+		Fields: map[string]FieldConfig{
+			"product_url": {XPath: ".//a/@href", Type: TypeURL},
+			"price":       {XPath: ".//span[@class='price']", Pipes: []string{"trim", "tofloat"}},
+			"published":   {XPath: ".//time", Type: TypeTime, Layout: "2006-01-02"},
+			"tags":        {XPath: ".//li", List: true, Fields: map[string]FieldConfig{"label": {XPath: "."}}},
+			"count":       {XPath: ".//li", Output: OutputCount},
+			"note":        {XPath: ".//p[@x='a;b']", Default: "n/a"},
+		},
+	}
+
+	src, err := GenerateStruct(config, "shop", "Item")
+	if err != nil {
+		t.Fatalf("GenerateStruct failed: %v", err)
+	}
+	expected := "package shop\n\n" +
+		"import \"time\"\n\n" +
+		"type Item struct {\n" +
+		"\t_          struct{}   `gtmlp:\"container=//div[@class='item'];alt=//article\"`\n" +
+		"\tCount      int        `json:\"count\" gtmlp:\"xpath=.//li;output=count\"`\n" +
+		"\tNote       string     `json:\"note\" gtmlp:\"xpath=.//p[@x='a\\\\;b'];default=n/a\"`\n" +
+		"\tPrice      float64    `json:\"price\" gtmlp:\"xpath=.//span[@class='price'];pipes=trim,tofloat\"`\n" +
+		"\tProductURL string     `json:\"product_url\" gtmlp:\"xpath=.//a/@href;type=url\"`\n" +
+		"\tPublished  time.Time  `json:\"published\" gtmlp:\"xpath=.//time;layout=2006-01-02\"`\n" +
+		"\tTags       []ItemTags `json:\"tags\" gtmlp:\"xpath=.//li\"`\n" +
+		"}\n\n" +
+		"type ItemTags struct {\n" +
+		"\tLabel string `json:\"label\" gtmlp:\"xpath=.\"`\n" +
+		"}\n"
+	if src != expected {
+		t.Errorf("GenerateStruct =\n%s\nwant\n%s", src, expected)
+	}
+
+	// The generated tags give back the config, with types implied by the Go types
+	roundTrip, err := ConfigFromStruct[genItem]("")
+	if err != nil {
+		t.Fatalf("ConfigFromStruct failed: %v", err)
+	}
+	config.Fields["count"] = FieldConfig{XPath: ".//li", Output: OutputCount, Type: TypeInt}
+	config.Fields["price"] = FieldConfig{XPath: ".//span[@class='price']", Pipes: []string{"trim", "tofloat"}, Type: TypeFloat}
+	if !reflect.DeepEqual(roundTrip.Fields, config.Fields) || roundTrip.Container != config.Container {
+		t.Errorf("Round trip fields = %+v, want %+v", roundTrip.Fields, config.Fields)
+	}
+}
+
+// TestGenerateStruct_Names tests Go identifiers for field names
+func TestGenerateStruct_Names(t *testing.T) {
+	tests := map[string]string{
+		"name":       "Name",
+		"product_id": "ProductID",
+		"imageUrl":   "ImageUrl",
+		"seller.url": "SellerURL",
+		"2nd-price":  "Field2ndPrice",
+		"@type":      "Type",
+	}
+	for name, expected := range tests {
+		if got := goIdentifier(name); got != expected {
+			t.Errorf("goIdentifier(%q) = %q, want %q", name, got, expected)
+		}
+	}
+
+	used := map[string]bool{}
+	if uniqueName("Name", used) != "Name" || uniqueName("Name", used) != "Name2" {
+		t.Error("Expected duplicate names to be numbered")
+	}
+}
@@ -0,0 +1,477 @@
+package gtmlp
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// Structured data selector prefixes. A field XPath with one of these prefixes
+// reads from the page's structured data instead of the DOM, e.g.
+// "jsonld:@Product.offers.price" or "og:title".
+const (
+	jsonLDPrefix    = "jsonld:"
+	microdataPrefix = "microdata:"
+	rdfaPrefix      = "rdfa:"
+	openGraphPrefix = "og:"
+	twitterPrefix   = "twitter:"
+)
+
+// StructuredData holds the machine-readable metadata embedded in a page
+type StructuredData struct {
+	JSONLD    []any          // Decoded JSON-LD objects; arrays and @graph are flattened
+	Microdata []any          // Top-level itemscope items
+	RDFa      []any          // Top-level typeof resources
+	OpenGraph map[string]any // og:* meta values keyed without the prefix; other OpenGraph namespaces (article:, product:) keep theirs
+	Twitter   map[string]any // twitter:* meta values keyed without the prefix
+}
+
+// ExtractStructuredData parses JSON-LD, Microdata, RDFa and OpenGraph/Twitter meta tags from HTML
+func ExtractStructuredData(htmlContent string) (*StructuredData, error) {
+	doc, err := htmlquery.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeParsing,
+			Message: "failed to parse HTML",
+			Cause:   err,
+		}
+	}
+
+	return &StructuredData{
+		JSONLD:    extractJSONLD(doc),
+		Microdata: extractMicrodata(doc),
+		RDFa:      extractRDFa(doc),
+		OpenGraph: extractMetaProperties(doc, isOpenGraphProperty, openGraphPrefix),
+		Twitter:   extractMetaProperties(doc, isTwitterProperty, twitterPrefix),
+	}, nil
+}
+
+// structuredSelector is a parsed structured data field selector
+type structuredSelector struct {
+	prefix     string
+	schemaType string // From a leading "@Type" segment
+	path       string
+}
+
+// isStructuredSelector reports whether selector reads from structured data
+func isStructuredSelector(selector string) bool {
+	for _, prefix := range []string{jsonLDPrefix, microdataPrefix, rdfaPrefix, openGraphPrefix, twitterPrefix} {
+		if strings.HasPrefix(selector, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStructuredSelector splits a structured selector into its source, optional type and path
+func parseStructuredSelector(selector string) (*structuredSelector, error) {
+	prefix, rest, _ := strings.Cut(selector, ":")
+	sel := &structuredSelector{prefix: prefix + ":", path: strings.TrimSpace(rest)}
+
+	switch sel.prefix {
+	case openGraphPrefix, twitterPrefix:
+		// Property names may contain colons (og:image:width), so only the first segment
+		// is the meta property and the rest indexes repeated values
+		if sel.path == "" {
+			return nil, fmt.Errorf("missing property name in %q", selector)
+		}
+		return sel, nil
+	}
+
+	if strings.HasPrefix(sel.path, "@") {
+		end := strings.IndexAny(sel.path, ".[")
+		if end < 0 {
+			end = len(sel.path)
+		}
+		sel.schemaType = sel.path[1:end]
+		sel.path = strings.TrimPrefix(sel.path[end:], ".")
+		if sel.schemaType == "" {
+			return nil, fmt.Errorf("missing type name in %q", selector)
+		}
+	}
+
+	if _, err := splitJSONPath(sel.path); err != nil {
+		return nil, err
+	}
+	return sel, nil
+}
+
+// extractStructuredField evaluates a structured selector within node and returns the value as a string
+func extractStructuredField(node *html.Node, selector string) string {
+	sel, err := parseStructuredSelector(selector)
+	if err != nil {
+		return ""
+	}
+
+	var root any
+	switch sel.prefix {
+	case openGraphPrefix:
+		return lookupMetaProperty(extractMetaProperties(node, isOpenGraphProperty, openGraphPrefix), sel.path)
+	case twitterPrefix:
+		return lookupMetaProperty(extractMetaProperties(node, isTwitterProperty, twitterPrefix), sel.path)
+	case jsonLDPrefix:
+		root = extractJSONLD(node)
+	case microdataPrefix:
+		root = extractMicrodata(node)
+	case rdfaPrefix:
+		root = extractRDFa(node)
+	}
+
+	items, _ := root.([]any)
+	if len(items) == 0 {
+		return ""
+	}
+
+	var target any
+	switch {
+	case sel.schemaType != "":
+		target = findSchemaType(items, sel.schemaType)
+		if target == nil {
+			return ""
+		}
+	case strings.HasPrefix(sel.path, "["):
+		// Index into the list of top-level objects
+		target = items
+	default:
+		target = items[0]
+	}
+
+	value, ok := lookupJSONPath(target, sel.path)
+	if !ok {
+		return ""
+	}
+	return jsonValueString(value)
+}
+
+// lookupMetaProperty resolves "name" or "name[i]" against meta properties
+func lookupMetaProperty(properties map[string]any, path string) string {
+	name, index, _ := strings.Cut(path, "[")
+	value, ok := properties[name]
+	if !ok {
+		return ""
+	}
+	if index == "" {
+		return jsonValueString(value)
+	}
+
+	i, err := strconv.Atoi(strings.TrimSuffix(index, "]"))
+	if err != nil || i < 0 {
+		return ""
+	}
+	values, isList := value.([]any)
+	if !isList {
+		values = []any{value}
+	}
+	if i >= len(values) {
+		return ""
+	}
+	return jsonValueString(values[i])
+}
+
+// findSchemaType returns the first object whose @type matches schemaType, searching
+// lists in order and depth-first (object keys are visited in sorted order)
+func findSchemaType(value any, schemaType string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if schemaTypeMatches(v["@type"], schemaType) {
+			return v
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if found := findSchemaType(v[key], schemaType); found != nil {
+				return found
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			if found := findSchemaType(elem, schemaType); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// schemaTypeMatches reports whether a @type value (string or list) names schemaType.
+// Full type IRIs match on their last segment, so "Product" matches "https://schema.org/Product".
+func schemaTypeMatches(typeValue any, schemaType string) bool {
+	switch v := typeValue.(type) {
+	case string:
+		for _, t := range strings.Fields(v) {
+			if t == schemaType || strings.HasSuffix(t, "/"+schemaType) ||
+				strings.HasSuffix(t, "#"+schemaType) || strings.HasSuffix(t, ":"+schemaType) {
+				return true
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			if schemaTypeMatches(elem, schemaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractJSONLD decodes every application/ld+json script under node.
+// Invalid blocks are logged and skipped.
+func extractJSONLD(node *html.Node) []any {
+	var items []any
+	walkElements(node, func(n *html.Node) bool {
+		if n.Data != "script" || !strings.EqualFold(strings.TrimSpace(htmlquery.SelectAttr(n, "type")), "application/ld+json") {
+			return true
+		}
+
+		data := strings.TrimSpace(htmlquery.InnerText(n))
+		data = strings.TrimSuffix(strings.TrimPrefix(data, "<!--"), "-->")
+		value, err := parseJSON(data)
+		if err != nil {
+			getLogger().Warn("invalid json-ld block skipped",
+				"error", err.Error())
+			return false
+		}
+		items = append(items, flattenJSONLD(value)...)
+		return false
+	})
+	return items
+}
+
+// flattenJSONLD expands top-level arrays and @graph containers into a list of objects
+func flattenJSONLD(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		var items []any
+		for _, elem := range v {
+			items = append(items, flattenJSONLD(elem)...)
+		}
+		return items
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			return flattenJSONLD(graph)
+		}
+		return []any{v}
+	}
+	return nil
+}
+
+// extractMicrodata returns the top-level microdata items under node
+func extractMicrodata(node *html.Node) []any {
+	var items []any
+	walkElements(node, func(n *html.Node) bool {
+		if !hasAttr(n, "itemscope") {
+			return true
+		}
+		items = append(items, microdataItem(n))
+		return false
+	})
+	return items
+}
+
+// microdataItem builds a map from an itemscope element's properties.
+// itemref is not followed.
+func microdataItem(scope *html.Node) map[string]any {
+	item := make(map[string]any)
+	if itemType := htmlquery.SelectAttr(scope, "itemtype"); itemType != "" {
+		item["@type"] = itemType
+	}
+	if itemID := htmlquery.SelectAttr(scope, "itemid"); itemID != "" {
+		item["@id"] = itemID
+	}
+
+	for child := scope.FirstChild; child != nil; child = child.NextSibling {
+		walkElements(child, func(n *html.Node) bool {
+			names := strings.Fields(htmlquery.SelectAttr(n, "itemprop"))
+			nested := hasAttr(n, "itemscope")
+			if len(names) == 0 {
+				// A nested item without itemprop is not a property of this item
+				return !nested
+			}
+
+			var value any
+			if nested {
+				value = microdataItem(n)
+			} else {
+				value = microdataValue(n)
+			}
+			for _, name := range names {
+				addProperty(item, name, value)
+			}
+			return !nested
+		})
+	}
+	return item
+}
+
+// microdataValue returns the value of a non-item itemprop element per the HTML microdata rules
+func microdataValue(n *html.Node) string {
+	if content, ok := attrValue(n, "content"); ok {
+		return content
+	}
+	switch n.Data {
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return htmlquery.SelectAttr(n, "src")
+	case "a", "area", "link":
+		return htmlquery.SelectAttr(n, "href")
+	case "object":
+		return htmlquery.SelectAttr(n, "data")
+	case "data", "meter":
+		return htmlquery.SelectAttr(n, "value")
+	case "time":
+		if datetime, ok := attrValue(n, "datetime"); ok {
+			return datetime
+		}
+	}
+	return strings.TrimSpace(htmlquery.InnerText(n))
+}
+
+// extractRDFa returns the top-level RDFa resources (typeof elements) under node
+func extractRDFa(node *html.Node) []any {
+	var items []any
+	walkElements(node, func(n *html.Node) bool {
+		if !hasAttr(n, "typeof") {
+			return true
+		}
+		items = append(items, rdfaResource(n))
+		return false
+	})
+	return items
+}
+
+// rdfaResource builds a map from a typeof element's properties
+func rdfaResource(scope *html.Node) map[string]any {
+	item := make(map[string]any)
+	if typeOf := strings.TrimSpace(htmlquery.SelectAttr(scope, "typeof")); typeOf != "" {
+		item["@type"] = typeOf
+	}
+	if vocab := htmlquery.SelectAttr(scope, "vocab"); vocab != "" {
+		item["@vocab"] = vocab
+	}
+	if id, ok := attrValue(scope, "resource"); ok {
+		item["@id"] = id
+	} else if id, ok := attrValue(scope, "about"); ok {
+		item["@id"] = id
+	}
+
+	for child := scope.FirstChild; child != nil; child = child.NextSibling {
+		walkElements(child, func(n *html.Node) bool {
+			names := strings.Fields(htmlquery.SelectAttr(n, "property"))
+			nested := hasAttr(n, "typeof")
+			if len(names) == 0 {
+				return !nested
+			}
+
+			var value any
+			if nested {
+				value = rdfaResource(n)
+			} else {
+				value = rdfaValue(n)
+			}
+			for _, name := range names {
+				addProperty(item, name, value)
+			}
+			return !nested
+		})
+	}
+	return item
+}
+
+// rdfaValue returns the value of a property element: content, then a link attribute, then text
+func rdfaValue(n *html.Node) string {
+	if content, ok := attrValue(n, "content"); ok {
+		return content
+	}
+	for _, name := range []string{"resource", "href", "src"} {
+		if value, ok := attrValue(n, name); ok {
+			return value
+		}
+	}
+	if datetime, ok := attrValue(n, "datetime"); ok {
+		return datetime
+	}
+	return strings.TrimSpace(htmlquery.InnerText(n))
+}
+
+// extractMetaProperties collects <meta property|name content> values whose name matches.
+// The prefix is stripped from keys and repeated properties become lists.
+func extractMetaProperties(node *html.Node, match func(string) bool, prefix string) map[string]any {
+	properties := make(map[string]any)
+	walkElements(node, func(n *html.Node) bool {
+		if n.Data != "meta" {
+			return true
+		}
+		name := htmlquery.SelectAttr(n, "property")
+		if name == "" {
+			name = htmlquery.SelectAttr(n, "name")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !match(name) {
+			return false
+		}
+		addProperty(properties, strings.TrimPrefix(name, prefix), htmlquery.SelectAttr(n, "content"))
+		return false
+	})
+	return properties
+}
+
+// isOpenGraphProperty reports whether name belongs to an OpenGraph namespace
+func isOpenGraphProperty(name string) bool {
+	namespace, _, ok := strings.Cut(name, ":")
+	if !ok {
+		return false
+	}
+	switch namespace {
+	case "og", "article", "book", "books", "business", "music", "product", "profile", "video":
+		return true
+	}
+	return false
+}
+
+// isTwitterProperty reports whether name is a Twitter card property
+func isTwitterProperty(name string) bool {
+	return strings.HasPrefix(name, twitterPrefix)
+}
+
+// addProperty sets name to value, turning repeated names into a list
+func addProperty(item map[string]any, name string, value any) {
+	existing, ok := item[name]
+	if !ok {
+		item[name] = value
+		return
+	}
+	if list, isList := existing.([]any); isList {
+		item[name] = append(list, value)
+		return
+	}
+	item[name] = []any{existing, value}
+}
+
+// walkElements visits element nodes under n in document order. Returning false
+// from visit skips the element's children.
+func walkElements(n *html.Node, visit func(*html.Node) bool) {
+	if n.Type == html.ElementNode && !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walkElements(child, visit)
+	}
+}
+
+// hasAttr reports whether n has the attribute, even if it is empty
+func hasAttr(n *html.Node, name string) bool {
+	_, ok := attrValue(n, name)
+	return ok
+}
+
+// attrValue returns an attribute's value and whether it is present
+func attrValue(n *html.Node, name string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,207 @@
+package gtmlp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+)
+
+const structuredHTML = `<html><head>
+  <meta property="og:title" content="Blue Widget">
+  <meta property="og:image" content="https://example.com/a.jpg">
+  <meta property="og:image" content="https://example.com/b.jpg">
+  <meta property="article:published_time" content="2024-05-01">
+  <meta name="twitter:card" content="summary">
+  <script type="application/ld+json">
+  {"@context": "https://schema.org", "@graph": [
+    {"@type": "WebPage", "name": "Widgets", "mainEntity": {"@type": "Product", "name": "Nested Widget"}},
+    {"@type": "Organization", "name": "Acme"}
+  ]}
+  </script>
+  <script type="application/ld+json">
+  {"@context": "https://schema.org", "@type": ["Product", "Thing"], "name": "Blue Widget",
+   "offers": {"@type": "Offer", "price": "19.99", "priceCurrency": "USD"}}
+  </script>
+  <script type="application/ld+json">{ not json </script>
+</head><body>
+  <div itemscope itemtype="https://schema.org/Product">
+    <h1 itemprop="name">Micro Widget</h1>
+    <img itemprop="image" src="/widget.png">
+    <div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+      <meta itemprop="priceCurrency" content="EUR">
+      <span itemprop="price" content="17.50">17,50 €</span>
+    </div>
+    <span itemprop="color">blue</span>
+    <span itemprop="color">navy</span>
+  </div>
+  <div vocab="https://schema.org/" typeof="Person">
+    <span property="name">Jane Doe</span>
+    <a property="url" href="https://example.com/jane">Profile</a>
+    <div property="address" typeof="PostalAddress">
+      <span property="addressLocality">Springfield</span>
+    </div>
+  </div>
+</body></html>`
+
+// TestExtractStructuredData tests extraction of every structured data format
+func TestExtractStructuredData(t *testing.T) {
+	data, err := ExtractStructuredData(structuredHTML)
+	if err != nil {
+		t.Fatalf("ExtractStructuredData failed: %v", err)
+	}
+
+	// @graph is flattened and the invalid block is skipped
+	if len(data.JSONLD) != 3 {
+		t.Fatalf("Expected 3 JSON-LD objects, got %d", len(data.JSONLD))
+	}
+	if name := data.JSONLD[1].(map[string]any)["name"]; name != "Acme" {
+		t.Errorf("Expected second JSON-LD object 'Acme', got %v", name)
+	}
+
+	if len(data.Microdata) != 1 {
+		t.Fatalf("Expected 1 microdata item, got %d", len(data.Microdata))
+	}
+	product := data.Microdata[0].(map[string]any)
+	if product["@type"] != "https://schema.org/Product" || product["name"] != "Micro Widget" {
+		t.Errorf("Unexpected microdata item: %v", product)
+	}
+	if product["image"] != "/widget.png" {
+		t.Errorf("Expected image src, got %v", product["image"])
+	}
+	if offer := product["offers"].(map[string]any); offer["price"] != "17.50" {
+		t.Errorf("Expected nested offer price 17.50, got %v", offer["price"])
+	}
+	if colors, ok := product["color"].([]any); !ok || len(colors) != 2 {
+		t.Errorf("Expected repeated color property as list, got %v", product["color"])
+	}
+
+	if len(data.RDFa) != 1 {
+		t.Fatalf("Expected 1 RDFa resource, got %d", len(data.RDFa))
+	}
+	person := data.RDFa[0].(map[string]any)
+	if person["@type"] != "Person" || person["url"] != "https://example.com/jane" {
+		t.Errorf("Unexpected RDFa resource: %v", person)
+	}
+	if address := person["address"].(map[string]any); address["addressLocality"] != "Springfield" {
+		t.Errorf("Expected nested address, got %v", address)
+	}
+
+	if data.OpenGraph["title"] != "Blue Widget" {
+		t.Errorf("Expected og title, got %v", data.OpenGraph["title"])
+	}
+	if images, ok := data.OpenGraph["image"].([]any); !ok || len(images) != 2 {
+		t.Errorf("Expected two og images, got %v", data.OpenGraph["image"])
+	}
+	if data.OpenGraph["article:published_time"] != "2024-05-01" {
+		t.Errorf("Expected article namespace kept, got %v", data.OpenGraph)
+	}
+	if data.Twitter["card"] != "summary" {
+		t.Errorf("Expected twitter card, got %v", data.Twitter["card"])
+	}
+}
+
+// TestExtractField_StructuredSelectors tests structured data selectors in field XPaths
+func TestExtractField_StructuredSelectors(t *testing.T) {
+	tests := []struct {
+		selector string
+		expected string
+	}{
+		{"jsonld:name", "Widgets"},
+		{"jsonld:[2].name", "Blue Widget"},
+		{"jsonld:@Product.name", "Nested Widget"},
+		{"jsonld:@Offer.price", "19.99"},
+		{"jsonld:@Organization", `{"@type":"Organization","name":"Acme"}`},
+		{"jsonld:@Recipe.name", ""},
+		{"microdata:@Product.offers.priceCurrency", "EUR"},
+		{"microdata:color[1]", "navy"},
+		{"rdfa:@PostalAddress.addressLocality", "Springfield"},
+		{"og:title", "Blue Widget"},
+		{"og:image[1]", "https://example.com/b.jpg"},
+		{"og:article:published_time", "2024-05-01"},
+		{"og:missing", ""},
+		{"twitter:card", "summary"},
+	}
+
+	doc, err := htmlquery.Parse(strings.NewReader(structuredHTML))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got := extractField(doc, tt.selector, "")
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestScrape_DocumentFieldsFromStructuredData tests mixing structured sources with XPath fallbacks
+func TestScrape_DocumentFieldsFromStructuredData(t *testing.T) {
+	config := &Config{
+		Container: `//div[@itemtype="https://schema.org/Product"]`,
+		Fields: map[string]FieldConfig{
+			"name":  {XPath: "microdata:name", AltXPath: []string{".//h1/text()"}},
+			"price": {XPath: "jsonld:@Offer.price", AltXPath: []string{"microdata:@Offer.price"}},
+		},
+		DocumentFields: map[string]FieldConfig{
+			"title": {XPath: "jsonld:@Product.missing", AltXPath: []string{"og:title"}},
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	page, err := ScrapePage[map[string]any](context.Background(), structuredHTML, config)
+	if err != nil {
+		t.Fatalf("ScrapePage failed: %v", err)
+	}
+
+	if len(page.Items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(page.Items))
+	}
+	// Microdata is read within the container, which is the item's own scope
+	if page.Items[0]["name"] != "Micro Widget" {
+		t.Errorf("Expected name 'Micro Widget', got %v", page.Items[0]["name"])
+	}
+	if page.Items[0]["price"] != "17.50" {
+		t.Errorf("Expected price 17.50 from microdata fallback, got %v", page.Items[0]["price"])
+	}
+	if page.Document["title"] != "Blue Widget" {
+		t.Errorf("Expected title from og fallback, got %v", page.Document["title"])
+	}
+}
+
+// TestValidate_StructuredSelectors tests validation of structured data selectors
+func TestValidate_StructuredSelectors(t *testing.T) {
+	valid := []string{"jsonld:name", "jsonld:@Product.offers[0].price", "microdata:@Product", "og:title"}
+	for _, selector := range valid {
+		if err := (&Config{}).validateFieldSelector(selector, syntaxHTML); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", selector, err)
+		}
+	}
+
+	invalid := []string{"jsonld:@.name", "rdfa:items[0", "og:"}
+	for _, selector := range invalid {
+		if err := (&Config{}).validateFieldSelector(selector, syntaxHTML); err == nil {
+			t.Errorf("Expected %q to be invalid", selector)
+		}
+	}
+}
+
+// TestStructuredData_JSON tests that structured data encodes for auditing
+func TestStructuredData_JSON(t *testing.T) {
+	data, err := ExtractStructuredData(`<html><head><meta property="og:type" content="website"></head></html>`)
+	if err != nil {
+		t.Fatalf("ExtractStructuredData failed: %v", err)
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(encoded) != `{"JSONLD":null,"Microdata":null,"RDFa":null,"OpenGraph":{"type":"website"},"Twitter":{}}` {
+		t.Errorf("Unexpected encoding: %s", encoded)
+	}
+}
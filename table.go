@@ -0,0 +1,341 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// tablePrefix marks a container that selects <table> elements whose data rows
+// become items, e.g. "table://table[@id='prices']"
+const tablePrefix = "table:"
+
+// Column selectors map a field to a table column by its header text
+const (
+	columnPrefix      = "column:"    // exact header text, or "#n" for the nth column
+	columnFoldPrefix  = "column-i:"  // case-insensitive header text
+	columnRegexPrefix = "column-re:" // regular expression matched against the header
+)
+
+// Span limits from the HTML table processing model
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// isTableSelector reports whether a container selector selects tables
+func isTableSelector(selector string) bool {
+	return strings.HasPrefix(selector, tablePrefix)
+}
+
+// isColumnSelector reports whether a field selector reads a table column
+func isColumnSelector(selector string) bool {
+	return strings.HasPrefix(selector, columnPrefix) ||
+		strings.HasPrefix(selector, columnFoldPrefix) ||
+		strings.HasPrefix(selector, columnRegexPrefix)
+}
+
+// columnMatcher returns a function reporting whether a column's header matches selector.
+// A header is the list of header texts above the column, from the top header row down;
+// text selectors match the full header joined with " / " or any single level.
+func columnMatcher(selector string) (func(header []string) bool, error) {
+	var equal func(a, b string) bool
+	text := selector
+	switch {
+	case strings.HasPrefix(selector, columnRegexPrefix):
+		re, err := regexp.Compile(strings.TrimPrefix(selector, columnRegexPrefix))
+		if err != nil {
+			return nil, err
+		}
+		return func(header []string) bool {
+			return len(header) > 0 && re.MatchString(strings.Join(header, " / "))
+		}, nil
+	case strings.HasPrefix(selector, columnFoldPrefix):
+		equal = strings.EqualFold
+		text = strings.TrimPrefix(selector, columnFoldPrefix)
+	default:
+		equal = func(a, b string) bool { return a == b }
+		text = strings.TrimPrefix(selector, columnPrefix)
+	}
+
+	name := normalizeSpace(text)
+	if name == "" {
+		return nil, fmt.Errorf("empty column header in %q", selector)
+	}
+	return func(header []string) bool {
+		if len(header) > 0 && equal(strings.Join(header, " / "), name) {
+			return true
+		}
+		for _, level := range header {
+			if equal(level, name) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// columnIndex returns the zero-based column of a "column:#n" selector
+func columnIndex(selector string) (int, bool) {
+	rest, ok := strings.CutPrefix(selector, columnPrefix+"#")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// validateColumnSelector checks a column: selector
+func validateColumnSelector(selector string) error {
+	if _, ok := columnIndex(selector); ok {
+		return nil
+	}
+	_, err := columnMatcher(selector)
+	return err
+}
+
+// htmlTable is a table expanded into a grid, with colspan and rowspan cells
+// repeated in every position they cover
+type htmlTable struct {
+	headers [][]string // Header texts per column, from the top header row down
+	rows    []tableRow // Data rows
+	columns map[string]int
+}
+
+// tableRow is a data row and its cells by column (nil where the row has none)
+type tableRow struct {
+	node  *html.Node
+	cells []*html.Node
+}
+
+// parseTable builds the header and data grid of a table. Header rows are the
+// rows of <thead>, or else the leading rows made of <th> cells only.
+// <tfoot> rows and rows without <td> cells are not data rows.
+func parseTable(table *html.Node) *htmlTable {
+	t := &htmlTable{columns: make(map[string]int)}
+
+	var headerGrid [][]*html.Node
+	haveHeader := false
+	for group := table.FirstChild; group != nil; group = group.NextSibling {
+		if group.Type != html.ElementNode {
+			continue
+		}
+		switch group.Data {
+		case "thead":
+			if haveHeader {
+				continue
+			}
+			headerGrid = expandTableRows(childElements(group, "tr"))
+			haveHeader = len(headerGrid) > 0
+		case "tbody", "tr":
+			rows := []*html.Node{group}
+			if group.Data == "tbody" {
+				rows = childElements(group, "tr")
+			}
+			grid := expandTableRows(rows)
+
+			// Without a <thead>, leading rows of <th> cells are the header
+			if !haveHeader && len(t.rows) == 0 {
+				n := 0
+				for n < len(rows) && isHeaderRow(rows[n]) {
+					n++
+				}
+				headerGrid = grid[:n]
+				rows, grid = rows[n:], grid[n:]
+				haveHeader = n > 0
+			}
+
+			for i, row := range rows {
+				if len(childElements(row, "td")) == 0 {
+					continue
+				}
+				t.rows = append(t.rows, tableRow{node: row, cells: grid[i]})
+			}
+		}
+	}
+
+	// Each column's header lists the distinct cells above it
+	for r, line := range headerGrid {
+		for col, cell := range line {
+			for len(t.headers) <= col {
+				t.headers = append(t.headers, nil)
+			}
+			// A rowspan cell is listed once, at its top row
+			if cell == nil || (r > 0 && col < len(headerGrid[r-1]) && headerGrid[r-1][col] == cell) {
+				continue
+			}
+			if text := normalizeSpace(htmlquery.InnerText(cell)); text != "" {
+				t.headers[col] = append(t.headers[col], text)
+			}
+		}
+	}
+
+	return t
+}
+
+// expandTableRows lays out the cells of a row group in a grid, repeating
+// colspan and rowspan cells. rowspan="0" extends to the end of the group.
+func expandTableRows(rows []*html.Node) [][]*html.Node {
+	grid := make([][]*html.Node, len(rows))
+	for r, row := range rows {
+		col := 0
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || (cell.Data != "td" && cell.Data != "th") {
+				continue
+			}
+
+			// Skip positions filled by rowspans from earlier rows
+			for col < len(grid[r]) && grid[r][col] != nil {
+				col++
+			}
+
+			colspan := spanAttr(cell, "colspan", 1, maxColspan)
+			rowspan := spanAttr(cell, "rowspan", 0, maxRowspan)
+			if rowspan == 0 || r+rowspan > len(rows) {
+				rowspan = len(rows) - r
+			}
+
+			for dr := range rowspan {
+				for len(grid[r+dr]) < col+colspan {
+					grid[r+dr] = append(grid[r+dr], nil)
+				}
+				for dc := range colspan {
+					grid[r+dr][col+dc] = cell
+				}
+			}
+			col += colspan
+		}
+	}
+	return grid
+}
+
+// spanAttr parses a colspan or rowspan attribute, capped at limit. Missing values
+// and values below lowest are 1.
+func spanAttr(cell *html.Node, name string, lowest, limit int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(htmlquery.SelectAttr(cell, name)))
+	if err != nil || n < lowest {
+		return 1
+	}
+	return min(n, limit)
+}
+
+// isHeaderRow reports whether a row has cells and all of them are <th>
+func isHeaderRow(row *html.Node) bool {
+	return len(childElements(row, "th")) > 0 && len(childElements(row, "td")) == 0
+}
+
+// childElements returns the element children of node with the given tag name
+func childElements(node *html.Node, tag string) []*html.Node {
+	var children []*html.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == tag {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// normalizeSpace trims s and collapses runs of whitespace to single spaces
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// column returns the column a column: selector reads, or -1 if no header matches
+func (t *htmlTable) column(selector string) int {
+	if col, ok := t.columns[selector]; ok {
+		return col
+	}
+
+	col := -1
+	if index, ok := columnIndex(selector); ok {
+		col = index
+	} else if match, err := columnMatcher(selector); err == nil {
+		for i, header := range t.headers {
+			if match(header) {
+				col = i
+				break
+			}
+		}
+	}
+
+	t.columns[selector] = col
+	return col
+}
+
+// cellValue returns a row's cell in the column selected by selector, formatted by an output mode
+func (t *htmlTable) cellValue(row tableRow, selector, output string) string {
+	col := t.column(selector)
+	var cell *html.Node
+	if col >= 0 && col < len(row.cells) {
+		cell = row.cells[col]
+	}
+
+	if output == OutputCount {
+		if cell == nil {
+			return ""
+		}
+		return "1"
+	}
+	if cell == nil {
+		return ""
+	}
+	return nodeOutput(cell, output)
+}
+
+// extractTableItems extracts one item per data row of the tables selected by a
+// table: container. column: fields read cells by header; other selectors are relative to the row.
+func extractTableItems(ctx context.Context, doc *html.Node, config *Config) ([]map[string]any, error) {
+	tables := findContainerMatches(config.Container, config.AltContainer, func(selector string) []*html.Node {
+		expr, err := compileSelector(strings.TrimPrefix(selector, tablePrefix))
+		if err != nil {
+			return nil
+		}
+		return htmlquery.QuerySelectorAll(doc, expr)
+	})
+
+	results := []map[string]any{}
+	for _, tableNode := range tables {
+		table := parseTable(tableNode)
+		getLogger().Debug("table parsed",
+			"columns", len(table.headers),
+			"rows", len(table.rows))
+
+		for _, row := range table.rows {
+			fieldData := make(map[string]any, len(config.Fields))
+			for fieldName, fieldConfig := range config.Fields {
+				if isNestedField(fieldConfig) {
+					value, err := extractNestedHTML(ctx, row.node, fieldConfig)
+					if err != nil {
+						return nil, err
+					}
+					fieldData[fieldName] = value
+					continue
+				}
+				value, err := extractFieldValue(ctx, fieldConfig, func(selector string) any {
+					if isColumnSelector(selector) {
+						return table.cellValue(row, selector, fieldConfig.Output)
+					}
+					if isScriptSelector(selector) {
+						return extractScriptField(ctx, row.node, selector)
+					}
+					return extractField(row.node, selector, fieldConfig.Output)
+				})
+				if err != nil {
+					return nil, err
+				}
+				fieldData[fieldName] = value
+			}
+			results = append(results, fieldData)
+		}
+	}
+
+	return results, nil
+}
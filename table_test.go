@@ -0,0 +1,174 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+)
+
+const tableTestHTML = `<html><body>
+<table id="prices">
+  <thead>
+    <tr><th rowspan="2">Product</th><th rowspan="2">Category</th><th colspan="2">Unit Price</th><th rowspan="2">In stock</th></tr>
+    <tr><th>USD</th><th>EUR</th></tr>
+  </thead>
+  <tbody>
+    <tr><td><a href="/p/1">Widget</a></td><td rowspan="2">Tools</td><td>9.50</td><td>8.75</td><td>yes</td></tr>
+    <tr><td><a href="/p/2">Gadget</a></td><td colspan="2">20</td><td>no</td></tr>
+    <tr><th colspan="5">Discontinued</th></tr>
+    <tr><td><a href="/p/3">Gizmo</a></td><td>Misc</td><td>1</td><td>0.9</td></tr>
+  </tbody>
+  <tfoot><tr><td>Total</td><td></td><td>30.50</td><td></td><td></td></tr></tfoot>
+</table>
+<table class="plain">
+  <tr><th>Name</th><th>Qty</th></tr>
+  <tr><td>Bolt</td><td>4</td></tr>
+</table>
+</body></html>`
+
+// TestParseTable tests header detection and colspan/rowspan expansion
+func TestParseTable(t *testing.T) {
+	doc, err := htmlquery.Parse(strings.NewReader(tableTestHTML))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	table := parseTable(htmlquery.FindOne(doc, "//table[@id='prices']"))
+
+	expectedHeaders := [][]string{
+		{"Product"},
+		{"Category"},
+		{"Unit Price", "USD"},
+		{"Unit Price", "EUR"},
+		{"In stock"},
+	}
+	if fmt.Sprint(table.headers) != fmt.Sprint(expectedHeaders) {
+		t.Errorf("Expected headers %v, got %v", expectedHeaders, table.headers)
+	}
+
+	// The section row of <th> cells and the footer are not data rows
+	if len(table.rows) != 3 {
+		t.Fatalf("Expected 3 data rows, got %d", len(table.rows))
+	}
+	var cells []string
+	for _, cell := range table.rows[1].cells {
+		cells = append(cells, strings.TrimSpace(htmlquery.InnerText(cell)))
+	}
+	// Category spans down from the row above and the price spans both currencies
+	if strings.Join(cells, "|") != "Gadget|Tools|20|20|no" {
+		t.Errorf("Unexpected expanded row: %v", cells)
+	}
+	if table.column("column:#3") != 2 || table.column("column:Missing") != -1 {
+		t.Errorf("Unexpected column lookups")
+	}
+}
+
+// TestScrape_TableColumns tests mapping fields to columns by header text
+func TestScrape_TableColumns(t *testing.T) {
+	type priceRow struct {
+		Product  string  `json:"product"`
+		Link     string  `json:"link"`
+		Category string  `json:"category"`
+		USD      float64 `json:"usd"`
+		EUR      float64 `json:"eur"`
+		Stock    string  `json:"stock"`
+	}
+
+	config := &Config{
+		Container: "table://table[@id='prices']",
+		Fields: map[string]FieldConfig{
+			"product":  {XPath: "column:Product"},
+			"link":     {XPath: ".//a/@href"},
+			"category": {XPath: "column-i:CATEGORY"},
+			"usd":      {XPath: "column:Unit Price / USD", Pipes: []string{"tofloat"}},
+			"eur":      {XPath: "column-re:(?i)price.*eur", Pipes: []string{"tofloat"}},
+			"stock":    {XPath: "column:Available", AltXPath: []string{"column:#5"}, Pipes: []string{"trim"}},
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	results, err := Scrape[priceRow](context.Background(), tableTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+
+	expected := []priceRow{
+		{Product: "Widget", Link: "/p/1", Category: "Tools", USD: 9.5, EUR: 8.75, Stock: "yes"},
+		{Product: "Gadget", Link: "/p/2", Category: "Tools", USD: 20, EUR: 20, Stock: "no"},
+		{Product: "Gizmo", Link: "/p/3", Category: "Misc", USD: 1, EUR: 0.9, Stock: ""},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(expected), len(results), results)
+	}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("Row %d: expected %+v, got %+v", i, expected[i], results[i])
+		}
+	}
+}
+
+// TestScrape_TableHeaderInFirstRow tests tables whose header is a first row of <th> cells
+func TestScrape_TableHeaderInFirstRow(t *testing.T) {
+	config := &Config{
+		Container: "table:css:table.plain",
+		Fields: map[string]FieldConfig{
+			"qty":  {XPath: "column:Qty", Pipes: []string{"toint"}},
+			"name": {XPath: "column:name", AltXPath: []string{"column-i:name"}},
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	results, err := ScrapeUntyped(context.Background(), tableTestHTML, config)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 1 || results[0]["name"] != "Bolt" || results[0]["qty"] != 4 {
+		t.Errorf("Unexpected results: %v", results)
+	}
+}
+
+// TestValidate_TableSelectors tests validation of table containers and column selectors
+func TestValidate_TableSelectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		container string
+		alt       []string
+		field     string
+		parser    string
+		errMsg    string
+	}{
+		{"valid", "table://table", nil, "column:Price", "", ""},
+		{"valid regex", "table:css:table", nil, "column-re:^Pri", "", ""},
+		{"column without table", "//tr", nil, "column:Price", "", "invalid xpath"},
+		{"bad regex", "table://table", nil, "column-re:(", "", "invalid xpath"},
+		{"empty header", "table://table", nil, "column-i: ", "", "invalid xpath"},
+		{"invalid table xpath", "table://table[", nil, "column:Price", "", "invalid container"},
+		{"mixed alt container", "table://table", []string{"//tr"}, "column:Price", "", "altContainer[0]"},
+		{"json parser", "table://table", nil, "column:Price", ParserJSON, "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Container:    tt.container,
+				AltContainer: tt.alt,
+				Parser:       tt.parser,
+				Fields:       map[string]FieldConfig{"price": {XPath: tt.field}},
+				Timeout:      30 * time.Second,
+			}
+			err := config.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
@@ -29,6 +29,15 @@ type FieldConfig struct {
 	XPath    string
 	AltXPath []string
 	Pipes    []string
+	Output   string  // "text" (default), "html", "outer-html", "lines", "normalized", "own-text" or "count"
+	Type     string  // Optional value type: "string", "int", "float", "bool", "time", "duration", "url" or "decimal"
+	Layout   string  // Time layout for type "time" (Go reference time, e.g. "02/01/2006")
+	Default  any     // Value used when every selector is empty
+	Follow   *Config // Optional detail page config; the field value is the detail URL
+
+	// Nested objects
+	Fields map[string]FieldConfig // Fields relative to the element XPath selects; the value is an object of them
+	List   bool                   // With Fields, one object per matching element instead of the first match
 }
 
 // Config holds scraping configuration
@@ -38,12 +47,28 @@ type Config struct {
 	AltContainer []string               // Alternative container selectors
 	Fields       map[string]FieldConfig // Field name → FieldConfig
 
+	// Body parsing
+	Parser     string            // "html", "json", "xml" or "auto" to choose from the response Content-Type (default: html)
+	Namespaces map[string]string // XML namespace prefix → URI for prefixed names in XPaths (xml parser)
+
 	// Pagination
 	Pagination *PaginationConfig // Optional pagination configuration
 
+	// Embedded script data
+	Scripts map[string]ScriptConfig // Named JSON values embedded in <script> tags, read with "script:<name>.<path>" selectors
+
+	// Page-level fields
+	DocumentFields     map[string]FieldConfig // Fields extracted once per page from the whole document
+	CopyDocumentFields bool                   // Also copy document field values into each item
+
+	// Item identity
+	Dedup *DedupConfig // Optional item ID stamping and duplicate removal
+
 	// Security options
 	URLValidator    func(string) error // Optional custom URL validation function
 	AllowPrivateIPs bool               // Allow scraping private/internal IPs (default: false)
+	RespectRobots   bool               // Honor robots.txt rules and Crawl-delay (default: false)
+	RobotsUserAgent string             // User agent matched against robots.txt (default: UserAgent)
 
 	// HTTP options
 	Timeout    time.Duration
@@ -52,6 +77,32 @@ type Config struct {
 	MaxRetries int
 	Proxy      string
 	Headers    map[string]string
+	RateLimit  time.Duration // Minimum delay between requests to the same host
+
+	// Response cache
+	Cache    ResponseCache // Stores responses between fetches (default: nil, no caching)
+	CacheTTL time.Duration // Serve cached responses younger than this without a request (default: 0, always revalidate)
+
+	// Record/replay
+	Session *Session    // Records or replays every HTTP exchange (default: nil)
+	WARC    *WARCWriter // Archives every HTTP exchange as WARC records (default: nil)
+
+	// Detail pages
+	FollowConcurrency int // Maximum concurrent detail page fetches (default: 4)
+}
+
+// ScriptConfig locates a JSON or JavaScript object literal inside a script tag.
+// The literal is decoded without executing any JavaScript.
+type ScriptConfig struct {
+	XPath   string // Script elements to search (default: "//script")
+	Pattern string // Regex locating the literal: it starts at the first capture group or right after the match (default: the whole script text)
+}
+
+// DedupConfig defines item identity and duplicate removal
+type DedupConfig struct {
+	KeyFields []string  // Fields that identify an item (default: all fields)
+	IDField   string    // Field stamped with the stable item ID (default: "_id")
+	Store     SeenStore // Remembers IDs across runs (default: in-memory, per scrape)
 }
 
 // PartialResult contains data and field-level errors
@@ -65,20 +116,49 @@ type PipeFunc func(ctx context.Context, input string, params []string) (any, err
 
 // PaginationConfig defines pagination behavior
 type PaginationConfig struct {
-	Type         string        // "next-link" or "numbered"
-	NextSelector string        // XPath for next link (next-link type)
-	AltSelectors []string      // Fallback selectors for next link
-	PageSelector string        // XPath for all page links (numbered type)
-	Pipes        []string      // URL transformation pipes
-	MaxPages     int           // Maximum pages to scrape (default: 100)
-	Timeout      time.Duration // Total pagination timeout (default: 10m)
+	Type             string            // "next-link", "numbered", "link-header", "json" or "form"
+	NextSelector     string            // XPath for next link (next-link type) or next-page control (form type)
+	AltSelectors     []string          // Fallback selectors for next link
+	FormSelector     string            // XPath for the form to submit (form type)
+	FormFields       map[string]string // Form field overrides for the next page, {page} is replaced with its number (form type)
+	PageSelector     string            // XPath for all page links (numbered type)
+	LastPageSelector string            // XPath for the highest page number; generates the full page range (numbered type)
+	LastPagePipes    []string          // Pipes applied to the last page number before parsing
+	PageURLPattern   string            // Page URL with a {page} placeholder; learned from page links if empty
+	LinkRel          string            // Link header relation to follow (link-header type, default: "next")
+	JSONPath         string            // Path to next URL or cursor in the JSON body (json type), e.g. "meta.next_cursor"
+	CursorParam      string            // Query parameter that receives the cursor (json type); if empty the value is a URL
+	Pipes            []string          // URL transformation pipes
+	MaxPages         int               // Maximum pages to scrape (default: 100)
+	Timeout          time.Duration     // Total pagination timeout (default: 10m)
+	OnError          string            // "abort" (default) or "skip" failed pages and continue
 }
 
+// Pagination error policies
+const (
+	OnErrorAbort = "abort"
+	OnErrorSkip  = "skip"
+)
+
 // PaginatedResults contains page-separated scraping results
 type PaginatedResults[T any] struct {
 	Pages      []PageResult[T]
 	TotalPages int
 	TotalItems int
+	Errors     []*PaginationError // Pages skipped under the "skip" error policy
+	Duplicates int                // Items dropped as duplicates (see Config.Dedup)
+}
+
+// Items returns the combined items from all pages
+func (r *PaginatedResults[T]) Items() []T {
+	if r == nil {
+		return nil
+	}
+	var items []T
+	for _, page := range r.Pages {
+		items = append(items, page.Items...)
+	}
+	return items
 }
 
 // PageResult contains results from a single page
@@ -86,13 +166,14 @@ type PageResult[T any] struct {
 	URL       string
 	PageNum   int
 	Items     []T
+	Document  map[string]any // Document field values (see Config.DocumentFields)
 	ScrapedAt time.Time
 }
 
 // PaginationInfo contains extracted pagination URLs
 type PaginationInfo struct {
 	URLs    []string // All discovered page URLs
-	Type    string   // "next-link" or "numbered"
+	Type    string   // Pagination type
 	BaseURL string   // Original base URL
 }
 
@@ -100,7 +181,7 @@ type PaginationInfo struct {
 type PaginationError struct {
 	PageURL      string // URL that failed
 	PageNumber   int    // Page number (1-indexed)
-	PartialData  any    // Items scraped before failure
+	PartialData  any    // Items scraped before failure ([]T)
 	TotalScraped int    // Total items before failure
 	Cause        error  // Underlying error
 }
@@ -110,6 +191,10 @@ func (e *PaginationError) Error() string {
 		e.PageNumber, e.PageURL, e.Cause)
 }
 
+func (e *PaginationError) Unwrap() error {
+	return e.Cause
+}
+
 // WithURL adds the base URL to context for parseUrl pipe
 func WithURL(ctx context.Context, url string) context.Context {
 	return context.WithValue(ctx, contextKey("baseURL"), url)
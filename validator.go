@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/antchfx/htmlquery"
-	"github.com/antchfx/xpath"
 )
 
 // ValidationResult represents XPath validation result for config-based validation
@@ -38,8 +37,8 @@ func ValidateXPath(html string, xpaths map[string]string) map[string]ValidationR
 			XPath: xpathExpr,
 		}
 
-		// Try to compile the XPath to check syntax
-		_, err := xpath.Compile(xpathExpr)
+		// Try to compile the XPath (or css: selector) to check syntax
+		expr, err := compileSelector(xpathExpr)
 		if err != nil {
 			result.Valid = false
 			result.Error = err
@@ -48,7 +47,7 @@ func ValidateXPath(html string, xpaths map[string]string) map[string]ValidationR
 		}
 
 		// Test the expression by finding nodes
-		nodes := htmlquery.Find(doc, xpathExpr)
+		nodes := htmlquery.QuerySelectorAll(doc, expr)
 		result.Valid = true
 		result.MatchCount = len(nodes)
 		results[field] = result
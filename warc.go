@@ -0,0 +1,470 @@
+package gtmlp
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WARC record types written and read by gtmlp
+const (
+	WARCTypeInfo     = "warcinfo"
+	WARCTypeRequest  = "request"
+	WARCTypeResponse = "response"
+)
+
+// WARCWriter writes WARC 1.1 records. When compress is set each record is a
+// separate gzip member, as in .warc.gz files. Safe for concurrent use.
+type WARCWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	closer   io.Closer
+	compress bool
+}
+
+// NewWARCWriter creates a WARC writer on w
+func NewWARCWriter(w io.Writer, compress bool) *WARCWriter {
+	return &WARCWriter{w: w, compress: compress}
+}
+
+// CreateWARCFile creates a WARC file at path, gzip-compressed when path ends in .gz,
+// and writes a warcinfo record. Close the writer when done.
+func CreateWARCFile(path string) (*WARCWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("failed to create WARC file: %s", path),
+			Cause:   err,
+		}
+	}
+
+	writer := NewWARCWriter(f, strings.HasSuffix(path, ".gz"))
+	writer.closer = f
+
+	info := "software: gtmlp/2.0\r\nformat: WARC File Format 1.1\r\n"
+	if err := writer.writeRecord(WARCTypeInfo, "", "application/warc-fields", nil, []byte(info)); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// Close closes the underlying file if the writer owns one
+func (w *WARCWriter) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
+
+// WriteExchange writes a request record and a response record for one HTTP exchange.
+// body is the decoded response body; resp.Body is not read.
+func (w *WARCWriter) WriteExchange(req *http.Request, reqBody []byte, resp *http.Response, body []byte) error {
+	targetURI := req.URL.String()
+
+	reqBlock, err := httputil.DumpRequestOut(cloneRequestWithBody(req, reqBody), true)
+	if err != nil {
+		return err
+	}
+	responseID := newWARCRecordID()
+	reqHeaders := map[string]string{"WARC-Concurrent-To": responseID}
+	if err := w.writeRecord(WARCTypeRequest, targetURI, "application/http;msgtype=request", reqHeaders, reqBlock); err != nil {
+		return err
+	}
+
+	// Headers describing the wire encoding no longer apply to the decoded body
+	header := resp.Header.Clone()
+	header.Del("Content-Encoding")
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "HTTP/%d.%d %s\r\n", max(resp.ProtoMajor, 1), resp.ProtoMinor, statusLine(resp))
+	header.Write(&block)
+	block.WriteString("\r\n")
+	block.Write(body)
+
+	digest := sha1.Sum(body)
+	respHeaders := map[string]string{
+		"WARC-Record-ID":      responseID,
+		"WARC-Payload-Digest": "sha1:" + base32.StdEncoding.EncodeToString(digest[:]),
+	}
+	return w.writeRecord(WARCTypeResponse, targetURI, "application/http;msgtype=response", respHeaders, block.Bytes())
+}
+
+// writeRecord writes one WARC record; extra headers override the generated ones
+func (w *WARCWriter) writeRecord(recordType, targetURI, contentType string, extra map[string]string, block []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&buf, "WARC-Type: %s\r\n", recordType)
+	recordID := extra["WARC-Record-ID"]
+	if recordID == "" {
+		recordID = newWARCRecordID()
+	}
+	fmt.Fprintf(&buf, "WARC-Record-ID: %s\r\n", recordID)
+	fmt.Fprintf(&buf, "WARC-Date: %s\r\n", time.Now().UTC().Format(time.RFC3339))
+	if targetURI != "" {
+		fmt.Fprintf(&buf, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	for name, value := range extra {
+		if name != "WARC-Record-ID" {
+			fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+		}
+	}
+	fmt.Fprintf(&buf, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(block))
+	buf.Write(block)
+	buf.WriteString("\r\n\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.compress {
+		_, err := w.w.Write(buf.Bytes())
+		return err
+	}
+
+	gz := gzip.NewWriter(w.w)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// transport wraps base so every exchange is archived
+func (w *WARCWriter) transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &warcTransport{writer: w, base: base}
+}
+
+// warcTransport is the http.RoundTripper installed by Config.WARC
+type warcTransport struct {
+	writer *WARCWriter
+	base   http.RoundTripper
+}
+
+// RoundTrip performs the request and writes it and its response to the archive
+func (t *warcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.writer.WriteExchange(req, reqBody, resp, body); err != nil {
+		getLogger().Warn("warc write failed",
+			"url", req.URL.String(),
+			"error", err.Error())
+	}
+
+	return resp, nil
+}
+
+// cloneRequestWithBody returns a copy of req whose body can be dumped
+func cloneRequestWithBody(req *http.Request, body []byte) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		clone.Body = nil
+	}
+	return clone
+}
+
+// statusLine returns "200 OK" style status text
+func statusLine(resp *http.Response) string {
+	if text := http.StatusText(resp.StatusCode); text != "" {
+		return fmt.Sprintf("%d %s", resp.StatusCode, text)
+	}
+	return strconv.Itoa(resp.StatusCode)
+}
+
+// newWARCRecordID returns a random <urn:uuid:...> record ID
+func newWARCRecordID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// WARCRecord is a record read from a WARC file
+type WARCRecord struct {
+	Type      string               // WARC-Type
+	TargetURI string               // WARC-Target-URI
+	Date      time.Time            // WARC-Date
+	Header    textproto.MIMEHeader // All WARC headers
+	Content   []byte               // Record block
+}
+
+// WARCReader reads WARC records from plain or gzip-compressed input
+type WARCReader struct {
+	r *bufio.Reader
+}
+
+// NewWARCReader creates a WARC reader, detecting gzip compression from the magic bytes
+func NewWARCReader(r io.Reader) (*WARCReader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, &ScrapeError{
+				Type:    ErrTypeParsing,
+				Message: "failed to open gzipped WARC",
+				Cause:   err,
+			}
+		}
+		br = bufio.NewReader(gz)
+	}
+	return &WARCReader{r: br}, nil
+}
+
+// Next returns the next record, or io.EOF when there are no more records
+func (r *WARCReader) Next() (*WARCRecord, error) {
+	// Skip blank lines between records
+	var version string
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && strings.TrimSpace(line) == "" {
+				return nil, io.EOF
+			}
+			return nil, warcParseError("truncated WARC record", err)
+		}
+		if version = strings.TrimSpace(line); version != "" {
+			break
+		}
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, warcParseError(fmt.Sprintf("invalid WARC version line: %q", version), nil)
+	}
+
+	header, err := textproto.NewReader(r.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, warcParseError("invalid WARC record header", err)
+	}
+
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, warcParseError("invalid WARC Content-Length", err)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r.r, content); err != nil {
+		return nil, warcParseError("truncated WARC record block", err)
+	}
+
+	record := &WARCRecord{
+		Type:      header.Get("WARC-Type"),
+		TargetURI: strings.Trim(header.Get("WARC-Target-URI"), "<>"),
+		Header:    header,
+		Content:   content,
+	}
+	record.Date, _ = time.Parse(time.RFC3339, header.Get("WARC-Date"))
+
+	return record, nil
+}
+
+func warcParseError(message string, cause error) error {
+	return &ScrapeError{
+		Type:    ErrTypeParsing,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+// responseBody parses a response record and returns its decoded body and Content-Type
+// if it is a successful response the parser handles: HTML by default, or the
+// JSON or XML media types for those parsers
+func (rec *WARCRecord) responseBody(parser string) ([]byte, string, bool, error) {
+	if rec.Type != WARCTypeResponse || !strings.HasPrefix(rec.Header.Get("Content-Type"), "application/http") {
+		return nil, "", false, nil
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(rec.Content)), nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", false, nil
+	}
+
+	var body io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, "", false, err
+		}
+		body = gz
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	switch parser {
+	case ParserJSON:
+		if !isJSONContentType(contentType) {
+			return nil, "", false, nil
+		}
+	case ParserXML:
+		if !isXMLContentType(contentType) {
+			return nil, "", false, nil
+		}
+	case ParserAuto:
+		if !isJSONContentType(contentType) && !isXMLContentType(contentType) && !isHTMLContentType(contentType) {
+			return nil, "", false, nil
+		}
+	default:
+		if !isHTMLContentType(contentType) {
+			return nil, "", false, nil
+		}
+	}
+
+	return data, contentType, true, nil
+}
+
+// ScrapeWARC runs config over every successful response record read from r that
+// Config.Parser handles: HTML pages by default, or JSON responses or XML feeds.
+// Each record's target URI is used as the base URL, so parseurl resolves links.
+// Records that fail to parse or extract are recorded in Errors and skipped.
+func ScrapeWARC[T any](ctx context.Context, r io.Reader, config *Config) (*PaginatedResults[T], error) {
+	if err := config.validateExtraction(); err != nil {
+		return nil, err
+	}
+
+	reader, err := NewWARCReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &PaginatedResults[T]{Pages: []PageResult[T]{}}
+	records := 0
+	ctx, dedup := withDedup(ctx, config)
+
+	for {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return results, err
+		}
+		records++
+
+		page, ok, err := scrapeWARCRecord[T](ctx, record, config)
+		if err == nil && !ok {
+			continue
+		}
+
+		pageNum := results.TotalPages + len(results.Errors) + 1
+		if err != nil {
+			getLogger().Warn("warc record failed",
+				"url", record.TargetURI,
+				"error", err.Error())
+			results.Errors = append(results.Errors, &PaginationError{
+				PageURL:    record.TargetURI,
+				PageNumber: pageNum,
+				Cause:      err,
+			})
+			continue
+		}
+
+		page.URL = record.TargetURI
+		page.PageNum = pageNum
+		page.ScrapedAt = time.Now()
+		results.Pages = append(results.Pages, *page)
+		results.TotalPages++
+		results.TotalItems += len(page.Items)
+	}
+	results.Duplicates = dedup.count()
+
+	getLogger().Info("warc scrape complete",
+		"records", records,
+		"pages", results.TotalPages,
+		"items", results.TotalItems,
+		"errors", len(results.Errors))
+
+	return results, nil
+}
+
+// scrapeWARCRecord extracts items and document fields from a response record.
+// Returns false for records that are skipped.
+func scrapeWARCRecord[T any](ctx context.Context, record *WARCRecord, config *Config) (*PageResult[T], bool, error) {
+	body, contentType, ok, err := record.responseBody(config.Parser)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	page, err := parseBody(string(body), contentType, record.TargetURI, config)
+	if err != nil {
+		return nil, false, err
+	}
+
+	items, document, err := scrapeParsed[T](WithURL(ctx, record.TargetURI), page, config)
+	if err != nil {
+		return nil, false, err
+	}
+	return &PageResult[T]{Items: items, Document: document}, true, nil
+}
+
+// ScrapeWARCFile opens a .warc or .warc.gz file and runs ScrapeWARC over it
+func ScrapeWARCFile[T any](ctx context.Context, path string, config *Config) (*PaginatedResults[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &ScrapeError{
+			Type:    ErrTypeConfig,
+			Message: fmt.Sprintf("failed to open WARC file: %s", path),
+			Cause:   err,
+		}
+	}
+	defer f.Close()
+
+	return ScrapeWARC[T](ctx, f, config)
+}
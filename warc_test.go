@@ -0,0 +1,234 @@
+package gtmlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type warcTestProduct struct {
+	Name string `json:"name"`
+	Link string `json:"link"`
+}
+
+// Test responses for archiving: an HTML listing, a JSON response and an RSS feed
+const testHTMLWARCProducts = `<html><body>
+  <div class="product"><h2>Alpha</h2><a href="/p/alpha">view</a></div>
+  <div class="product"><h2>Beta</h2><a href="/p/beta">view</a></div>
+</body></html>`
+
+const testJSONWARCData = `{"ok":true}`
+
+const testXMLWARCFeed = `<rss><channel><item><title>Gamma</title><link>/p/gamma</link></item></channel></rss>`
+
+// TestWARC_WriteAndScrape tests archiving fetches and re-extracting from the archive
+func TestWARC_WriteAndScrape(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/products":
+					w.Header().Set("Content-Type", "text/html; charset=utf-8")
+					fmt.Fprint(w, testHTMLWARCProducts)
+				case "/data.json":
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprint(w, testJSONWARCData)
+				case "/feed.xml":
+					w.Header().Set("Content-Type", "application/rss+xml")
+					fmt.Fprint(w, testXMLWARCFeed)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			var archive bytes.Buffer
+			config := &Config{
+				Container: "//div[@class='product']",
+				Fields: map[string]FieldConfig{
+					"name": {XPath: ".//h2/text()"},
+					"link": {XPath: ".//a/@href", Pipes: []string{"parseurl"}},
+				},
+				Timeout:         5 * time.Second,
+				AllowPrivateIPs: true, // Allow localhost for testing
+			}
+			config.WARC = NewWARCWriter(&archive, compress)
+
+			if _, err := ScrapeURL[warcTestProduct](context.Background(), server.URL+"/products", config); err != nil {
+				t.Fatalf("ScrapeURL failed: %v", err)
+			}
+			if _, err := fetchHTML(server.URL+"/data.json", config); err != nil {
+				t.Fatalf("fetch failed: %v", err)
+			}
+			server.Close()
+
+			// Offline re-extraction
+			results, err := ScrapeWARC[warcTestProduct](context.Background(), bytes.NewReader(archive.Bytes()), config)
+			if err != nil {
+				t.Fatalf("ScrapeWARC failed: %v", err)
+			}
+
+			if results.TotalPages != 1 || len(results.Errors) != 0 {
+				t.Fatalf("Expected 1 HTML page and no errors, got %d pages and %v", results.TotalPages, results.Errors)
+			}
+			items := results.Items()
+			if len(items) != 2 || items[0].Name != "Alpha" {
+				t.Fatalf("Unexpected items: %+v", items)
+			}
+			if want := server.URL + "/p/alpha"; items[0].Link != want {
+				t.Errorf("Expected link resolved against target URI %q, got %q", want, items[0].Link)
+			}
+		})
+	}
+}
+
+// TestScrapeWARC_Parser tests that archived feeds and JSON responses are scraped with Config.Parser
+func TestScrapeWARC_Parser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/products":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, testHTMLWARCProducts)
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, testJSONWARCData)
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, testXMLWARCFeed)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var archive bytes.Buffer
+	config := &Config{
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		WARC:            NewWARCWriter(&archive, false),
+	}
+	for _, path := range []string{"/products", "/data.json", "/feed.xml"} {
+		if _, err := fetchHTML(server.URL+path, config); err != nil {
+			t.Fatalf("fetch failed: %v", err)
+		}
+	}
+
+	feedConfig := &Config{
+		Parser:    ParserXML,
+		Container: "//item",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: "./title"},
+			"link": {XPath: "./link", Pipes: []string{"parseurl"}},
+		},
+	}
+	results, err := ScrapeWARC[warcTestProduct](context.Background(), bytes.NewReader(archive.Bytes()), feedConfig)
+	if err != nil {
+		t.Fatalf("ScrapeWARC failed: %v", err)
+	}
+	items := results.Items()
+	if results.TotalPages != 1 || len(items) != 1 || items[0].Name != "Gamma" || items[0].Link != server.URL+"/p/gamma" {
+		t.Errorf("Expected the feed item only, got %d pages and %+v", results.TotalPages, items)
+	}
+
+	jsonConfig := &Config{
+		Parser:    ParserJSON,
+		Container: "$",
+		Fields:    map[string]FieldConfig{"ok": {XPath: "$.ok"}},
+	}
+	jsonResults, err := ScrapeWARC[map[string]any](context.Background(), bytes.NewReader(archive.Bytes()), jsonConfig)
+	if err != nil {
+		t.Fatalf("ScrapeWARC failed: %v", err)
+	}
+	if jsonResults.TotalPages != 1 || jsonResults.Pages[0].URL != server.URL+"/data.json" {
+		t.Errorf("Expected the JSON response only, got %+v", jsonResults.Pages)
+	}
+}
+
+// TestWARCReader_Records tests record framing and headers
+func TestWARCReader_Records(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.warc.gz")
+	writer, err := CreateWARCFile(path)
+	if err != nil {
+		t.Fatalf("CreateWARCFile failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/products":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, testHTMLWARCProducts)
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, testJSONWARCData)
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, testXMLWARCFeed)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	config := &Config{
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+		WARC:            writer,
+	}
+	if _, err := fetchHTML(server.URL+"/products", config); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	config = &Config{
+		Container: "//div[@class='product']",
+		Fields: map[string]FieldConfig{
+			"name": {XPath: ".//h2/text()"},
+			"link": {XPath: ".//a/@href", Pipes: []string{"parseurl"}},
+		},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true, // Allow localhost for testing
+	}
+	results, err := ScrapeWARCFile[warcTestProduct](context.Background(), path, config)
+	if err != nil {
+		t.Fatalf("ScrapeWARCFile failed: %v", err)
+	}
+	if results.TotalItems != 2 {
+		t.Errorf("Expected 2 items, got %d", results.TotalItems)
+	}
+
+	// Parse a hand-written record
+	var raw bytes.Buffer
+	fmt.Fprint(&raw, "WARC/1.1\r\nWARC-Type: resource\r\nWARC-Target-URI: <urn:x>\r\nContent-Length: 2\r\n\r\nhi\r\n\r\n")
+	reader, _ := NewWARCReader(&raw)
+	record, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if record.Type != "resource" || record.TargetURI != "urn:x" || string(record.Content) != "hi" {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after last record, got %v", err)
+	}
+}
+
+// TestWARCReader_Truncated tests that truncated records are reported
+func TestWARCReader_Truncated(t *testing.T) {
+	reader, _ := NewWARCReader(strings.NewReader("WARC/1.1\r\nWARC-Type: response\r\nContent-Length: 100\r\n\r\nshort"))
+	if _, err := reader.Next(); err == nil {
+		t.Error("Expected error for truncated record")
+	}
+
+	reader, _ = NewWARCReader(strings.NewReader("not a warc\r\n"))
+	if _, err := reader.Next(); err == nil {
+		t.Error("Expected error for invalid version line")
+	}
+}
@@ -0,0 +1,223 @@
+package gtmlp
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html/charset"
+)
+
+// Namespace URIs used by the built-in feed configs
+const (
+	NamespaceAtom          = "http://www.w3.org/2005/Atom"
+	NamespaceDublinCore    = "http://purl.org/dc/elements/1.1/"
+	NamespaceContentModule = "http://purl.org/rss/1.0/modules/content/"
+)
+
+// RSSConfig returns a config for the items of an RSS 2.0 feed.
+// Fields: title, link, description, content, guid, published, author and category.
+func RSSConfig() *Config {
+	return &Config{
+		Parser: ParserXML,
+		Namespaces: map[string]string{
+			"dc":      NamespaceDublinCore,
+			"content": NamespaceContentModule,
+		},
+		Container: "//channel/item",
+		Fields: map[string]FieldConfig{
+			"title":       {XPath: "./title"},
+			"link":        {XPath: "./link", AltXPath: []string{"./guid[not(@isPermaLink='false')]"}},
+			"description": {XPath: "./description"},
+			"content":     {XPath: "./content:encoded", AltXPath: []string{"./description"}},
+			"guid":        {XPath: "./guid", AltXPath: []string{"./link"}},
+			"published":   {XPath: "./pubDate", AltXPath: []string{"./dc:date"}},
+			"author":      {XPath: "./author", AltXPath: []string{"./dc:creator"}},
+			"category":    {XPath: "./category"},
+		},
+		Timeout:   30 * time.Second,
+		UserAgent: "GTMLP/2.0",
+	}
+}
+
+// AtomConfig returns a config for the entries of an Atom feed, following
+// rel="next" links of paged feeds.
+// Fields: title, link, summary, content, id, published, updated and author.
+func AtomConfig() *Config {
+	return &Config{
+		Parser:     ParserXML,
+		Namespaces: map[string]string{"atom": NamespaceAtom},
+		Container:  "//atom:entry",
+		Fields: map[string]FieldConfig{
+			"title":     {XPath: "./atom:title"},
+			"link":      {XPath: "./atom:link[@rel='alternate']/@href", AltXPath: []string{"./atom:link[not(@rel)]/@href"}},
+			"summary":   {XPath: "./atom:summary", AltXPath: []string{"./atom:content"}},
+			"content":   {XPath: "./atom:content", AltXPath: []string{"./atom:summary"}},
+			"id":        {XPath: "./atom:id"},
+			"published": {XPath: "./atom:published", AltXPath: []string{"./atom:updated"}},
+			"updated":   {XPath: "./atom:updated"},
+			"author":    {XPath: "./atom:author/atom:name", AltXPath: []string{"../atom:author/atom:name"}},
+		},
+		Pagination: &PaginationConfig{
+			Type:         "next-link",
+			NextSelector: "/atom:feed/atom:link[@rel='next']/@href",
+		},
+		Timeout:   30 * time.Second,
+		UserAgent: "GTMLP/2.0",
+	}
+}
+
+// parseXML parses an XML document. HTML entities such as &nbsp;, common in feeds, are accepted.
+func parseXML(body string) (*xmlquery.Node, error) {
+	return xmlquery.ParseWithOptions(strings.NewReader(body), xmlquery.ParserOptions{
+		Decoder: &xmlquery.DecoderOptions{
+			Strict:        true,
+			Entity:        xml.HTMLEntity,
+			CharsetReader: charset.NewReaderLabel,
+		},
+	})
+}
+
+// compileXMLSelector compiles an XPath or css: selector for XML documents.
+// Name prefixes resolve through namespaces; without any, they match document prefixes literally.
+func compileXMLSelector(selector string, namespaces map[string]string) (*xpath.Expr, error) {
+	expr, err := selectorXPath(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(namespaces) == 0 {
+		return xpath.Compile(expr)
+	}
+	return xpath.CompileWithNS(expr, namespaces)
+}
+
+// selectXMLNodes returns the nodes matching selector relative to node
+func selectXMLNodes(node *xmlquery.Node, selector string, namespaces map[string]string) []*xmlquery.Node {
+	expr, err := compileXMLSelector(selector, namespaces)
+	if err != nil {
+		return nil
+	}
+	return xmlquery.QuerySelectorAll(node, expr)
+}
+
+// extractXMLField returns the first match of selector relative to node, formatted by
+// an output mode. OutputLines is the same as OutputText, and the HTML modes return XML.
+func extractXMLField(node *xmlquery.Node, selector string, namespaces map[string]string, output string) string {
+	expr, err := compileXMLSelector(selector, namespaces)
+	if err != nil {
+		return ""
+	}
+
+	nodeIterator, ok := expr.Evaluate(xmlquery.CreateXPathNavigator(node)).(*xpath.NodeIterator)
+	if !ok {
+		return ""
+	}
+	if output == OutputCount {
+		return countNodes(nodeIterator)
+	}
+	if !nodeIterator.MoveNext() {
+		return ""
+	}
+
+	navigator := nodeIterator.Current().(*xmlquery.NodeNavigator)
+	if navigator.NodeType() == xpath.AttributeNode {
+		return attributeOutput(strings.TrimSpace(navigator.Value()), output)
+	}
+
+	match := navigator.Current()
+	if match.Type != xmlquery.ElementNode && match.Type != xmlquery.DocumentNode {
+		// Text, CDATA and comment nodes
+		if output == OutputNormalized {
+			return normalizeSpace(match.Data)
+		}
+		return strings.TrimSpace(match.Data)
+	}
+
+	switch output {
+	case OutputHTML:
+		return strings.TrimSpace(match.OutputXML(false))
+	case OutputOuterHTML:
+		return match.OutputXML(true)
+	case OutputNormalized:
+		return normalizeSpace(match.InnerText())
+	case OutputOwnText:
+		var text strings.Builder
+		for child := match.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == xmlquery.TextNode || child.Type == xmlquery.CharDataNode {
+				text.WriteString(child.Data)
+				text.WriteByte(' ')
+			}
+		}
+		return normalizeSpace(text.String())
+	}
+	return strings.TrimSpace(match.InnerText())
+}
+
+// extractXMLPage extracts items and document fields from an XML document
+func extractXMLPage(ctx context.Context, doc *xmlquery.Node, config *Config) ([]map[string]any, map[string]any, error) {
+	var document map[string]any
+	if len(config.DocumentFields) > 0 {
+		var err error
+		if document, err = extractXMLFields(ctx, doc, config.DocumentFields, config.Namespaces); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	containers := findContainerMatches(config.Container, config.AltContainer, func(selector string) []*xmlquery.Node {
+		return selectXMLNodes(doc, selector, config.Namespaces)
+	})
+
+	items := make([]map[string]any, 0, len(containers))
+	for _, container := range containers {
+		fieldData, err := extractXMLFields(ctx, container, config.Fields, config.Namespaces)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, fieldData)
+	}
+
+	items, err := finishItems(ctx, items, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	copyDocumentFields(items, document, config)
+
+	return items, document, nil
+}
+
+// extractXMLFields extracts fields whose selectors are XPaths relative to node
+func extractXMLFields(ctx context.Context, node *xmlquery.Node, fields map[string]FieldConfig, namespaces map[string]string) (map[string]any, error) {
+	fieldData := make(map[string]any, len(fields))
+	for fieldName, fieldConfig := range fields {
+		if isNestedField(fieldConfig) {
+			value, err := extractNested(fieldConfig, func(selector string) []*xmlquery.Node {
+				return selectXMLNodes(node, selector, namespaces)
+			}, func(element *xmlquery.Node) (map[string]any, error) {
+				return extractXMLFields(ctx, element, fieldConfig.Fields, namespaces)
+			})
+			if err != nil {
+				return nil, err
+			}
+			fieldData[fieldName] = value
+			continue
+		}
+		fieldValue, err := extractFieldValue(ctx, fieldConfig, func(selector string) any {
+			return extractXMLField(node, selector, namespaces, fieldConfig.Output)
+		})
+		if err != nil {
+			return nil, err
+		}
+		fieldData[fieldName] = fieldValue
+	}
+	return fieldData, nil
+}
+
+// extractXMLNextURL extracts the next page URL of an XML page using NextSelector and AltSelectors
+func extractXMLNextURL(ctx context.Context, baseURL string, doc *xmlquery.Node, config *Config) (string, error) {
+	return findNextURL(ctx, baseURL, config, func(selector string) string {
+		return extractXMLField(doc, selector, config.Namespaces, "")
+	})
+}
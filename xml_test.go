@@ -0,0 +1,192 @@
+package gtmlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+  <title>Example News</title>
+  <item>
+    <title>First&nbsp;Post</title>
+    <link>https://example.com/first</link>
+    <description><![CDATA[<p>Short <b>intro</b></p>]]></description>
+    <content:encoded><![CDATA[<p>Full text</p>]]></content:encoded>
+    <guid isPermaLink="false">post-1</guid>
+    <pubDate>Wed, 01 May 2024 10:00:00 GMT</pubDate>
+    <dc:creator>Ann</dc:creator>
+    <category>Go</category>
+  </item>
+  <item>
+    <title>Second Post</title>
+    <guid>https://example.com/second</guid>
+    <description>Plain summary</description>
+    <author>bob@example.com (Bob)</author>
+  </item>
+</channel>
+</rss>`
+
+func atomFeed(entries, next string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Blog</title>
+  <author><name>Feed Author</name></author>
+  %s
+  %s
+</feed>`, next, entries)
+}
+
+// TestScrape_RSSConfig tests the built-in RSS 2.0 config
+func TestScrape_RSSConfig(t *testing.T) {
+	results, err := ScrapeUntyped(context.Background(), rssFeed, RSSConfig())
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	expected := []map[string]any{
+		{
+			"title":       "First Post",
+			"link":        "https://example.com/first",
+			"description": "<p>Short <b>intro</b></p>",
+			"content":     "<p>Full text</p>",
+			"guid":        "post-1",
+			"published":   "Wed, 01 May 2024 10:00:00 GMT",
+			"author":      "Ann",
+			"category":    "Go",
+		},
+		{
+			"title":       "Second Post",
+			"link":        "https://example.com/second",
+			"description": "Plain summary",
+			"content":     "Plain summary",
+			"guid":        "https://example.com/second",
+			"published":   "",
+			"author":      "bob@example.com (Bob)",
+			"category":    "",
+		},
+	}
+	for i, want := range expected {
+		for field, value := range want {
+			if got := results[i][field]; got != value {
+				t.Errorf("Item %d field %s: expected %q, got %q", i, field, value, got)
+			}
+		}
+	}
+}
+
+// TestScrapeURLWithPages_AtomConfig tests the built-in Atom config with paged feeds
+func TestScrapeURLWithPages_AtomConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, atomFeed(`<entry><title>Older</title><id>urn:2</id><link href="/older"/><updated>2024-04-01T00:00:00Z</updated></entry>`, ""))
+			return
+		}
+		fmt.Fprint(w, atomFeed(`<entry>
+    <title type="html">Newest</title>
+    <id>urn:1</id>
+    <link rel="alternate" href="https://example.com/newest"/>
+    <link rel="edit" href="https://example.com/edit/1"/>
+    <published>2024-05-01T00:00:00Z</published>
+    <updated>2024-05-02T00:00:00Z</updated>
+    <author><name>Ann</name></author>
+    <summary>Summary text</summary>
+  </entry>`, `<link rel="next" href="?page=2"/>`))
+	}))
+	defer server.Close()
+
+	config := AtomConfig()
+	config.AllowPrivateIPs = true
+	config.Timeout = 5 * time.Second
+
+	results, err := ScrapeURLWithPages[map[string]any](context.Background(), server.URL, config)
+	if err != nil {
+		t.Fatalf("ScrapeURLWithPages failed: %v", err)
+	}
+	if results.TotalPages != 2 || results.TotalItems != 2 {
+		t.Fatalf("Expected 2 pages and 2 items, got %d and %d", results.TotalPages, results.TotalItems)
+	}
+
+	newest := results.Pages[0].Items[0]
+	if newest["link"] != "https://example.com/newest" || newest["author"] != "Ann" || newest["content"] != "Summary text" {
+		t.Errorf("Unexpected first entry: %v", newest)
+	}
+	older := results.Pages[1].Items[0]
+	if older["link"] != "/older" || older["published"] != "2024-04-01T00:00:00Z" || older["author"] != "Feed Author" {
+		t.Errorf("Unexpected second entry: %v", older)
+	}
+}
+
+// TestScrapeURL_XMLContentType tests namespace mappings and detecting XML from the Content-Type
+func TestScrapeURL_XMLContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		fmt.Fprint(w, `<catalog xmlns:p="urn:products"><p:Product SKU="A1"><p:Name>Widget</p:Name></p:Product></catalog>`)
+	}))
+	defer server.Close()
+
+	// The config prefix differs from the document's; names keep their case
+	config := &Config{
+		Parser:          ParserAuto,
+		Namespaces:      map[string]string{"prod": "urn:products"},
+		Container:       "//prod:Product",
+		Fields:          map[string]FieldConfig{"name": {XPath: "./prod:Name"}, "sku": {XPath: "./@SKU"}},
+		Timeout:         5 * time.Second,
+		AllowPrivateIPs: true,
+	}
+
+	results, err := ScrapeURLUntyped(context.Background(), server.URL, config)
+	if err != nil {
+		t.Fatalf("ScrapeURLUntyped failed: %v", err)
+	}
+	if len(results) != 1 || results[0]["name"] != "Widget" || results[0]["sku"] != "A1" {
+		t.Errorf("Unexpected results: %v", results)
+	}
+}
+
+// TestValidate_XMLParser tests validation of XML configs
+func TestValidate_XMLParser(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		valid  bool
+	}{
+		{"rss", func(c *Config) {}, true},
+		{"unmapped prefix", func(c *Config) {
+			c.Fields["media"] = FieldConfig{XPath: "./media:thumbnail/@url"}
+		}, false},
+		{"numbered pagination", func(c *Config) {
+			c.Pagination = &PaginationConfig{Type: "numbered", PageSelector: "//a/@href"}
+		}, false},
+		{"detail page xml parser", func(c *Config) {
+			c.Fields["link"] = FieldConfig{XPath: "./link", Follow: &Config{Parser: ParserXML, Fields: map[string]FieldConfig{"x": {XPath: "//x"}}}}
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := RSSConfig()
+			tt.modify(config)
+			err := config.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid config, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+
+	if err := AtomConfig().Validate(); err != nil {
+		t.Errorf("Expected valid Atom config, got %v", err)
+	}
+}